@@ -3,18 +3,23 @@ package main
 import (
 	"SpaceBookProject/internal/worker"
 	"context"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata"
 
 	"SpaceBookProject/internal/auth"
 	"SpaceBookProject/internal/config"
 	"SpaceBookProject/internal/db"
 	"SpaceBookProject/internal/domain"
 	"SpaceBookProject/internal/handlers"
+	"SpaceBookProject/internal/mailer"
+	"SpaceBookProject/internal/notify"
+	"SpaceBookProject/internal/payments"
 	"SpaceBookProject/internal/repository"
 	"SpaceBookProject/internal/services"
 	"SpaceBookProject/middleware"
@@ -34,59 +39,221 @@ func main() {
 	}
 	defer database.Close()
 
-	jwtManager := auth.NewJWTManager(cfg.JWT.SecretKey)
+	jwtManager := auth.NewJWTManager(cfg.JWT.SecretKey, cfg.JWT.AccessTokenTTL, cfg.JWT.RefreshTokenTTL)
+	revocations := auth.NewRevocationStore(repository.NewRevocationRepository(database), cfg.JWT.RevocationCacheTTL)
 
 	userRepo := repository.NewUserRepository(database)
+	sessionRepo := repository.NewSessionRepository(database)
 	bookingRepo := repository.NewBookingRepository(database)
+	bookingChangeRepo := repository.NewBookingChangeRepository(database)
+	bookingSeriesRepo := repository.NewBookingSeriesRepository(database)
 	spaceRepo := repository.NewSpaceRepository(database)
-	eventsChan := make(chan domain.BookingEvent, 100)
+	blackoutRepo := repository.NewBlackoutRepository(database)
+	outboxRepo := repository.NewOutboxRepository(database)
+	webhookRepo := repository.NewWebhookRepository(database)
+	userTokenRepo := repository.NewUserTokenRepository(database)
+	moderationRepo := repository.NewModerationRepository(database)
+	orgRepo := repository.NewOrganizationRepository(database)
+	waitlistRepo := repository.NewWaitlistRepository(database)
+	pricingRepo := repository.NewPricingRepository(database)
+	ledgerRepo := repository.NewLedgerRepository(database, cfg.Ledger.CommissionPercent)
+	invoiceRepo := repository.NewInvoiceRepository(database, domain.TaxRate{
+		Name:    cfg.Invoices.TaxName,
+		Percent: cfg.Invoices.TaxPercent,
+	})
+	paymentRepo := repository.NewPaymentRepository(database, ledgerRepo, invoiceRepo)
 
-	authService := services.NewAuthService(userRepo, jwtManager)
-	bookingService := services.NewBookingService(bookingRepo, spaceRepo, eventsChan)
-	spaceService := services.NewSpaceService(spaceRepo)
+	mail, err := newMailer(&cfg.Mail)
+	if err != nil {
+		log.Fatalf("failed to init mailer: %v", err)
+	}
+	renderer := notify.NewRenderer(cfg.Mail.TemplatesDir)
+	accountNotifier := notify.NewAccountNotifier(mail, renderer, cfg.Account.BaseURL)
+
+	authService := services.NewAuthService(
+		userRepo, sessionRepo, userTokenRepo, jwtManager, revocations,
+		accountNotifier,
+		services.AccountTokenTTL{
+			PasswordReset:     cfg.Account.PasswordResetTTL,
+			EmailVerification: cfg.Account.EmailVerificationTTL,
+		},
+	)
+	spacePolicy := services.NewSpacePolicy(orgRepo)
+	bookingService := services.NewBookingService(bookingRepo, bookingChangeRepo, bookingSeriesRepo, spaceRepo, blackoutRepo, pricingRepo, userRepo, orgRepo, spacePolicy, cfg.Account.RequireEmailVerification)
+	spaceService := services.NewSpaceService(spaceRepo, orgRepo, spacePolicy)
+	availabilityService := services.NewAvailabilityService(bookingRepo, spaceRepo, blackoutRepo, spacePolicy)
+	organizationService := services.NewOrganizationService(orgRepo, userRepo, accountNotifier, cfg.Account.OrgInvitationTTL)
+	webhookService := services.NewWebhookService(webhookRepo)
+	adminService := services.NewAdminService(moderationRepo, userRepo, spaceRepo, revocations)
+	pricingService := services.NewPricingService(pricingRepo, spaceRepo, spacePolicy)
+	paymentProvider, err := newPaymentProvider(&cfg.Payments)
+	if err != nil {
+		log.Fatalf("failed to init payment provider: %v", err)
+	}
+	paymentService := services.NewPaymentService(paymentRepo, bookingRepo, bookingService, paymentProvider)
+	ledgerService := services.NewLedgerService(ledgerRepo)
+	invoiceService := services.NewInvoiceService(invoiceRepo, bookingRepo, bookingService)
+	waitlistNotifier := notify.NewWaitlistNotifier(mail, renderer, cfg.Account.BaseURL)
+	waitlistService := services.NewWaitlistService(waitlistRepo, bookingRepo, spaceRepo, userRepo, bookingService, spacePolicy, waitlistNotifier)
 
 	authHandler := handlers.NewAuthHandler(authService)
 	bookingHandler := handlers.NewBookingHandler(bookingService)
 	spaceHandler := handlers.NewSpaceHandler(spaceService)
+	availabilityHandler := handlers.NewAvailabilityHandler(availabilityService)
+	webhookHandler := handlers.NewWebhookHandler(webhookService)
+	adminHandler := handlers.NewAdminHandler(adminService)
+	organizationHandler := handlers.NewOrganizationHandler(organizationService)
+	waitlistHandler := handlers.NewWaitlistHandler(waitlistService)
+	pricingHandler := handlers.NewPricingHandler(pricingService)
+	paymentHandler := handlers.NewPaymentHandler(paymentService)
+	ledgerHandler := handlers.NewLedgerHandler(ledgerService)
+	invoiceHandler := handlers.NewInvoiceHandler(invoiceService)
 
 	gin.SetMode(cfg.Server.Mode)
 	r := gin.New()
 	r.Use(gin.Logger(), gin.Recovery(), middleware.CORSMiddleware())
 
 	api := r.Group(cfg.API.Prefix + "/" + cfg.API.Version)
+	requireAuth := middleware.AuthMiddleware(jwtManager, revocations)
 
 	authGroup := api.Group("/auth")
 	{
 		authGroup.POST("/register", authHandler.Register)
 		authGroup.POST("/login", authHandler.Login)
 		authGroup.POST("/refresh", authHandler.RefreshToken)
-		authGroup.GET("/me", middleware.AuthMiddleware(jwtManager), authHandler.GetMe)
+		authGroup.GET("/me", requireAuth, authHandler.GetMe)
+		authGroup.POST("/logout", requireAuth, authHandler.Logout)
+		authGroup.POST("/logout-all", requireAuth, authHandler.LogoutAll)
+		authGroup.POST("/password/change", requireAuth, authHandler.ChangePassword)
+		authGroup.GET("/sessions", requireAuth, authHandler.ListSessions)
+		authGroup.DELETE("/sessions/:id", requireAuth, authHandler.RevokeSession)
+		authGroup.POST("/password/forgot", authHandler.ForgotPassword)
+		authGroup.POST("/password/reset", authHandler.ResetPassword)
+		authGroup.POST("/email/verify", authHandler.VerifyEmail)
+		authGroup.POST("/email/verification", requireAuth, authHandler.ResendEmailVerification)
+		authGroup.POST("/roles/owner", requireAuth, authHandler.BecomeOwner)
 	}
 
 	spacesGroup := api.Group("/spaces")
 	{
 		spacesGroup.GET("", spaceHandler.ListSpaces)
+		spacesGroup.GET("/:id", middleware.OptionalAuthMiddleware(jwtManager, revocations), spaceHandler.GetSpace)
+		spacesGroup.GET("/:id/availability", availabilityHandler.GetAvailability)
+		spacesGroup.GET("/:id/pricing", pricingHandler.GetPricing)
+		spacesGroup.POST("/:id/quote", pricingHandler.Quote)
 	}
-	ownerSpaces := api.Group("/spaces", middleware.AuthMiddleware(jwtManager), middleware.OwnerOnlyMiddleware())
+	api.POST("/spaces", requireAuth, middleware.OwnerOnlyMiddleware(), spaceHandler.CreateSpace)
+	// права на существующие помещения проверяет SpacePolicy: сотрудникам
+	// организации роль owner не нужна
+	ownerSpaces := api.Group("/spaces", requireAuth)
 	{
-		ownerSpaces.POST("", spaceHandler.CreateSpace)
+		ownerSpaces.PATCH("/:id", spaceHandler.UpdateSpace)
+		ownerSpaces.PUT("/:id/organization", spaceHandler.SetOrganization)
+		ownerSpaces.PUT("/:id/instant-booking", spaceHandler.SetInstantBooking)
+		ownerSpaces.PUT("/:id/pricing", pricingHandler.SetPricing)
+		ownerSpaces.PUT("/:id/cancellation-policy", spaceHandler.SetCancellationPolicy)
+		ownerSpaces.DELETE("/:id", spaceHandler.DeleteSpace)
+		ownerSpaces.POST("/:id/activate", spaceHandler.ActivateSpace)
+		ownerSpaces.POST("/:id/deactivate", spaceHandler.DeactivateSpace)
+		ownerSpaces.GET("/:id/blackouts", availabilityHandler.ListBlackouts)
+		ownerSpaces.POST("/:id/blackouts", availabilityHandler.CreateBlackout)
+		ownerSpaces.DELETE("/:id/blackouts/:blackoutId", availabilityHandler.DeleteBlackout)
+		ownerSpaces.GET("/:id/waitlist", waitlistHandler.SpaceWaitlist)
+		ownerSpaces.POST("/:id/waitlist", middleware.RoleMiddleware(domain.RoleTenant), waitlistHandler.Join)
 	}
 
-	bookingsGroup := api.Group("/bookings", middleware.AuthMiddleware(jwtManager))
+	bookingsGroup := api.Group("/bookings", requireAuth)
 	{
 		bookingsGroup.POST("", middleware.RoleMiddleware(domain.RoleTenant), bookingHandler.CreateBooking)
 		bookingsGroup.GET("/my", middleware.RoleMiddleware(domain.RoleTenant), bookingHandler.MyBookings)
+		bookingsGroup.POST("/series", middleware.RoleMiddleware(domain.RoleTenant), bookingHandler.CreateSeries)
+		bookingsGroup.GET("/series/:id", bookingHandler.GetSeries)
+		bookingsGroup.GET("/:id/cancellation-preview", middleware.RoleMiddleware(domain.RoleTenant), bookingHandler.CancellationPreview)
 		bookingsGroup.PATCH("/:id/cancel", middleware.RoleMiddleware(domain.RoleTenant), bookingHandler.CancelBooking)
+		bookingsGroup.GET("/:id/cancellation", bookingHandler.GetCancellation)
+		bookingsGroup.PATCH("/:id", middleware.RoleMiddleware(domain.RoleTenant), bookingHandler.ModifyBooking)
+		bookingsGroup.GET("/:id/history", bookingHandler.BookingHistory)
+		bookingsGroup.GET("/:id/changes", bookingHandler.BookingChanges)
+		bookingsGroup.POST("/:id/payments", middleware.RoleMiddleware(domain.RoleTenant), paymentHandler.Pay)
+		bookingsGroup.GET("/:id/payments", paymentHandler.ListPayments)
+		bookingsGroup.GET("/:id/invoice", invoiceHandler.Invoice)
+		bookingsGroup.GET("/:id/credit-notes", invoiceHandler.CreditNotes)
+		bookingsGroup.GET("/:id/credit-notes/:noteId", invoiceHandler.CreditNote)
 	}
 
-	ownerBookings := api.Group("/owner/bookings",
-		middleware.AuthMiddleware(jwtManager),
-		middleware.OwnerOnlyMiddleware(),
-	)
+	waitlistGroup := api.Group("/waitlist", requireAuth)
+	{
+		waitlistGroup.GET("/my", waitlistHandler.MyWaitlist)
+		waitlistGroup.DELETE("/:id", waitlistHandler.Leave)
+	}
+
+	// подлинность вебхука шлюза проверяется подписью, а не токеном
+	api.POST("/payments/webhook", paymentHandler.Webhook)
+
+	api.GET("/owner/spaces", requireAuth, spaceHandler.OwnerSpaces)
+	api.GET("/owner/waitlist", requireAuth, waitlistHandler.Demand)
+	api.GET("/owner/payouts", requireAuth, ledgerHandler.OwnerPayouts)
+	api.GET("/owner/payouts/:id", requireAuth, ledgerHandler.PayoutStatement)
+
+	ownerBookings := api.Group("/owner/bookings", requireAuth)
 	{
 		ownerBookings.GET("", bookingHandler.OwnerBookings)
 		ownerBookings.PATCH("/:id/approve", bookingHandler.ApproveBooking)
+		ownerBookings.PATCH("/series/:id/approve", bookingHandler.ApproveSeries)
+		ownerBookings.PATCH("/series/:id/reject", bookingHandler.RejectSeries)
 		ownerBookings.PATCH("/:id/reject", bookingHandler.RejectBooking)
+		ownerBookings.PATCH("/:id/check-in", bookingHandler.CheckInBooking)
+		ownerBookings.PATCH("/:id/complete", bookingHandler.CompleteBooking)
+		ownerBookings.PATCH("/:id/no-show", bookingHandler.NoShowBooking)
+		ownerBookings.PATCH("/:id/cancel", bookingHandler.OwnerCancelBooking)
+		ownerBookings.PATCH("/:id/changes/:changeId/approve", bookingHandler.ApproveBookingChange)
+		ownerBookings.PATCH("/:id/changes/:changeId/reject", bookingHandler.RejectBookingChange)
+	}
+
+	webhooksGroup := api.Group("/webhooks",
+		requireAuth,
+		middleware.RoleMiddleware(domain.RoleOwner, domain.RoleAdmin),
+	)
+	{
+		webhooksGroup.GET("", webhookHandler.ListEndpoints)
+		webhooksGroup.POST("", webhookHandler.CreateEndpoint)
+		webhooksGroup.DELETE("/:id", webhookHandler.DeleteEndpoint)
+		webhooksGroup.GET("/:id/deliveries", webhookHandler.ListDeliveries)
+		webhooksGroup.GET("/:id/deliveries/:deliveryId/attempts", webhookHandler.ListAttempts)
+		webhooksGroup.POST("/:id/deliveries/:deliveryId/redeliver", webhookHandler.Redeliver)
+	}
+
+	orgsGroup := api.Group("/organizations", requireAuth)
+	{
+		orgsGroup.POST("", organizationHandler.CreateOrganization)
+		orgsGroup.GET("", organizationHandler.ListMyOrganizations)
+		orgsGroup.PATCH("/:id", organizationHandler.UpdateOrganization)
+		orgsGroup.POST("/invitations/accept", organizationHandler.AcceptInvitation)
+		orgsGroup.GET("/:id/members", organizationHandler.ListMembers)
+		orgsGroup.PATCH("/:id/members/:userId", organizationHandler.UpdateMember)
+		orgsGroup.DELETE("/:id/members/:userId", organizationHandler.RemoveMember)
+		orgsGroup.GET("/:id/invitations", organizationHandler.ListInvitations)
+		orgsGroup.POST("/:id/invitations", organizationHandler.Invite)
+		orgsGroup.DELETE("/:id/invitations/:invitationId", organizationHandler.RevokeInvitation)
+		orgsGroup.GET("/:id/bookings", bookingHandler.CompanyBookings)
+		orgsGroup.PATCH("/:id/bookings/:bookingId/approve", bookingHandler.CompanyApproveBooking)
+		orgsGroup.PATCH("/:id/bookings/:bookingId/reject", bookingHandler.CompanyRejectBooking)
+	}
+
+	adminGroup := api.Group("/admin",
+		requireAuth,
+		middleware.RoleMiddleware(domain.RoleAdmin),
+	)
+	{
+		adminGroup.GET("/users", adminHandler.ListUsers)
+		adminGroup.POST("/users/:id/suspend", adminHandler.SuspendUser)
+		adminGroup.POST("/users/:id/reinstate", adminHandler.ReinstateUser)
+		adminGroup.POST("/bookings/:id/cancel", adminHandler.CancelBooking)
+		adminGroup.POST("/spaces/:id/unpublish", adminHandler.UnpublishSpace)
+		adminGroup.POST("/spaces/:id/republish", adminHandler.RepublishSpace)
+		adminGroup.GET("/audit", adminHandler.ListAudit)
+		adminGroup.GET("/ledger/reconciliation", ledgerHandler.Reconcile)
+		adminGroup.POST("/payouts/run", ledgerHandler.RunPayouts)
 	}
 
 	srv := &http.Server{
@@ -98,9 +265,52 @@ func main() {
 		os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
-	bookingWorker := worker.NewBookingEventWorker(eventsChan)
+	bookingNotifier := notify.NewBookingNotifier(
+		mail, renderer,
+		bookingRepo, spaceRepo, userRepo,
+	)
+
+	// письма идут последними: при ошибке событие повторяется целиком,
+	// а постановка вебхуков в очередь идемпотентна
+	dispatcher := worker.MultiDispatcher(
+		worker.LogDispatcher,
+		worker.NewWebhookDispatcher(webhookRepo),
+		worker.DispatcherFunc(paymentService.HandleEvent),
+		worker.DispatcherFunc(waitlistService.HandleEvent),
+		worker.DispatcherFunc(bookingNotifier.Notify),
+	)
+	bookingWorker := worker.NewBookingEventWorker(outboxRepo, dispatcher, worker.BookingEventWorkerConfig{
+		PollInterval: cfg.Outbox.PollInterval,
+		BatchSize:    cfg.Outbox.BatchSize,
+		Lease:        cfg.Outbox.Lease,
+		MaxAttempts:  cfg.Outbox.MaxAttempts,
+		BaseBackoff:  cfg.Outbox.BaseBackoff,
+		MaxBackoff:   cfg.Outbox.MaxBackoff,
+	})
 	go bookingWorker.Run(ctx)
 
+	expiryScheduler := worker.NewBookingExpiryScheduler(bookingRepo, worker.BookingExpirySchedulerConfig{
+		Interval:  cfg.Expiry.Interval,
+		BatchSize: cfg.Expiry.BatchSize,
+	})
+	go expiryScheduler.Run(ctx)
+
+	webhookWorker := worker.NewWebhookWorker(webhookRepo, worker.WebhookWorkerConfig{
+		PollInterval: cfg.Webhooks.PollInterval,
+		BatchSize:    cfg.Webhooks.BatchSize,
+		Timeout:      cfg.Webhooks.Timeout,
+		MaxAttempts:  cfg.Webhooks.MaxAttempts,
+		BaseBackoff:  cfg.Webhooks.BaseBackoff,
+		MaxBackoff:   cfg.Webhooks.MaxBackoff,
+	})
+	go webhookWorker.Run(ctx)
+
+	payoutScheduler := worker.NewPayoutScheduler(ledgerRepo, worker.PayoutSchedulerConfig{
+		Period:        cfg.Ledger.PayoutPeriod,
+		CheckInterval: cfg.Ledger.PayoutCheckInterval,
+	})
+	go payoutScheduler.Run(ctx)
+
 	go func() {
 		log.Printf("server listening on :%s", cfg.Server.Port)
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -120,3 +330,25 @@ func main() {
 
 	log.Println("server exited gracefully")
 }
+
+func newPaymentProvider(cfg *config.PaymentsConfig) (payments.Provider, error) {
+	switch cfg.Provider {
+	case "fake", "":
+		return payments.NewFakeProvider(cfg.WebhookSecret), nil
+	default:
+		return nil, fmt.Errorf("unknown payment provider %q", cfg.Provider)
+	}
+}
+
+func newMailer(cfg *config.MailConfig) (mailer.Mailer, error) {
+	switch cfg.Driver {
+	case "smtp":
+		return mailer.NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.From), nil
+	case "file":
+		return mailer.NewFileMailer(cfg.FileDir, cfg.From)
+	case "console", "":
+		return mailer.NewConsoleMailer(os.Stdout, cfg.From), nil
+	default:
+		return nil, fmt.Errorf("unknown mail driver %q", cfg.Driver)
+	}
+}
@@ -0,0 +1,44 @@
+// Команда promote-admin выдаёт роль администратора существующему
+// пользователю. Через API роль admin получить нельзя.
+//
+//	go run ./cmd/promote-admin user@example.com
+package main
+
+import (
+	"log"
+	"os"
+
+	"SpaceBookProject/internal/config"
+	"SpaceBookProject/internal/db"
+	"SpaceBookProject/internal/domain"
+	"SpaceBookProject/internal/repository"
+)
+
+func main() {
+	if len(os.Args) != 2 {
+		log.Fatalf("usage: %s <email>", os.Args[0])
+	}
+	email := os.Args[1]
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
+
+	database, err := db.InitDB(&cfg.Database)
+	if err != nil {
+		log.Fatalf("failed to init db: %v", err)
+	}
+	defer database.Close()
+
+	users := repository.NewUserRepository(database)
+	user, err := users.GetByEmail(email)
+	if err != nil {
+		log.Fatalf("failed to find user %s: %v", email, err)
+	}
+	if _, err := users.AddRole(user.ID, domain.RoleAdmin); err != nil {
+		log.Fatalf("failed to promote user %s: %v", email, err)
+	}
+
+	log.Printf("user %d (%s) is now an admin; the role applies after the next login or token refresh", user.ID, email)
+}
@@ -2,7 +2,6 @@ package auth
 
 import (
 	"errors"
-	"fmt"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -14,9 +13,16 @@ var (
 )
 
 type TokenClaims struct {
-	UserID int    `json:"user_id"`
-	Email  string `json:"email"`
-	Role   string `json:"role"` // "owner" или "tenant"
+	UserID int      `json:"user_id"`
+	Email  string   `json:"email"`
+	Roles  []string `json:"roles"`
+	// LegacyRole есть только в токенах, выданных до перехода на набор
+	// ролей; ValidateToken переносит его в Roles.
+	LegacyRole string `json:"role,omitempty"`
+	SessionID  int64  `json:"sid"`
+	// TokenVersion сверяется с users.token_version: её увеличение
+	// разом отзывает все выданные пользователю токены.
+	TokenVersion int `json:"ver"`
 	jwt.RegisteredClaims
 }
 
@@ -26,20 +32,33 @@ type JWTManager struct {
 	refreshTokenTTL time.Duration
 }
 
-func NewJWTManager(secretKey string) *JWTManager {
+func NewJWTManager(secretKey string, accessTokenTTL, refreshTokenTTL time.Duration) *JWTManager {
 	return &JWTManager{
 		secretKey:       secretKey,
-		accessTokenTTL:  15 * time.Minute,
-		refreshTokenTTL: 7 * 24 * time.Hour,
+		accessTokenTTL:  accessTokenTTL,
+		refreshTokenTTL: refreshTokenTTL,
 	}
 }
 
-func (j *JWTManager) GenerateAccessToken(userID int, email, role string) (string, error) {
+// RefreshTokenTTL — время жизни сессии, продлеваемой обменом refresh-токена.
+func (j *JWTManager) RefreshTokenTTL() time.Duration {
+	return j.refreshTokenTTL
+}
+
+func (j *JWTManager) GenerateAccessToken(userID int, email string, roles []string, sessionID int64, version int) (string, error) {
+	jti, err := newTokenID()
+	if err != nil {
+		return "", err
+	}
+
 	claims := TokenClaims{
-		UserID: userID,
-		Email:  email,
-		Role:   role,
+		UserID:       userID,
+		Email:        email,
+		Roles:        roles,
+		SessionID:    sessionID,
+		TokenVersion: version,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(j.accessTokenTTL)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
@@ -50,17 +69,6 @@ func (j *JWTManager) GenerateAccessToken(userID int, email, role string) (string
 	return token.SignedString([]byte(j.secretKey))
 }
 
-func (j *JWTManager) GenerateRefreshToken(userID int) (string, error) {
-	claims := jwt.RegisteredClaims{
-		Subject:   fmt.Sprintf("%d", userID), // Правильное преобразование int в string
-		ExpiresAt: jwt.NewNumericDate(time.Now().Add(j.refreshTokenTTL)),
-		IssuedAt:  jwt.NewNumericDate(time.Now()),
-	}
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(j.secretKey))
-}
-
 func (j *JWTManager) ValidateToken(tokenString string) (*TokenClaims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &TokenClaims{}, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
@@ -82,5 +90,9 @@ func (j *JWTManager) ValidateToken(tokenString string) (*TokenClaims, error) {
 		return nil, ErrExpiredToken
 	}
 
+	if len(claims.Roles) == 0 && claims.LegacyRole != "" {
+		claims.Roles = []string{claims.LegacyRole}
+	}
+
 	return claims, nil
 }
@@ -0,0 +1,115 @@
+package auth
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var ErrRevokedToken = errors.New("token has been revoked")
+
+// RevocationBackend — постоянное хранилище отзывов (Postgres).
+type RevocationBackend interface {
+	// IsAccessTokenRevoked сообщает, отозван ли токен: по jti, по сессии sid
+	// или сменой версии токенов пользователя.
+	IsAccessTokenRevoked(jti string, sessionID int64, userID, version int) (bool, error)
+	RevokeAccessToken(jti string, expiresAt time.Time) error
+}
+
+type revocationEntry struct {
+	userID    int
+	sessionID int64
+	revoked   bool
+	expires   time.Time
+}
+
+// RevocationStore проверяет access-токены на отзыв, кэшируя ответы
+// хранилища на ttl. Отзывы, сделанные через этот же процесс, сбрасывают
+// кэш сразу; другие реплики увидят их не позже чем через ttl.
+type RevocationStore struct {
+	backend RevocationBackend
+	ttl     time.Duration
+
+	mu    sync.Mutex
+	cache map[string]revocationEntry
+}
+
+func NewRevocationStore(backend RevocationBackend, ttl time.Duration) *RevocationStore {
+	return &RevocationStore{
+		backend: backend,
+		ttl:     ttl,
+		cache:   make(map[string]revocationEntry),
+	}
+}
+
+func (s *RevocationStore) Check(claims *TokenClaims) error {
+	now := time.Now()
+
+	s.mu.Lock()
+	entry, ok := s.cache[claims.ID]
+	s.mu.Unlock()
+
+	if !ok || now.After(entry.expires) {
+		revoked, err := s.backend.IsAccessTokenRevoked(claims.ID, claims.SessionID, claims.UserID, claims.TokenVersion)
+		if err != nil {
+			return err
+		}
+		entry = revocationEntry{
+			userID:    claims.UserID,
+			sessionID: claims.SessionID,
+			revoked:   revoked,
+			expires:   now.Add(s.ttl),
+		}
+
+		s.mu.Lock()
+		s.cache[claims.ID] = entry
+		s.evictExpired(now)
+		s.mu.Unlock()
+	}
+
+	if entry.revoked {
+		return ErrRevokedToken
+	}
+	return nil
+}
+
+// RevokeToken отзывает конкретный access-токен до истечения его срока.
+func (s *RevocationStore) RevokeToken(claims *TokenClaims) error {
+	if err := s.backend.RevokeAccessToken(claims.ID, claims.ExpiresAt.Time); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	delete(s.cache, claims.ID)
+	s.mu.Unlock()
+	return nil
+}
+
+// ForgetSession сбрасывает кэш для токенов отозванной сессии.
+func (s *RevocationStore) ForgetSession(sessionID int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for jti, e := range s.cache {
+		if e.sessionID == sessionID {
+			delete(s.cache, jti)
+		}
+	}
+}
+
+// ForgetUser сбрасывает кэш для всех токенов пользователя.
+func (s *RevocationStore) ForgetUser(userID int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for jti, e := range s.cache {
+		if e.userID == userID {
+			delete(s.cache, jti)
+		}
+	}
+}
+
+func (s *RevocationStore) evictExpired(now time.Time) {
+	for jti, e := range s.cache {
+		if now.After(e.expires) {
+			delete(s.cache, jti)
+		}
+	}
+}
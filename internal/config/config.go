@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -13,6 +14,14 @@ type Config struct {
 	Server   ServerConfig
 	JWT      JWTConfig
 	API      APIConfig
+	Outbox   OutboxConfig
+	Expiry   ExpiryConfig
+	Webhooks WebhookConfig
+	Mail     MailConfig
+	Account  AccountConfig
+	Payments PaymentsConfig
+	Ledger   LedgerConfig
+	Invoices InvoiceConfig
 }
 
 type DatabaseConfig struct {
@@ -33,6 +42,9 @@ type JWTConfig struct {
 	SecretKey       string
 	AccessTokenTTL  time.Duration
 	RefreshTokenTTL time.Duration
+	// RevocationCacheTTL — сколько реплика доверяет закэшированной проверке
+	// отзыва токена; отзывы через другие реплики видны не позже этого срока.
+	RevocationCacheTTL time.Duration
 }
 
 type APIConfig struct {
@@ -40,6 +52,72 @@ type APIConfig struct {
 	Prefix  string
 }
 
+type OutboxConfig struct {
+	PollInterval time.Duration
+	BatchSize    int
+	Lease        time.Duration
+	MaxAttempts  int
+	BaseBackoff  time.Duration
+	MaxBackoff   time.Duration
+}
+
+// ExpiryConfig — расписание истечения заявок без ответа владельца.
+type ExpiryConfig struct {
+	Interval  time.Duration
+	BatchSize int
+}
+
+type WebhookConfig struct {
+	PollInterval time.Duration
+	BatchSize    int
+	Timeout      time.Duration
+	MaxAttempts  int
+	BaseBackoff  time.Duration
+	MaxBackoff   time.Duration
+}
+
+type MailConfig struct {
+	Driver       string // smtp, console или file
+	From         string
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	FileDir      string
+	TemplatesDir string
+}
+
+type AccountConfig struct {
+	// BaseURL — адрес фронтенда, на который ведут ссылки из писем.
+	BaseURL              string
+	PasswordResetTTL     time.Duration
+	EmailVerificationTTL time.Duration
+	OrgInvitationTTL     time.Duration
+	// RequireEmailVerification запрещает бронировать до подтверждения email.
+	RequireEmailVerification bool
+}
+
+type PaymentsConfig struct {
+	Provider string // пока только fake
+	// WebhookSecret — ключ, которым шлюз подписывает вебхуки.
+	WebhookSecret string
+}
+
+// LedgerConfig — комиссия платформы и расписание выплат владельцам.
+type LedgerConfig struct {
+	CommissionPercent int
+	// PayoutPeriod — как часто создаётся пакет выплат.
+	PayoutPeriod time.Duration
+	// PayoutCheckInterval — как часто планировщик проверяет, не пора ли.
+	PayoutCheckInterval time.Duration
+}
+
+// InvoiceConfig — налог, включённый в цены и выделяемый в счетах.
+type InvoiceConfig struct {
+	TaxName    string
+	TaxPercent int
+}
+
 func LoadConfig() (*Config, error) {
 	if err := godotenv.Load(); err != nil {
 		// Не критично если файла нет - используем переменные окружения
@@ -60,14 +138,87 @@ func LoadConfig() (*Config, error) {
 			Mode: getEnv("SERVER_MODE", "debug"),
 		},
 		JWT: JWTConfig{
-			SecretKey:       getEnv("JWT_SECRET_KEY", "your-secret-key"),
-			AccessTokenTTL:  parseDuration(getEnv("JWT_ACCESS_TOKEN_TTL", "15m"), 15*time.Minute),
-			RefreshTokenTTL: parseDuration(getEnv("JWT_REFRESH_TOKEN_TTL", "168h"), 168*time.Hour),
+			SecretKey:          getEnv("JWT_SECRET_KEY", "your-secret-key"),
+			AccessTokenTTL:     parseDuration(getEnv("JWT_ACCESS_TOKEN_TTL", "15m"), 15*time.Minute),
+			RefreshTokenTTL:    parseDuration(getEnv("JWT_REFRESH_TOKEN_TTL", "168h"), 168*time.Hour),
+			RevocationCacheTTL: parseDuration(getEnv("JWT_REVOCATION_CACHE_TTL", "10s"), 10*time.Second),
 		},
 		API: APIConfig{
 			Version: getEnv("API_VERSION", "v1"),
 			Prefix:  getEnv("API_PREFIX", "/api"),
 		},
+		Outbox: OutboxConfig{
+			PollInterval: parseDuration(getEnv("OUTBOX_POLL_INTERVAL", "2s"), 2*time.Second),
+			BatchSize:    parseInt(getEnv("OUTBOX_BATCH_SIZE", "50"), 50),
+			Lease:        parseDuration(getEnv("OUTBOX_LEASE", "1m"), time.Minute),
+			MaxAttempts:  parseInt(getEnv("OUTBOX_MAX_ATTEMPTS", "10"), 10),
+			BaseBackoff:  parseDuration(getEnv("OUTBOX_BASE_BACKOFF", "5s"), 5*time.Second),
+			MaxBackoff:   parseDuration(getEnv("OUTBOX_MAX_BACKOFF", "1h"), time.Hour),
+		},
+		Expiry: ExpiryConfig{
+			Interval:  parseDuration(getEnv("BOOKING_EXPIRY_INTERVAL", "1m"), time.Minute),
+			BatchSize: parseInt(getEnv("BOOKING_EXPIRY_BATCH_SIZE", "100"), 100),
+		},
+		Webhooks: WebhookConfig{
+			PollInterval: parseDuration(getEnv("WEBHOOK_POLL_INTERVAL", "2s"), 2*time.Second),
+			BatchSize:    parseInt(getEnv("WEBHOOK_BATCH_SIZE", "20"), 20),
+			Timeout:      parseDuration(getEnv("WEBHOOK_TIMEOUT", "10s"), 10*time.Second),
+			MaxAttempts:  parseInt(getEnv("WEBHOOK_MAX_ATTEMPTS", "8"), 8),
+			BaseBackoff:  parseDuration(getEnv("WEBHOOK_BASE_BACKOFF", "30s"), 30*time.Second),
+			MaxBackoff:   parseDuration(getEnv("WEBHOOK_MAX_BACKOFF", "6h"), 6*time.Hour),
+		},
+		Mail: MailConfig{
+			Driver:       getEnv("MAIL_DRIVER", "console"),
+			From:         getEnv("MAIL_FROM", "SpaceBook <noreply@spacebook.local>"),
+			SMTPHost:     getEnv("SMTP_HOST", "localhost"),
+			SMTPPort:     getEnv("SMTP_PORT", "1025"),
+			SMTPUsername: getEnv("SMTP_USERNAME", ""),
+			SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+			FileDir:      getEnv("MAIL_FILE_DIR", "./mail"),
+			TemplatesDir: getEnv("MAIL_TEMPLATES_DIR", ""),
+		},
+		Account: AccountConfig{
+			BaseURL:                  getEnv("APP_BASE_URL", "http://localhost:3000"),
+			PasswordResetTTL:         parseDuration(getEnv("PASSWORD_RESET_TTL", "1h"), time.Hour),
+			EmailVerificationTTL:     parseDuration(getEnv("EMAIL_VERIFICATION_TTL", "48h"), 48*time.Hour),
+			OrgInvitationTTL:         parseDuration(getEnv("ORG_INVITATION_TTL", "168h"), 168*time.Hour),
+			RequireEmailVerification: parseBool(getEnv("AUTH_REQUIRE_EMAIL_VERIFICATION", "false"), false),
+		},
+		Payments: PaymentsConfig{
+			Provider:      getEnv("PAYMENT_PROVIDER", "fake"),
+			WebhookSecret: getEnv("PAYMENT_WEBHOOK_SECRET", "fake-webhook-secret"),
+		},
+		Ledger: LedgerConfig{
+			CommissionPercent:   parseInt(getEnv("PLATFORM_COMMISSION_PERCENT", "10"), 10),
+			PayoutPeriod:        parseDuration(getEnv("PAYOUT_PERIOD", "168h"), 168*time.Hour),
+			PayoutCheckInterval: parseDuration(getEnv("PAYOUT_CHECK_INTERVAL", "1h"), time.Hour),
+		},
+		Invoices: InvoiceConfig{
+			TaxName:    getEnv("INVOICE_TAX_NAME", "VAT"),
+			TaxPercent: parseInt(getEnv("INVOICE_TAX_PERCENT", "12"), 12),
+		},
+	}
+
+	if p := config.Ledger.CommissionPercent; p < 0 || p > 100 {
+		return nil, fmt.Errorf("PLATFORM_COMMISSION_PERCENT must be between 0 and 100, got %d", p)
+	}
+	if p := config.Invoices.TaxPercent; p < 0 || p > 100 {
+		return nil, fmt.Errorf("INVOICE_TAX_PERCENT must be between 0 and 100, got %d", p)
+	}
+	// интервалы фоновых воркеров идут в time.NewTicker, который паникует
+	// на нуле и отрицательных значениях
+	for _, iv := range []struct {
+		name  string
+		value time.Duration
+	}{
+		{"OUTBOX_POLL_INTERVAL", config.Outbox.PollInterval},
+		{"BOOKING_EXPIRY_INTERVAL", config.Expiry.Interval},
+		{"WEBHOOK_POLL_INTERVAL", config.Webhooks.PollInterval},
+		{"PAYOUT_CHECK_INTERVAL", config.Ledger.PayoutCheckInterval},
+	} {
+		if iv.value <= 0 {
+			return nil, fmt.Errorf("%s must be positive, got %s", iv.name, iv.value)
+		}
 	}
 
 	return config, nil
@@ -92,3 +243,19 @@ func parseDuration(s string, defaultDuration time.Duration) time.Duration {
 	}
 	return duration
 }
+
+func parseInt(s string, defaultValue int) int {
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return defaultValue
+	}
+	return v
+}
+
+func parseBool(s string, defaultValue bool) bool {
+	v, err := strconv.ParseBool(s)
+	if err != nil {
+		return defaultValue
+	}
+	return v
+}
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoadConfigIntervals(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		value   string
+		wantErr bool
+	}{
+		{"defaults", "", "", false},
+		{"custom poll interval", "OUTBOX_POLL_INTERVAL", "500ms", false},
+		{"zero outbox poll interval", "OUTBOX_POLL_INTERVAL", "0s", true},
+		{"negative expiry interval", "BOOKING_EXPIRY_INTERVAL", "-1m", true},
+		{"zero webhook poll interval", "WEBHOOK_POLL_INTERVAL", "0", true},
+		{"zero payout check interval", "PAYOUT_CHECK_INTERVAL", "0h", true},
+		{"unparsable interval falls back to default", "WEBHOOK_POLL_INTERVAL", "soon", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.key != "" {
+				t.Setenv(tt.key, tt.value)
+			}
+			_, err := LoadConfig()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadConfig() error = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), tt.key) {
+				t.Errorf("error %q does not name %s", err, tt.key)
+			}
+		})
+	}
+}
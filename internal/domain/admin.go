@@ -0,0 +1,38 @@
+package domain
+
+import "time"
+
+type AuditAction string
+
+const (
+	AuditUserSuspended    AuditAction = "user.suspended"
+	AuditUserReinstated   AuditAction = "user.reinstated"
+	AuditBookingCancelled AuditAction = "booking.force_cancelled"
+	AuditSpaceUnpublished AuditAction = "space.unpublished"
+	AuditSpaceRepublished AuditAction = "space.republished"
+)
+
+type AuditTargetType string
+
+const (
+	AuditTargetUser    AuditTargetType = "user"
+	AuditTargetBooking AuditTargetType = "booking"
+	AuditTargetSpace   AuditTargetType = "space"
+)
+
+// AuditEntry — запись журнала действий администратора.
+type AuditEntry struct {
+	ID         int64           `json:"id" db:"id"`
+	AdminID    int             `json:"admin_id" db:"admin_id"`
+	Action     AuditAction     `json:"action" db:"action"`
+	TargetType AuditTargetType `json:"target_type" db:"target_type"`
+	TargetID   int64           `json:"target_id" db:"target_id"`
+	Reason     string          `json:"reason" db:"reason"`
+	CreatedAt  time.Time       `json:"created_at" db:"created_at"`
+}
+
+// ModerationRequest — тело любого модерационного действия: причина
+// обязательна и попадает в журнал.
+type ModerationRequest struct {
+	Reason string `json:"reason" binding:"required,min=3"`
+}
@@ -0,0 +1,42 @@
+package domain
+
+import "time"
+
+type IntervalSource string
+
+const (
+	IntervalSourceBooking  IntervalSource = "booking"
+	IntervalSourcePending  IntervalSource = "pending"
+	IntervalSourceBlackout IntervalSource = "blackout"
+)
+
+// Blackout — период, когда владелец закрыл помещение для бронирования.
+type Blackout struct {
+	ID        int       `json:"id" db:"id"`
+	SpaceID   int       `json:"space_id" db:"space_id"`
+	StartsAt  time.Time `json:"starts_at" db:"starts_at"`
+	EndsAt    time.Time `json:"ends_at" db:"ends_at"`
+	Reason    string    `json:"reason" db:"reason"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+type CreateBlackoutRequest struct {
+	StartsAt string `json:"starts_at" binding:"required"`
+	EndsAt   string `json:"ends_at" binding:"required"`
+	Reason   string `json:"reason"`
+}
+
+type Interval struct {
+	From   time.Time      `json:"from"`
+	To     time.Time      `json:"to"`
+	Source IntervalSource `json:"source,omitempty"`
+}
+
+type Availability struct {
+	SpaceID  int        `json:"space_id"`
+	Timezone string     `json:"timezone"`
+	From     time.Time  `json:"from"`
+	To       time.Time  `json:"to"`
+	Free     []Interval `json:"free"`
+	Busy     []Interval `json:"busy"`
+}
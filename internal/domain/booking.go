@@ -1,28 +1,149 @@
 package domain
 
-import "time"
+import (
+	"errors"
+	"fmt"
+	"time"
+)
 
 type BookingStatus string
 
 const (
-	BookingStatusPending   BookingStatus = "pending"
-	BookingStatusApproved  BookingStatus = "approved"
+	// BookingStatusPendingCompany — заявка ждёт согласования внутри
+	// компании и владельцу ещё не видна.
+	BookingStatusPendingCompany BookingStatus = "pending_company"
+	BookingStatusPending        BookingStatus = "pending"
+	BookingStatusApproved       BookingStatus = "approved"
+	// BookingStatusConfirmed — одобренная бронь оплачена: платёж списан.
+	BookingStatusConfirmed BookingStatus = "confirmed"
 	BookingStatusRejected  BookingStatus = "rejected"
 	BookingStatusCancelled BookingStatus = "cancelled"
+	// BookingStatusExpired — заявку не рассмотрели вовремя.
+	BookingStatusExpired   BookingStatus = "expired"
+	BookingStatusCheckedIn BookingStatus = "checked_in"
+	BookingStatusCompleted BookingStatus = "completed"
+	BookingStatusNoShow    BookingStatus = "no_show"
 )
 
+// OccupyingStatuses — статусы, в которых бронь занимает помещение; их
+// же перечисляет constraint bookings_no_approved_overlap.
+var OccupyingStatuses = []BookingStatus{
+	BookingStatusApproved,
+	BookingStatusConfirmed,
+	BookingStatusCheckedIn,
+	BookingStatusCompleted,
+}
+
+// ErrInvalidTransition — общая причина всех отказов в смене статуса;
+// конкретный переход описывает TransitionError.
+var ErrInvalidTransition = errors.New("invalid booking status transition")
+
+// TransitionError сообщает, из какого статуса в какой бронь перевести нельзя.
+type TransitionError struct {
+	From BookingStatus
+	To   BookingStatus
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("booking cannot move from %s to %s", e.From, e.To)
+}
+
+func (e *TransitionError) Unwrap() error {
+	return ErrInvalidTransition
+}
+
+// bookingTransitions — единственный источник правды о жизненном цикле
+// брони: для каждого статуса перечислены допустимые следующие и событие,
+// которое порождает переход. Пустое событие означает переход без
+// уведомлений: например, отмену заявки, не дошедшей до владельца.
+var bookingTransitions = map[BookingStatus]map[BookingStatus]BookingEventType{
+	BookingStatusPendingCompany: {
+		BookingStatusPending:   BookingEventCreated,
+		BookingStatusRejected:  BookingEventCompanyRejected,
+		BookingStatusCancelled: "",
+		BookingStatusExpired:   "",
+	},
+	BookingStatusPending: {
+		BookingStatusApproved:  BookingEventApproved,
+		BookingStatusRejected:  BookingEventRejected,
+		BookingStatusCancelled: BookingEventCancelled,
+		BookingStatusExpired:   BookingEventExpired,
+	},
+	BookingStatusApproved: {
+		BookingStatusConfirmed: BookingEventConfirmed,
+		BookingStatusCancelled: BookingEventCancelled,
+		BookingStatusCheckedIn: BookingEventCheckedIn,
+		BookingStatusCompleted: BookingEventCompleted,
+		BookingStatusNoShow:    BookingEventNoShow,
+	},
+	BookingStatusConfirmed: {
+		BookingStatusCancelled: BookingEventCancelled,
+		BookingStatusCheckedIn: BookingEventCheckedIn,
+		BookingStatusCompleted: BookingEventCompleted,
+		BookingStatusNoShow:    BookingEventNoShow,
+	},
+	BookingStatusCheckedIn: {
+		BookingStatusCompleted: BookingEventCompleted,
+	},
+}
+
+// TransitionEvent проверяет переход from -> to по таблице и возвращает
+// событие, которое нужно отправить в outbox.
+func TransitionEvent(from, to BookingStatus) (BookingEventType, error) {
+	event, ok := bookingTransitions[from][to]
+	if !ok {
+		return "", &TransitionError{From: from, To: to}
+	}
+	return event, nil
+}
+
+// Booking — бронь помещения. Price рассчитывается по тарифу при создании
+// и больше не зависит от его правок; пуст, если тарифа тогда не было.
 type Booking struct {
-	ID        int           `json:"id" db:"id"`
-	SpaceID   int           `json:"space_id" db:"space_id"`
-	TenantID  int           `json:"tenant_id" db:"tenant_id"`
-	Status    BookingStatus `json:"status" db:"status"`
-	DateFrom  time.Time     `json:"date_from" db:"date_from"`
-	DateTo    time.Time     `json:"date_to" db:"date_to"`
-	CreatedAt time.Time     `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time     `json:"updated_at" db:"updated_at"`
+	ID             int           `json:"id" db:"id"`
+	SpaceID        int           `json:"space_id" db:"space_id"`
+	TenantID       int           `json:"tenant_id" db:"tenant_id"`
+	OrganizationID *int          `json:"organization_id,omitempty" db:"organization_id"`
+	SeriesID       *int          `json:"series_id,omitempty" db:"series_id"`
+	Status         BookingStatus `json:"status" db:"status"`
+	DateFrom       time.Time     `json:"date_from" db:"date_from"`
+	DateTo         time.Time     `json:"date_to" db:"date_to"`
+	RespondBy      *time.Time    `json:"respond_by,omitempty" db:"respond_by"`
+	Price          *Money        `json:"price,omitempty"`
+	CreatedAt      time.Time     `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time     `json:"updated_at" db:"updated_at"`
+}
+
+// BookingTransition — запрошенная смена статуса. ActorID пуст, когда
+// переход выполняет система, а не пользователь. CancelledBy задаётся при
+// отмене: по нему рассчитывается и сохраняется возврат.
+type BookingTransition struct {
+	To          BookingStatus
+	ActorID     *int
+	Reason      string
+	CancelledBy CancellationParty
 }
+
+// BookingStatusChange — запись истории статусов брони. FromStatus пуст
+// у первой записи, сделанной при создании.
+type BookingStatusChange struct {
+	ID         int64          `json:"id"`
+	BookingID  int            `json:"booking_id"`
+	FromStatus *BookingStatus `json:"from_status"`
+	ToStatus   BookingStatus  `json:"to_status"`
+	ActorID    *int           `json:"actor_id"`
+	Reason     *string        `json:"reason,omitempty"`
+	CreatedAt  time.Time      `json:"created_at"`
+}
+
+// StatusChangeRequest — необязательное тело запросов на смену статуса.
+type StatusChangeRequest struct {
+	Reason string `json:"reason" binding:"max=500"`
+}
+
 type CreateBookingRequest struct {
-	SpaceID  int    `json:"space_id" binding:"required"`
-	DateFrom string `json:"date_from" binding:"required"`
-	DateTo   string `json:"date_to" binding:"required"`
+	SpaceID        int    `json:"space_id" binding:"required"`
+	DateFrom       string `json:"date_from" binding:"required"`
+	DateTo         string `json:"date_to" binding:"required"`
+	OrganizationID *int   `json:"organization_id"`
 }
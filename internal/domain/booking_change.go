@@ -0,0 +1,36 @@
+package domain
+
+import "time"
+
+type BookingChangeStatus string
+
+const (
+	BookingChangePending  BookingChangeStatus = "pending"
+	BookingChangeApproved BookingChangeStatus = "approved"
+	BookingChangeRejected BookingChangeStatus = "rejected"
+	// BookingChangeSuperseded — арендатор прислал новый запрос раньше,
+	// чем владелец ответил на этот.
+	BookingChangeSuperseded BookingChangeStatus = "superseded"
+)
+
+// BookingChangeRequest — просьба арендатора перенести одобренную бронь.
+// Даты брони меняются, только когда владелец её одобрит.
+type BookingChangeRequest struct {
+	ID          int64               `json:"id"`
+	BookingID   int                 `json:"booking_id"`
+	DateFrom    time.Time           `json:"date_from"`
+	DateTo      time.Time           `json:"date_to"`
+	Status      BookingChangeStatus `json:"status"`
+	Reason      *string             `json:"reason,omitempty"`
+	RequestedBy int                 `json:"requested_by"`
+	DecidedBy   *int                `json:"decided_by,omitempty"`
+	DecidedAt   *time.Time          `json:"decided_at,omitempty"`
+	CreatedAt   time.Time           `json:"created_at"`
+}
+
+// ModifyBookingRequest — новые даты брони в тех же форматах, что и при создании.
+type ModifyBookingRequest struct {
+	DateFrom string `json:"date_from" binding:"required"`
+	DateTo   string `json:"date_to" binding:"required"`
+	Reason   string `json:"reason" binding:"max=500"`
+}
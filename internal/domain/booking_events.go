@@ -7,14 +7,49 @@ type BookingEventType string
 const (
 	BookingEventCreated   BookingEventType = "created"
 	BookingEventApproved  BookingEventType = "approved"
+	BookingEventConfirmed BookingEventType = "confirmed"
 	BookingEventRejected  BookingEventType = "rejected"
 	BookingEventCancelled BookingEventType = "cancelled"
+	BookingEventExpired   BookingEventType = "expired"
+	BookingEventModified  BookingEventType = "modified"
+	BookingEventCheckedIn BookingEventType = "checked_in"
+	BookingEventCompleted BookingEventType = "completed"
+	BookingEventNoShow    BookingEventType = "no_show"
+
+	// события согласования внутри компании; когда компания одобряет
+	// заявку, владелец получает обычное created
+	BookingEventCompanyApprovalRequested BookingEventType = "company_approval_requested"
+	BookingEventCompanyRejected          BookingEventType = "company_rejected"
 )
 
+// OwnerVisible сообщает, касается ли событие владельца помещения.
+// Внутренние события компании владельцу (и его вебхукам) не показываются.
+func (t BookingEventType) OwnerVisible() bool {
+	return t != BookingEventCompanyApprovalRequested && t != BookingEventCompanyRejected
+}
+
 type BookingEvent struct {
+	ID        int64            `json:"id"`
 	Type      BookingEventType `json:"type"`
 	BookingID int              `json:"booking_id"`
 	SpaceID   int              `json:"space_id"`
 	TenantID  int              `json:"tenant_id"`
 	At        time.Time        `json:"at"`
+	// Cancellation — расчёт возврата; есть только у события cancelled.
+	Cancellation *Cancellation `json:"cancellation,omitempty"`
+}
+
+type OutboxStatus string
+
+const (
+	OutboxStatusPending   OutboxStatus = "pending"
+	OutboxStatusDelivered OutboxStatus = "delivered"
+	OutboxStatusDead      OutboxStatus = "dead"
+)
+
+// OutboxMessage — событие, захваченное воркером из таблицы outbox.
+type OutboxMessage struct {
+	ID       int64
+	Event    BookingEvent
+	Attempts int
 }
@@ -0,0 +1,37 @@
+package domain
+
+import "time"
+
+// BookingSeries — повторяющаяся бронь. Каждое вхождение — обычная бронь
+// со ссылкой на серию, поэтому одобрять и отменять их можно и по одному.
+type BookingSeries struct {
+	ID             int       `json:"id"`
+	SpaceID        int       `json:"space_id"`
+	TenantID       int       `json:"tenant_id"`
+	OrganizationID *int      `json:"organization_id,omitempty"`
+	RRule          string    `json:"rrule"`
+	Exceptions     []string  `json:"exceptions"`
+	CreatedAt      time.Time `json:"created_at"`
+	Bookings       []Booking `json:"bookings,omitempty"`
+}
+
+// CreateSeriesRequest — первое вхождение задаётся как обычная бронь,
+// остальные получаются из RRule. Exceptions — даты YYYY-MM-DD, которые
+// нужно пропустить.
+type CreateSeriesRequest struct {
+	SpaceID        int      `json:"space_id" binding:"required"`
+	DateFrom       string   `json:"date_from" binding:"required"`
+	DateTo         string   `json:"date_to" binding:"required"`
+	RRule          string   `json:"rrule" binding:"required"`
+	Exceptions     []string `json:"exceptions" binding:"dive,datetime=2006-01-02"`
+	OrganizationID *int     `json:"organization_id"`
+	// SkipConflicts создаёт серию без занятых вхождений вместо отказа.
+	SkipConflicts bool `json:"skip_conflicts"`
+}
+
+// SeriesConflict — вхождение, которое нельзя забронировать, и причина.
+type SeriesConflict struct {
+	DateFrom time.Time `json:"date_from"`
+	DateTo   time.Time `json:"date_to"`
+	Reason   string    `json:"reason"`
+}
@@ -0,0 +1,131 @@
+package domain
+
+import (
+	"errors"
+	"sort"
+	"time"
+)
+
+type CancellationPolicyKind string
+
+const (
+	CancellationFlexible CancellationPolicyKind = "flexible"
+	CancellationModerate CancellationPolicyKind = "moderate"
+	CancellationStrict   CancellationPolicyKind = "strict"
+	CancellationCustom   CancellationPolicyKind = "custom"
+)
+
+// CancellationParty — кто отменил бронь. От этого зависит возврат:
+// арендатор получает его по политике помещения, а при отмене владельцем
+// или администратором деньги возвращаются полностью.
+type CancellationParty string
+
+const (
+	CancelledByTenant CancellationParty = "tenant"
+	CancelledByOwner  CancellationParty = "owner"
+	CancelledByAdmin  CancellationParty = "admin"
+)
+
+var (
+	ErrInvalidCancellationPolicy = errors.New("policy must be flexible, moderate, strict or custom")
+	ErrInvalidRefundTiers        = errors.New("custom policy needs tiers with distinct hours_before and percent from 0 to 100")
+)
+
+// RefundTier — возврат Percent процентов при отмене не позже чем за
+// HoursBefore часов до начала брони.
+type RefundTier struct {
+	HoursBefore int `json:"hours_before" binding:"gte=0"`
+	Percent     int `json:"percent" binding:"gte=0,lte=100"`
+}
+
+// presetTiers — стандартные политики; у custom ступени задаёт владелец.
+var presetTiers = map[CancellationPolicyKind][]RefundTier{
+	// полный возврат до суток до начала
+	CancellationFlexible: {{HoursBefore: 24, Percent: 100}},
+	// полный возврат за 5 дней, дальше половина до самого начала
+	CancellationModerate: {{HoursBefore: 120, Percent: 100}, {HoursBefore: 0, Percent: 50}},
+	// половина за неделю, полный возврат только за две
+	CancellationStrict: {{HoursBefore: 336, Percent: 100}, {HoursBefore: 168, Percent: 50}},
+}
+
+type CancellationPolicy struct {
+	Kind  CancellationPolicyKind `json:"kind" binding:"required"`
+	Tiers []RefundTier           `json:"tiers" binding:"dive"`
+}
+
+// DefaultCancellationPolicy действует у помещений, где владелец политику не выбирал.
+func DefaultCancellationPolicy() CancellationPolicy {
+	return CancellationPolicy{Kind: CancellationFlexible, Tiers: presetTiers[CancellationFlexible]}
+}
+
+// Normalize проверяет политику и подставляет ступени стандартной политики;
+// ступени custom сортируются от самой ранней.
+func (p CancellationPolicy) Normalize() (CancellationPolicy, error) {
+	if p.Kind == CancellationCustom {
+		if len(p.Tiers) == 0 {
+			return p, ErrInvalidRefundTiers
+		}
+		tiers := append([]RefundTier(nil), p.Tiers...)
+		sort.Slice(tiers, func(i, j int) bool { return tiers[i].HoursBefore > tiers[j].HoursBefore })
+		for i, t := range tiers {
+			if t.Percent < 0 || t.Percent > 100 || t.HoursBefore < 0 {
+				return p, ErrInvalidRefundTiers
+			}
+			if i > 0 && t.HoursBefore == tiers[i-1].HoursBefore {
+				return p, ErrInvalidRefundTiers
+			}
+		}
+		return CancellationPolicy{Kind: p.Kind, Tiers: tiers}, nil
+	}
+
+	tiers, ok := presetTiers[p.Kind]
+	if !ok {
+		return p, ErrInvalidCancellationPolicy
+	}
+	return CancellationPolicy{Kind: p.Kind, Tiers: tiers}, nil
+}
+
+// RefundPercent возвращает процент возврата при отмене в момент at брони,
+// начинающейся в start: лучшую из ступеней, срок которой ещё не прошёл.
+func (p CancellationPolicy) RefundPercent(start, at time.Time) int {
+	left := start.Sub(at)
+	percent := 0
+	for _, t := range p.Tiers {
+		if left >= time.Duration(t.HoursBefore)*time.Hour && t.Percent > percent {
+			percent = t.Percent
+		}
+	}
+	return percent
+}
+
+// Cancellation — расчёт возврата при отмене брони. Refund и Fee пусты,
+// если у брони нет стоимости.
+type Cancellation struct {
+	BookingID     int                    `json:"booking_id"`
+	CancelledBy   CancellationParty      `json:"cancelled_by"`
+	Policy        CancellationPolicyKind `json:"policy"`
+	RefundPercent int                    `json:"refund_percent"`
+	Refund        *Money                 `json:"refund,omitempty"`
+	Fee           *Money                 `json:"fee,omitempty"`
+	CreatedAt     time.Time              `json:"created_at"`
+}
+
+// Cancel рассчитывает возврат при отмене брони b стороной by в момент at.
+func (p CancellationPolicy) Cancel(b *Booking, by CancellationParty, at time.Time) *Cancellation {
+	c := &Cancellation{
+		BookingID:     b.ID,
+		CancelledBy:   by,
+		Policy:        p.Kind,
+		RefundPercent: 100,
+		CreatedAt:     at,
+	}
+	if by == CancelledByTenant {
+		c.RefundPercent = p.RefundPercent(b.DateFrom, at)
+	}
+	if b.Price != nil {
+		refund := percentOf(b.Price.Amount, c.RefundPercent)
+		c.Refund = &Money{Amount: refund, Currency: b.Price.Currency}
+		c.Fee = &Money{Amount: b.Price.Amount - refund, Currency: b.Price.Currency}
+	}
+	return c
+}
@@ -0,0 +1,126 @@
+package domain
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCancellationPolicyNormalize(t *testing.T) {
+	tests := []struct {
+		name      string
+		policy    CancellationPolicy
+		wantTiers []RefundTier
+		wantErr   error
+	}{
+		{
+			name:      "preset ignores given tiers",
+			policy:    CancellationPolicy{Kind: CancellationStrict, Tiers: []RefundTier{{HoursBefore: 1, Percent: 100}}},
+			wantTiers: []RefundTier{{HoursBefore: 336, Percent: 100}, {HoursBefore: 168, Percent: 50}},
+		},
+		{
+			name: "custom tiers out of order are sorted from the earliest",
+			policy: CancellationPolicy{Kind: CancellationCustom, Tiers: []RefundTier{
+				{HoursBefore: 0, Percent: 10}, {HoursBefore: 72, Percent: 100}, {HoursBefore: 24, Percent: 50},
+			}},
+			wantTiers: []RefundTier{{HoursBefore: 72, Percent: 100}, {HoursBefore: 24, Percent: 50}, {HoursBefore: 0, Percent: 10}},
+		},
+		{
+			name:    "custom without tiers",
+			policy:  CancellationPolicy{Kind: CancellationCustom},
+			wantErr: ErrInvalidRefundTiers,
+		},
+		{
+			name: "custom with duplicate hours",
+			policy: CancellationPolicy{Kind: CancellationCustom, Tiers: []RefundTier{
+				{HoursBefore: 24, Percent: 100}, {HoursBefore: 48, Percent: 100}, {HoursBefore: 24, Percent: 50},
+			}},
+			wantErr: ErrInvalidRefundTiers,
+		},
+		{
+			name:    "custom with percent above 100",
+			policy:  CancellationPolicy{Kind: CancellationCustom, Tiers: []RefundTier{{HoursBefore: 24, Percent: 120}}},
+			wantErr: ErrInvalidRefundTiers,
+		},
+		{
+			name:    "unknown kind",
+			policy:  CancellationPolicy{Kind: "lenient"},
+			wantErr: ErrInvalidCancellationPolicy,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.policy.Normalize()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Normalize() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(got.Tiers, tt.wantTiers) {
+				t.Errorf("tiers = %+v, want %+v", got.Tiers, tt.wantTiers)
+			}
+		})
+	}
+}
+
+func TestCancellationPolicyRefundPercent(t *testing.T) {
+	start := time.Date(2026, 3, 20, 12, 0, 0, 0, time.UTC)
+	custom := CancellationPolicy{Kind: CancellationCustom, Tiers: []RefundTier{
+		{HoursBefore: 0, Percent: 10}, {HoursBefore: 72, Percent: 100}, {HoursBefore: 24, Percent: 50},
+	}}
+
+	tests := []struct {
+		name   string
+		policy CancellationPolicy
+		before time.Duration
+		want   int
+	}{
+		{"flexible exactly at the tier", DefaultCancellationPolicy(), 24 * time.Hour, 100},
+		{"flexible just after the tier", DefaultCancellationPolicy(), 24*time.Hour - time.Second, 0},
+		{"moderate exactly at five days", CancellationPolicy{Kind: CancellationModerate, Tiers: presetTiers[CancellationModerate]}, 120 * time.Hour, 100},
+		{"moderate at the start", CancellationPolicy{Kind: CancellationModerate, Tiers: presetTiers[CancellationModerate]}, 0, 50},
+		{"moderate after the start", CancellationPolicy{Kind: CancellationModerate, Tiers: presetTiers[CancellationModerate]}, -time.Hour, 0},
+		{"strict between tiers", CancellationPolicy{Kind: CancellationStrict, Tiers: presetTiers[CancellationStrict]}, 200 * time.Hour, 50},
+		{"strict exactly at one week", CancellationPolicy{Kind: CancellationStrict, Tiers: presetTiers[CancellationStrict]}, 168 * time.Hour, 50},
+		{"custom out of order, earliest tier", custom, 72 * time.Hour, 100},
+		{"custom out of order, middle tier", custom, 71 * time.Hour, 50},
+		{"custom out of order, last tier", custom, time.Hour, 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.RefundPercent(start, start.Add(-tt.before)); got != tt.want {
+				t.Errorf("RefundPercent() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCancellationPolicyCancel(t *testing.T) {
+	start := time.Date(2026, 3, 20, 12, 0, 0, 0, time.UTC)
+	at := start.Add(-48 * time.Hour)
+	policy := CancellationPolicy{Kind: CancellationModerate, Tiers: presetTiers[CancellationModerate]}
+
+	tests := []struct {
+		name        string
+		by          CancellationParty
+		price       *Money
+		wantPercent int
+		wantRefund  *Money
+		wantFee     *Money
+	}{
+		{"tenant gets the policy refund", CancelledByTenant, &Money{Amount: 10001, Currency: "EUR"}, 50, &Money{Amount: 5001, Currency: "EUR"}, &Money{Amount: 5000, Currency: "EUR"}},
+		{"owner cancellation refunds in full", CancelledByOwner, &Money{Amount: 10000, Currency: "EUR"}, 100, &Money{Amount: 10000, Currency: "EUR"}, &Money{Amount: 0, Currency: "EUR"}},
+		{"booking without price", CancelledByTenant, nil, 50, nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &Booking{ID: 7, DateFrom: start, Price: tt.price}
+			c := policy.Cancel(b, tt.by, at)
+			if c.RefundPercent != tt.wantPercent {
+				t.Errorf("refund percent = %d, want %d", c.RefundPercent, tt.wantPercent)
+			}
+			if !reflect.DeepEqual(c.Refund, tt.wantRefund) || !reflect.DeepEqual(c.Fee, tt.wantFee) {
+				t.Errorf("refund, fee = %+v, %+v, want %+v, %+v", c.Refund, c.Fee, tt.wantRefund, tt.wantFee)
+			}
+		})
+	}
+}
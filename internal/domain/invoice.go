@@ -0,0 +1,121 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+type InvoiceKind string
+
+const (
+	InvoiceKindInvoice    InvoiceKind = "invoice"
+	InvoiceKindCreditNote InvoiceKind = "credit_note"
+	// InvoiceKindProforma — счёт одобренной, но не подтверждённой брони:
+	// не сохраняется и не занимает номер, пока даты и цена могут измениться.
+	InvoiceKindProforma InvoiceKind = "proforma"
+)
+
+// InvoiceParty — реквизиты стороны документа на момент выставления;
+// последующие правки профиля выставленный документ не меняют.
+type InvoiceParty struct {
+	Name  string `json:"name"`
+	Email string `json:"email,omitempty"`
+	Phone string `json:"phone,omitempty"`
+}
+
+type InvoiceLine struct {
+	Description string `json:"description"`
+	Quantity    int    `json:"quantity"`
+	UnitAmount  int64  `json:"unit_amount"`
+	Amount      int64  `json:"amount"`
+}
+
+// InvoiceTax — налог, включённый в сумму документа.
+type InvoiceTax struct {
+	Name    string `json:"name"`
+	Percent int    `json:"percent"`
+	Base    int64  `json:"base"`
+	Amount  int64  `json:"amount"`
+}
+
+// TaxRate — налог, который включается в выставляемые документы.
+type TaxRate struct {
+	Name    string
+	Percent int
+}
+
+// Invoice — счёт за аренду или корректировочный документ (credit note) к
+// нему при возврате. Номера идут подряд без пропусков внутри серии
+// выставителя: организации, если помещение её, иначе владельца.
+type Invoice struct {
+	ID         int           `json:"id"`
+	Kind       InvoiceKind   `json:"kind"`
+	Series     string        `json:"series"`
+	Sequence   int           `json:"sequence"`
+	Number     string        `json:"number"`
+	BookingID  int           `json:"booking_id"`
+	PaymentID  *int          `json:"payment_id,omitempty"`
+	CorrectsID *int          `json:"corrects_id,omitempty"`
+	Issuer     InvoiceParty  `json:"issuer"`
+	Customer   InvoiceParty  `json:"customer"`
+	Lines      []InvoiceLine `json:"lines"`
+	Taxes      []InvoiceTax  `json:"taxes"`
+	Subtotal   int64         `json:"subtotal"`
+	TaxTotal   int64         `json:"tax_total"`
+	Total      int64         `json:"total"`
+	Currency   string        `json:"currency"`
+	IssuedAt   time.Time     `json:"issued_at"`
+}
+
+// InvoiceSeries — серия нумерации выставителя.
+func InvoiceSeries(ownerID int, organizationID *int) string {
+	if organizationID != nil {
+		return fmt.Sprintf("ORG%d", *organizationID)
+	}
+	return fmt.Sprintf("OWN%d", ownerID)
+}
+
+// InvoiceNumber — печатный номер документа, например INV-ORG3-000042.
+func InvoiceNumber(kind InvoiceKind, series string, sequence int) string {
+	prefix := "INV"
+	if kind == InvoiceKindCreditNote {
+		prefix = "CN"
+	}
+	return fmt.Sprintf("%s-%s-%06d", prefix, series, sequence)
+}
+
+// ProformaNumber — печатный номер pro-forma счёта; в серию он не входит.
+func ProformaNumber(bookingID int) string {
+	return fmt.Sprintf("PF-B%d", bookingID)
+}
+
+// ApplyTax раскладывает total, в который налог уже включён, на строку
+// налога и сумму без налога.
+func (inv *Invoice) ApplyTax(rate TaxRate) {
+	tax := InvoiceTax{Name: rate.Name, Percent: rate.Percent}
+	tax.Base = (inv.Total*100 + int64(100+rate.Percent)/2) / int64(100+rate.Percent)
+	tax.Amount = inv.Total - tax.Base
+
+	inv.Taxes = []InvoiceTax{tax}
+	inv.Subtotal = tax.Base
+	inv.TaxTotal = tax.Amount
+}
+
+// FormatAmount печатает сумму в минимальных единицах с двумя знаками
+// после запятой и разделителем тысяч: 123456 → "1 234.56".
+func FormatAmount(amount int64) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	units := fmt.Sprintf("%d", amount/100)
+	var grouped []byte
+	for i := range units {
+		if i > 0 && (len(units)-i)%3 == 0 {
+			grouped = append(grouped, ' ')
+		}
+		grouped = append(grouped, units[i])
+	}
+	return fmt.Sprintf("%s%s.%02d", sign, grouped, amount%100)
+}
@@ -0,0 +1,104 @@
+package domain
+
+import "time"
+
+// LedgerAccountKind — вид счёта в главной книге. Счета platform и fees
+// общие, owner и tenant заводятся на каждого пользователя.
+type LedgerAccountKind string
+
+const (
+	// LedgerAccountPlatform — деньги, которые платформа держит у шлюза.
+	LedgerAccountPlatform LedgerAccountKind = "platform"
+	// LedgerAccountOwner — долг платформы перед владельцем.
+	LedgerAccountOwner LedgerAccountKind = "owner"
+	// LedgerAccountTenant — расчёты с арендатором; у оплаченных броней в нуле.
+	LedgerAccountTenant LedgerAccountKind = "tenant"
+	// LedgerAccountFees — заработанная платформой комиссия.
+	LedgerAccountFees LedgerAccountKind = "fees"
+)
+
+type LedgerTransactionKind string
+
+const (
+	LedgerBookingCharge LedgerTransactionKind = "booking_charge"
+	LedgerRefund        LedgerTransactionKind = "refund"
+	LedgerCommission    LedgerTransactionKind = "commission"
+	LedgerPayout        LedgerTransactionKind = "payout"
+)
+
+type LedgerAccount struct {
+	ID       int               `json:"id"`
+	Kind     LedgerAccountKind `json:"kind"`
+	UserID   *int              `json:"user_id,omitempty"`
+	Currency string            `json:"currency"`
+}
+
+// LedgerEntry — проводка по счёту. Amount положителен для дебета и
+// отрицателен для кредита, поэтому сумма проводок транзакции равна нулю,
+// а баланс счёта — это просто сумма его проводок.
+type LedgerEntry struct {
+	ID            int64                 `json:"id"`
+	TransactionID int64                 `json:"transaction_id"`
+	Kind          LedgerTransactionKind `json:"kind"`
+	AccountID     int                   `json:"account_id"`
+	Amount        int64                 `json:"amount"`
+	Currency      string                `json:"currency"`
+	BookingID     *int                  `json:"booking_id,omitempty"`
+	PaymentID     *int                  `json:"payment_id,omitempty"`
+	Description   string                `json:"description"`
+	CreatedAt     time.Time             `json:"created_at"`
+}
+
+// LedgerLine — часть новой транзакции до записи в книгу.
+type LedgerLine struct {
+	Kind   LedgerAccountKind
+	UserID *int
+	Amount int64
+}
+
+// Payout — выплата владельцу всего, что платформа была должна ему на
+// момент пакета. Балансы — долг перед владельцем, то есть баланс его
+// счёта с обратным знаком. Выписка строится по проводкам счёта между
+// предыдущей выплатой и этой.
+type Payout struct {
+	ID              int       `json:"id"`
+	BatchID         int       `json:"batch_id"`
+	OwnerID         int       `json:"owner_id"`
+	Currency        string    `json:"currency"`
+	Amount          int64     `json:"amount"`
+	OpeningBalance  int64     `json:"opening_balance"`
+	ClosingBalance  int64     `json:"closing_balance"`
+	FromTransaction int64     `json:"-"`
+	ToTransaction   int64     `json:"-"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// PayoutStatement — выплата и проводки, из которых сложилась её сумма:
+// ClosingBalance = OpeningBalance − сумма Amount проводок Entries.
+type PayoutStatement struct {
+	Payout
+	Entries []LedgerEntry `json:"entries"`
+}
+
+// OwnerBalance — текущий долг платформы перед владельцем в валюте.
+type OwnerBalance struct {
+	Currency string `json:"currency"`
+	Amount   int64  `json:"amount"`
+}
+
+// LedgerReconciliation — сверка книги: суммы по видам счетов, транзакции
+// с ненулевой суммой проводок и расхождение счёта platform с платежами.
+type LedgerReconciliation struct {
+	Currency           string                      `json:"currency"`
+	Balances           map[LedgerAccountKind]int64 `json:"balances"`
+	UnbalancedTxIDs    []int64                     `json:"unbalanced_transaction_ids"`
+	PaymentsNet        int64                       `json:"payments_net"`
+	PayoutsTotal       int64                       `json:"payouts_total"`
+	PlatformDifference int64                       `json:"platform_difference"`
+}
+
+// Commission — комиссия платформы в percent процентов с суммы amount,
+// округлённая до минимальной единицы валюты.
+func Commission(amount int64, percent int) int64 {
+	return percentOf(amount, percent)
+}
@@ -0,0 +1,109 @@
+package domain
+
+import "time"
+
+type OrgRole string
+
+const (
+	OrgRoleAdmin   OrgRole = "admin"
+	OrgRoleManager OrgRole = "manager"
+	OrgRoleViewer  OrgRole = "viewer"
+)
+
+// SpaceAction — действие над помещением, которое проверяет политика доступа.
+type SpaceAction string
+
+const (
+	// SpaceActionView — видеть выключенное помещение, его брони и закрытые даты.
+	SpaceActionView SpaceAction = "view"
+	// SpaceActionDecide — одобрять и отклонять брони.
+	SpaceActionDecide SpaceAction = "decide"
+	// SpaceActionSchedule — закрывать и открывать даты.
+	SpaceActionSchedule SpaceAction = "schedule"
+	// SpaceActionManage — редактировать, включать, выключать и удалять помещение.
+	SpaceActionManage SpaceAction = "manage"
+)
+
+// CanApproveBookings сообщает, может ли участник согласовывать брони
+// сотрудников компании.
+func (r OrgRole) CanApproveBookings() bool {
+	return r == OrgRoleAdmin || r == OrgRoleManager
+}
+
+// Allows сообщает, разрешено ли участнику с этой ролью действие action
+// над помещениями организации.
+func (r OrgRole) Allows(action SpaceAction) bool {
+	switch r {
+	case OrgRoleAdmin:
+		return true
+	case OrgRoleManager:
+		return action != SpaceActionManage
+	case OrgRoleViewer:
+		return action == SpaceActionView
+	default:
+		return false
+	}
+}
+
+// Organization.BookingApprovalRequired включает согласование броней
+// сотрудников администратором или менеджером компании до отправки владельцу.
+type Organization struct {
+	ID                      int       `json:"id" db:"id"`
+	Name                    string    `json:"name" db:"name"`
+	CreatedBy               int       `json:"created_by" db:"created_by"`
+	BookingApprovalRequired bool      `json:"booking_approval_required" db:"booking_approval_required"`
+	CreatedAt               time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt               time.Time `json:"updated_at" db:"updated_at"`
+	// MyRole — роль текущего пользователя, заполняется в списке "мои организации".
+	MyRole OrgRole `json:"my_role,omitempty"`
+}
+
+type OrgMember struct {
+	OrganizationID int       `json:"organization_id" db:"organization_id"`
+	UserID         int       `json:"user_id" db:"user_id"`
+	Email          string    `json:"email" db:"email"`
+	FirstName      string    `json:"first_name" db:"first_name"`
+	LastName       string    `json:"last_name" db:"last_name"`
+	Role           OrgRole   `json:"role" db:"role"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+}
+
+type OrgInvitation struct {
+	ID             int64      `json:"id" db:"id"`
+	OrganizationID int        `json:"organization_id" db:"organization_id"`
+	Email          string     `json:"email" db:"email"`
+	Role           OrgRole    `json:"role" db:"role"`
+	InvitedBy      int        `json:"invited_by" db:"invited_by"`
+	ExpiresAt      time.Time  `json:"expires_at" db:"expires_at"`
+	AcceptedAt     *time.Time `json:"accepted_at,omitempty" db:"accepted_at"`
+	RevokedAt      *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+}
+
+type CreateOrganizationRequest struct {
+	Name string `json:"name" binding:"required,min=2,max=200"`
+}
+
+type UpdateOrganizationRequest struct {
+	Name                    *string `json:"name" binding:"omitempty,min=2,max=200"`
+	BookingApprovalRequired *bool   `json:"booking_approval_required"`
+}
+
+type InviteMemberRequest struct {
+	Email string  `json:"email" binding:"required,email"`
+	Role  OrgRole `json:"role" binding:"required,oneof=admin manager viewer"`
+}
+
+type AcceptInvitationRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type UpdateMemberRequest struct {
+	Role OrgRole `json:"role" binding:"required,oneof=admin manager viewer"`
+}
+
+// SetSpaceOrganizationRequest привязывает помещение к организации;
+// null отвязывает.
+type SetSpaceOrganizationRequest struct {
+	OrganizationID *int `json:"organization_id"`
+}
@@ -0,0 +1,36 @@
+package domain
+
+import "time"
+
+type PaymentStatus string
+
+const (
+	// PaymentStatusPending — платёж создан, шлюз ещё не ответил.
+	PaymentStatusPending PaymentStatus = "pending"
+	// PaymentStatusRequiresAction — плательщик должен подтвердить платёж
+	// у шлюза (например, 3-D Secure); результат придёт вебхуком.
+	PaymentStatusRequiresAction PaymentStatus = "requires_action"
+	PaymentStatusAuthorized     PaymentStatus = "authorized"
+	PaymentStatusCaptured       PaymentStatus = "captured"
+	PaymentStatusFailed         PaymentStatus = "failed"
+	// PaymentStatusRefunded — возвращена вся списанная сумма; частичный
+	// возврат оставляет статус captured и растит RefundedAmount.
+	PaymentStatusRefunded PaymentStatus = "refunded"
+)
+
+// Payment — платёж по брони у внешнего шлюза. У брони не больше одного
+// незавершённого или списанного платежа.
+type Payment struct {
+	ID             int           `json:"id"`
+	BookingID      int           `json:"booking_id"`
+	Provider       string        `json:"provider"`
+	ProviderRef    *string       `json:"provider_ref,omitempty"`
+	Amount         int64         `json:"amount"`
+	Currency       string        `json:"currency"`
+	Status         PaymentStatus `json:"status"`
+	RefundedAmount int64         `json:"refunded_amount"`
+	ActionURL      *string       `json:"action_url,omitempty"`
+	FailureReason  *string       `json:"failure_reason,omitempty"`
+	CreatedAt      time.Time     `json:"created_at"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+}
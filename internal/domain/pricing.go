@@ -0,0 +1,258 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Money — сумма в минимальных единицах валюты (копейках, центах).
+type Money struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+type PricingUnit string
+
+const (
+	PricingUnitHour  PricingUnit = "hour"
+	PricingUnitDay   PricingUnit = "day"
+	PricingUnitWeek  PricingUnit = "week"
+	PricingUnitMonth PricingUnit = "month"
+)
+
+var (
+	ErrInvalidCurrency = errors.New("currency must be a three-letter ISO 4217 code")
+	ErrNoRates         = errors.New("at least one of hour, day, week or month rate must be set")
+	ErrInvalidPricing  = errors.New("invalid pricing")
+)
+
+// PricingRates — ставки за единицу времени в минимальных единицах валюты;
+// пустая ставка означает, что помещение так не сдаётся.
+type PricingRates struct {
+	Hour  *int64 `json:"hour" binding:"omitempty,gt=0"`
+	Day   *int64 `json:"day" binding:"omitempty,gt=0"`
+	Week  *int64 `json:"week" binding:"omitempty,gt=0"`
+	Month *int64 `json:"month" binding:"omitempty,gt=0"`
+}
+
+// SeasonalRate меняет ставки на период: Percent — доля от обычной ставки,
+// 150 означает +50%. Даты включительные, по местному времени помещения.
+type SeasonalRate struct {
+	Label    string `json:"label" binding:"max=100"`
+	DateFrom string `json:"date_from" binding:"required"`
+	DateTo   string `json:"date_to" binding:"required"`
+	Percent  int    `json:"percent" binding:"required,gt=0"`
+}
+
+// LongStayDiscount — скидка в процентах на бронь не короче MinDays суток.
+type LongStayDiscount struct {
+	MinDays int `json:"min_days" binding:"required,gt=0"`
+	Percent int `json:"percent" binding:"required,gt=0,lt=100"`
+}
+
+// SpacePricing — тарифы помещения. Сезонный процент применяется к любой
+// единице по дате её начала, WeekendPercent — поверх него к часам и
+// суткам, начинающимся в субботу или воскресенье: недельная и месячная
+// ставки уже включают выходные.
+type SpacePricing struct {
+	SpaceID        int                `json:"space_id"`
+	Currency       string             `json:"currency"`
+	Rates          PricingRates       `json:"rates"`
+	WeekendPercent *int               `json:"weekend_percent,omitempty"`
+	Seasons        []SeasonalRate     `json:"seasons"`
+	Discounts      []LongStayDiscount `json:"long_stay_discounts"`
+	UpdatedAt      time.Time          `json:"updated_at"`
+}
+
+type SetPricingRequest struct {
+	Currency       string             `json:"currency" binding:"required,len=3"`
+	Rates          PricingRates       `json:"rates"`
+	WeekendPercent *int               `json:"weekend_percent" binding:"omitempty,gt=0"`
+	Seasons        []SeasonalRate     `json:"seasons" binding:"dive"`
+	Discounts      []LongStayDiscount `json:"long_stay_discounts" binding:"dive"`
+}
+
+type QuoteRequest struct {
+	DateFrom string `json:"date_from" binding:"required"`
+	DateTo   string `json:"date_to" binding:"required"`
+}
+
+// QuoteLine — строка расчёта: Quantity одинаковых единиц по UnitPrice.
+// Note объясняет надбавку — выходные или название сезона.
+type QuoteLine struct {
+	Unit      PricingUnit `json:"unit"`
+	DateFrom  time.Time   `json:"date_from"`
+	DateTo    time.Time   `json:"date_to"`
+	Quantity  int         `json:"quantity"`
+	UnitPrice int64       `json:"unit_price"`
+	Amount    int64       `json:"amount"`
+	Note      string      `json:"note,omitempty"`
+}
+
+type Quote struct {
+	SpaceID         int         `json:"space_id"`
+	Currency        string      `json:"currency"`
+	DateFrom        time.Time   `json:"date_from"`
+	DateTo          time.Time   `json:"date_to"`
+	Lines           []QuoteLine `json:"lines"`
+	Subtotal        int64       `json:"subtotal"`
+	DiscountPercent int         `json:"discount_percent,omitempty"`
+	Discount        int64       `json:"discount"`
+	Total           int64       `json:"total"`
+}
+
+func (q *Quote) TotalMoney() Money {
+	return Money{Amount: q.Total, Currency: q.Currency}
+}
+
+// Validate проверяет то, что не выразить тегами binding: код валюты,
+// наличие хотя бы одной ставки и корректность сезонов.
+func (p *SpacePricing) Validate() error {
+	if len(p.Currency) != 3 {
+		return ErrInvalidCurrency
+	}
+	for _, r := range p.Currency {
+		if r < 'A' || r > 'Z' {
+			return ErrInvalidCurrency
+		}
+	}
+	if p.Rates.Hour == nil && p.Rates.Day == nil && p.Rates.Week == nil && p.Rates.Month == nil {
+		return ErrNoRates
+	}
+	for _, s := range p.Seasons {
+		from, err := time.Parse(dateOnly, s.DateFrom)
+		if err != nil {
+			return fmt.Errorf("%w: season dates must be YYYY-MM-DD", ErrInvalidPricing)
+		}
+		to, err := time.Parse(dateOnly, s.DateTo)
+		if err != nil {
+			return fmt.Errorf("%w: season dates must be YYYY-MM-DD", ErrInvalidPricing)
+		}
+		if to.Before(from) {
+			return fmt.Errorf("%w: season %q ends before it starts", ErrInvalidPricing, s.Label)
+		}
+	}
+	return nil
+}
+
+const dateOnly = "2006-01-02"
+
+// Quote рассчитывает стоимость брони [from, to). Интервал покрывается
+// жадно, от крупных единиц к мелким: календарные месяцы, недели, сутки,
+// часы — из тех, для которых задана ставка. Каждый начатый час считается
+// целиком; остаток, для которого нет ставки мельче, оплачивается одной
+// единицей наименьшей заданной ставки. Скидка за длительность берётся
+// наибольшая из подходящих и применяется к сумме.
+func (p *SpacePricing) Quote(from, to time.Time, loc *time.Location) *Quote {
+	q := &Quote{
+		SpaceID:  p.SpaceID,
+		Currency: p.Currency,
+		DateFrom: from,
+		DateTo:   to,
+		Lines:    []QuoteLine{},
+	}
+
+	cursor := from.In(loc)
+	end := to.In(loc)
+	steps := []struct {
+		unit PricingUnit
+		rate *int64
+		next func(t time.Time) time.Time
+	}{
+		{PricingUnitMonth, p.Rates.Month, func(t time.Time) time.Time { return t.AddDate(0, 1, 0) }},
+		{PricingUnitWeek, p.Rates.Week, func(t time.Time) time.Time { return t.AddDate(0, 0, 7) }},
+		{PricingUnitDay, p.Rates.Day, func(t time.Time) time.Time { return t.AddDate(0, 0, 1) }},
+		{PricingUnitHour, p.Rates.Hour, func(t time.Time) time.Time { return t.Add(time.Hour) }},
+	}
+
+	smallest := -1
+	for i, st := range steps {
+		if st.rate == nil {
+			continue
+		}
+		smallest = i
+		for !cursor.Equal(end) {
+			next := st.next(cursor)
+			if next.After(end) {
+				if st.unit != PricingUnitHour {
+					break
+				}
+				next = end
+			}
+			q.add(p.line(st.unit, *st.rate, cursor, next, loc))
+			cursor = next
+		}
+	}
+	if !cursor.Equal(end) && smallest >= 0 {
+		st := steps[smallest]
+		q.add(p.line(st.unit, *st.rate, cursor, end, loc))
+	}
+
+	for _, l := range q.Lines {
+		q.Subtotal += l.Amount
+	}
+	days := int(to.Sub(from) / (24 * time.Hour))
+	for _, d := range p.Discounts {
+		if days >= d.MinDays && d.Percent > q.DiscountPercent {
+			q.DiscountPercent = d.Percent
+		}
+	}
+	q.Discount = percentOf(q.Subtotal, q.DiscountPercent)
+	q.Total = q.Subtotal - q.Discount
+	return q
+}
+
+// line оценивает одну единицу, начинающуюся в start, с учётом сезона и выходных.
+func (p *SpacePricing) line(unit PricingUnit, rate int64, start, end time.Time, loc *time.Location) QuoteLine {
+	price, note := rate, ""
+	day := start.In(loc).Format(dateOnly)
+	for _, s := range p.Seasons {
+		// даты в формате YYYY-MM-DD сравниваются как строки
+		if s.DateFrom <= day && day <= s.DateTo {
+			price, note = percentOf(price, s.Percent), s.Label
+			if note == "" {
+				note = "season"
+			}
+			break
+		}
+	}
+	if p.WeekendPercent != nil && (unit == PricingUnitHour || unit == PricingUnitDay) {
+		if wd := start.In(loc).Weekday(); wd == time.Saturday || wd == time.Sunday {
+			price = percentOf(price, *p.WeekendPercent)
+			if note == "" {
+				note = "weekend"
+			} else {
+				note += ", weekend"
+			}
+		}
+	}
+	return QuoteLine{
+		Unit:      unit,
+		DateFrom:  start,
+		DateTo:    end,
+		Quantity:  1,
+		UnitPrice: price,
+		Amount:    price,
+		Note:      note,
+	}
+}
+
+// add объединяет подряд идущие единицы с одинаковой ценой в одну строку.
+func (q *Quote) add(l QuoteLine) {
+	if n := len(q.Lines); n > 0 {
+		last := &q.Lines[n-1]
+		if last.Unit == l.Unit && last.UnitPrice == l.UnitPrice && last.Note == l.Note && last.DateTo.Equal(l.DateFrom) {
+			last.DateTo = l.DateTo
+			last.Quantity++
+			last.Amount += l.Amount
+			return
+		}
+	}
+	q.Lines = append(q.Lines, l)
+}
+
+// percentOf округляет до ближайшей минимальной единицы валюты.
+func percentOf(amount int64, percent int) int64 {
+	return (amount*int64(percent) + 50) / 100
+}
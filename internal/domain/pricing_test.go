@@ -0,0 +1,170 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSpacePricingQuote(t *testing.T) {
+	rate := func(v int64) *int64 { return &v }
+	percent := func(v int) *int { return &v }
+	at := func(s string) time.Time {
+		v, err := time.Parse("2006-01-02 15:04", s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+
+	type line struct {
+		unit      PricingUnit
+		quantity  int
+		unitPrice int64
+		note      string
+	}
+	tests := []struct {
+		name         string
+		pricing      SpacePricing
+		from, to     string
+		wantLines    []line
+		wantDiscount int64
+		wantTotal    int64
+	}{
+		{
+			name:    "greedy month, week, day and hour",
+			pricing: SpacePricing{Rates: PricingRates{Hour: rate(100), Day: rate(1000), Week: rate(5000), Month: rate(15000)}},
+			from:    "2026-01-05 10:00",
+			to:      "2026-02-17 13:30",
+			wantLines: []line{
+				{PricingUnitMonth, 1, 15000, ""},
+				{PricingUnitWeek, 1, 5000, ""},
+				{PricingUnitDay, 5, 1000, ""},
+				{PricingUnitHour, 4, 100, ""},
+			},
+			wantTotal: 25400,
+		},
+		{
+			name:      "started hour is charged in full",
+			pricing:   SpacePricing{Rates: PricingRates{Hour: rate(100)}},
+			from:      "2026-01-05 10:00",
+			to:        "2026-01-05 11:20",
+			wantLines: []line{{PricingUnitHour, 2, 100, ""}},
+			wantTotal: 200,
+		},
+		{
+			name:      "remainder without smaller rate costs one smallest unit",
+			pricing:   SpacePricing{Rates: PricingRates{Day: rate(1000)}},
+			from:      "2026-01-05 10:00",
+			to:        "2026-01-06 15:00",
+			wantLines: []line{{PricingUnitDay, 2, 1000, ""}},
+			wantTotal: 2000,
+		},
+		{
+			name: "season applies by unit start date",
+			pricing: SpacePricing{
+				Rates:   PricingRates{Day: rate(1000)},
+				Seasons: []SeasonalRate{{Label: "peak", DateFrom: "2026-01-06", DateTo: "2026-01-07", Percent: 150}},
+			},
+			from: "2026-01-05 00:00",
+			to:   "2026-01-08 00:00",
+			wantLines: []line{
+				{PricingUnitDay, 1, 1000, ""},
+				{PricingUnitDay, 2, 1500, "peak"},
+			},
+			wantTotal: 4000,
+		},
+		{
+			name: "weekend surcharge on days and hours",
+			pricing: SpacePricing{
+				Rates:          PricingRates{Hour: rate(100), Day: rate(1000)},
+				WeekendPercent: percent(120),
+			},
+			from: "2026-01-09 00:00",
+			to:   "2026-01-11 02:00",
+			wantLines: []line{
+				{PricingUnitDay, 1, 1000, ""},
+				{PricingUnitDay, 1, 1200, "weekend"},
+				{PricingUnitHour, 2, 120, "weekend"},
+			},
+			wantTotal: 2440,
+		},
+		{
+			name: "weekend surcharge stacks on season",
+			pricing: SpacePricing{
+				Rates:          PricingRates{Day: rate(1000)},
+				WeekendPercent: percent(120),
+				Seasons:        []SeasonalRate{{DateFrom: "2026-01-10", DateTo: "2026-01-10", Percent: 150}},
+			},
+			from:      "2026-01-10 00:00",
+			to:        "2026-01-11 00:00",
+			wantLines: []line{{PricingUnitDay, 1, 1800, "season, weekend"}},
+			wantTotal: 1800,
+		},
+		{
+			name: "weekly rate already includes weekends",
+			pricing: SpacePricing{
+				Rates:          PricingRates{Day: rate(1000), Week: rate(5000)},
+				WeekendPercent: percent(120),
+			},
+			from:      "2026-01-10 00:00",
+			to:        "2026-01-17 00:00",
+			wantLines: []line{{PricingUnitWeek, 1, 5000, ""}},
+			wantTotal: 5000,
+		},
+		{
+			name: "largest matching long-stay discount",
+			pricing: SpacePricing{
+				Rates:     PricingRates{Day: rate(1000)},
+				Discounts: []LongStayDiscount{{MinDays: 7, Percent: 10}, {MinDays: 3, Percent: 5}},
+			},
+			from:         "2026-01-05 00:00",
+			to:           "2026-01-12 00:00",
+			wantLines:    []line{{PricingUnitDay, 7, 1000, ""}},
+			wantDiscount: 700,
+			wantTotal:    6300,
+		},
+		{
+			name: "stay shorter than the larger discount",
+			pricing: SpacePricing{
+				Rates:     PricingRates{Day: rate(1000)},
+				Discounts: []LongStayDiscount{{MinDays: 7, Percent: 10}, {MinDays: 3, Percent: 5}},
+			},
+			from:         "2026-01-05 00:00",
+			to:           "2026-01-11 00:00",
+			wantLines:    []line{{PricingUnitDay, 6, 1000, ""}},
+			wantDiscount: 300,
+			wantTotal:    5700,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := tt.pricing.Quote(at(tt.from), at(tt.to), time.UTC)
+
+			if len(q.Lines) != len(tt.wantLines) {
+				t.Fatalf("got %d lines %+v, want %+v", len(q.Lines), q.Lines, tt.wantLines)
+			}
+			var subtotal int64
+			for i, w := range tt.wantLines {
+				l := q.Lines[i]
+				got := line{l.Unit, l.Quantity, l.UnitPrice, l.Note}
+				if got != w {
+					t.Errorf("line %d = %+v, want %+v", i, got, w)
+				}
+				if l.Amount != int64(l.Quantity)*l.UnitPrice {
+					t.Errorf("line %d amount = %d, want %d", i, l.Amount, int64(l.Quantity)*l.UnitPrice)
+				}
+				subtotal += l.Amount
+			}
+			if q.Subtotal != subtotal {
+				t.Errorf("subtotal = %d, want %d", q.Subtotal, subtotal)
+			}
+			if q.Discount != tt.wantDiscount || q.Total != tt.wantTotal {
+				t.Errorf("discount, total = %d, %d, want %d, %d", q.Discount, q.Total, tt.wantDiscount, tt.wantTotal)
+			}
+			if !q.Lines[0].DateFrom.Equal(at(tt.from)) || !q.Lines[len(q.Lines)-1].DateTo.Equal(at(tt.to)) {
+				t.Errorf("lines cover %s - %s, want %s - %s",
+					q.Lines[0].DateFrom, q.Lines[len(q.Lines)-1].DateTo, tt.from, tt.to)
+			}
+		})
+	}
+}
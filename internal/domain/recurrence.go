@@ -0,0 +1,218 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxSeriesOccurrences ограничивает размер серии: правило без разумной
+// границы не должно порождать тысячи броней.
+const MaxSeriesOccurrences = 366
+
+type RecurrenceFreq string
+
+const (
+	FreqDaily   RecurrenceFreq = "DAILY"
+	FreqWeekly  RecurrenceFreq = "WEEKLY"
+	FreqMonthly RecurrenceFreq = "MONTHLY"
+)
+
+var (
+	ErrInvalidRRule        = errors.New("invalid recurrence rule")
+	ErrTooManyOccurrences  = fmt.Errorf("recurrence rule produces more than %d occurrences", MaxSeriesOccurrences)
+	ErrUnboundedRecurrence = errors.New("recurrence rule must set COUNT or UNTIL")
+)
+
+var rruleWeekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// RecurrenceRule — поддерживаемое подмножество RRULE из RFC 5545:
+// FREQ=DAILY|WEEKLY|MONTHLY, INTERVAL, COUNT или UNTIL, BYDAY для
+// еженедельных правил. Месячное правило повторяет число месяца первого
+// вхождения и, как в RFC, пропускает месяцы, где такого числа нет.
+type RecurrenceRule struct {
+	Freq     RecurrenceFreq
+	Interval int
+	Count    int
+	Until    *time.Time
+	// untilDate — UNTIL задан датой без времени и включает весь этот день
+	// в часовом поясе помещения.
+	untilDate bool
+	ByDay     []time.Weekday
+}
+
+// ParseRRule разбирает строку вида "FREQ=WEEKLY;BYDAY=TU;COUNT=13";
+// префикс "RRULE:" допускается.
+func ParseRRule(s string) (*RecurrenceRule, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "RRULE:")
+	if s == "" {
+		return nil, ErrInvalidRRule
+	}
+
+	r := &RecurrenceRule{Interval: 1}
+	for _, part := range strings.Split(s, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" {
+			return nil, fmt.Errorf("%w: malformed part %q", ErrInvalidRRule, part)
+		}
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			switch f := RecurrenceFreq(strings.ToUpper(value)); f {
+			case FreqDaily, FreqWeekly, FreqMonthly:
+				r.Freq = f
+			default:
+				return nil, fmt.Errorf("%w: unsupported FREQ %s", ErrInvalidRRule, value)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("%w: INTERVAL must be a positive number", ErrInvalidRRule)
+			}
+			r.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("%w: COUNT must be a positive number", ErrInvalidRRule)
+			}
+			r.Count = n
+		case "UNTIL":
+			if t, err := time.Parse("20060102T150405Z", value); err == nil {
+				r.Until = &t
+				break
+			}
+			t, err := time.Parse("20060102", value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: UNTIL must be YYYYMMDD or YYYYMMDDTHHMMSSZ", ErrInvalidRRule)
+			}
+			r.Until, r.untilDate = &t, true
+		case "BYDAY":
+			for _, code := range strings.Split(strings.ToUpper(value), ",") {
+				wd, ok := rruleWeekdays[code]
+				if !ok {
+					return nil, fmt.Errorf("%w: unsupported BYDAY %s", ErrInvalidRRule, code)
+				}
+				r.ByDay = append(r.ByDay, wd)
+			}
+		default:
+			return nil, fmt.Errorf("%w: unsupported part %s", ErrInvalidRRule, key)
+		}
+	}
+
+	if r.Freq == "" {
+		return nil, fmt.Errorf("%w: FREQ is required", ErrInvalidRRule)
+	}
+	if r.Count > 0 && r.Until != nil {
+		return nil, fmt.Errorf("%w: COUNT and UNTIL are mutually exclusive", ErrInvalidRRule)
+	}
+	if r.Count == 0 && r.Until == nil {
+		return nil, ErrUnboundedRecurrence
+	}
+	if len(r.ByDay) > 0 && r.Freq != FreqWeekly {
+		return nil, fmt.Errorf("%w: BYDAY is supported only for WEEKLY", ErrInvalidRRule)
+	}
+	return r, nil
+}
+
+// Occurrences разворачивает правило от первого вхождения first. Вхождения
+// сохраняют местное время first в поясе loc, поэтому переход на летнее
+// время их не сдвигает. Даты из except (YYYY-MM-DD по местному времени)
+// пропускаются, но, как EXDATE в RFC 5545, учитываются в COUNT.
+func (r *RecurrenceRule) Occurrences(first time.Time, loc *time.Location, except map[string]bool) ([]time.Time, error) {
+	local := first.In(loc)
+	y, mo, d := local.Date()
+	h, mi, sec := local.Clock()
+	at := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, h, mi, sec, 0, loc)
+	}
+
+	var until time.Time
+	if r.Until != nil {
+		until = *r.Until
+		if r.untilDate {
+			uy, um, ud := r.Until.Date()
+			until = time.Date(uy, um, ud+1, 0, 0, 0, 0, loc).Add(-time.Nanosecond)
+		}
+	}
+
+	var (
+		out     []time.Time
+		counted int
+	)
+	// emit возвращает false, когда правило исчерпано
+	emit := func(t time.Time) (bool, error) {
+		if r.Until != nil && t.After(until) {
+			return false, nil
+		}
+		counted++
+		if counted > MaxSeriesOccurrences {
+			return false, ErrTooManyOccurrences
+		}
+		if !except[t.Format("2006-01-02")] {
+			out = append(out, t)
+		}
+		return r.Count == 0 || counted < r.Count, nil
+	}
+
+	switch r.Freq {
+	case FreqDaily:
+		for i := 0; ; i++ {
+			more, err := emit(at(y, mo, d+i*r.Interval))
+			if err != nil || !more {
+				return out, err
+			}
+		}
+
+	case FreqWeekly:
+		days := r.ByDay
+		if len(days) == 0 {
+			days = []time.Weekday{local.Weekday()}
+		}
+		offsets := make([]int, len(days))
+		for i, wd := range days {
+			// неделя начинается с понедельника, как WKST=MO по умолчанию
+			offsets[i] = (int(wd) + 6) % 7
+		}
+		sort.Ints(offsets)
+		monday := d - (int(local.Weekday())+6)%7
+
+		for w := 0; ; w++ {
+			for _, off := range offsets {
+				t := at(y, mo, monday+w*7*r.Interval+off)
+				if t.Before(local) {
+					continue
+				}
+				more, err := emit(t)
+				if err != nil || !more {
+					return out, err
+				}
+			}
+		}
+
+	case FreqMonthly:
+		for i := 0; ; i++ {
+			t := at(y, mo+time.Month(i*r.Interval), d)
+			if t.Day() != d {
+				if r.Until == nil && i > MaxSeriesOccurrences*12 {
+					return out, ErrTooManyOccurrences
+				}
+				continue
+			}
+			more, err := emit(t)
+			if err != nil || !more {
+				return out, err
+			}
+		}
+	}
+	return nil, ErrInvalidRRule
+}
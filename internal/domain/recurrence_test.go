@@ -0,0 +1,134 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestParseRRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    string
+		wantErr error
+	}{
+		{"weekly with count", "FREQ=WEEKLY;BYDAY=TU;COUNT=13", nil},
+		{"prefix and lower case", "RRULE:freq=daily;interval=2;until=20260131", nil},
+		{"until with time", "FREQ=MONTHLY;UNTIL=20261231T235959Z", nil},
+		{"empty", "", ErrInvalidRRule},
+		{"no freq", "COUNT=3", ErrInvalidRRule},
+		{"yearly", "FREQ=YEARLY;COUNT=3", ErrInvalidRRule},
+		{"zero interval", "FREQ=DAILY;INTERVAL=0;COUNT=3", ErrInvalidRRule},
+		{"count and until", "FREQ=DAILY;COUNT=3;UNTIL=20260131", ErrInvalidRRule},
+		{"unbounded", "FREQ=DAILY", ErrUnboundedRecurrence},
+		{"byday on daily", "FREQ=DAILY;BYDAY=MO;COUNT=3", ErrInvalidRRule},
+		{"unknown weekday", "FREQ=WEEKLY;BYDAY=XX;COUNT=3", ErrInvalidRRule},
+		{"malformed part", "FREQ=DAILY;COUNT", ErrInvalidRRule},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseRRule(tt.rule)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseRRule(%q) error = %v, want %v", tt.rule, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRecurrenceRuleOccurrences(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	local := func(s string) time.Time {
+		v, err := time.ParseInLocation("2006-01-02 15:04", s, berlin)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+
+	tests := []struct {
+		name   string
+		rule   string
+		first  string
+		except map[string]bool
+		want   []string
+	}{
+		{
+			name:  "weekly across spring DST keeps local time",
+			rule:  "FREQ=WEEKLY;BYDAY=TU;COUNT=3",
+			first: "2026-03-24 10:00",
+			want:  []string{"2026-03-24 10:00", "2026-03-31 10:00", "2026-04-07 10:00"},
+		},
+		{
+			name:  "weekly across autumn DST with several days",
+			rule:  "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=4",
+			first: "2026-10-21 09:30",
+			want:  []string{"2026-10-21 09:30", "2026-10-26 09:30", "2026-10-28 09:30", "2026-11-02 09:30"},
+		},
+		{
+			name:  "weekly byday before first is skipped in first week",
+			rule:  "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR;COUNT=3",
+			first: "2026-01-07 12:00",
+			want:  []string{"2026-01-09 12:00", "2026-01-19 12:00", "2026-01-23 12:00"},
+		},
+		{
+			name:  "daily with interval",
+			rule:  "FREQ=DAILY;INTERVAL=2;COUNT=3",
+			first: "2026-02-27 08:00",
+			want:  []string{"2026-02-27 08:00", "2026-03-01 08:00", "2026-03-03 08:00"},
+		},
+		{
+			name:  "until date includes the whole day",
+			rule:  "FREQ=DAILY;UNTIL=20260105",
+			first: "2026-01-03 18:00",
+			want:  []string{"2026-01-03 18:00", "2026-01-04 18:00", "2026-01-05 18:00"},
+		},
+		{
+			name:  "monthly skips months without the day",
+			rule:  "FREQ=MONTHLY;COUNT=3",
+			first: "2026-01-31 10:00",
+			want:  []string{"2026-01-31 10:00", "2026-03-31 10:00", "2026-05-31 10:00"},
+		},
+		{
+			name:   "except dates count towards COUNT",
+			rule:   "FREQ=DAILY;COUNT=3",
+			first:  "2026-06-01 10:00",
+			except: map[string]bool{"2026-06-02": true},
+			want:   []string{"2026-06-01 10:00", "2026-06-03 10:00"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := ParseRRule(tt.rule)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := r.Occurrences(local(tt.first).UTC(), berlin, tt.except)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d occurrences %v, want %v", len(got), got, tt.want)
+			}
+			for i, w := range tt.want {
+				if !got[i].Equal(local(w)) {
+					t.Errorf("occurrence %d = %s, want %s", i, got[i].In(berlin).Format("2006-01-02 15:04 MST"), w)
+				}
+			}
+		})
+	}
+}
+
+func TestRecurrenceRuleOccurrencesLimit(t *testing.T) {
+	r, err := ParseRRule("FREQ=DAILY;COUNT=400")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = r.Occurrences(time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC), time.UTC, nil)
+	if !errors.Is(err, ErrTooManyOccurrences) {
+		t.Fatalf("error = %v, want %v", err, ErrTooManyOccurrences)
+	}
+}
@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+type Session struct {
+	ID         int64      `json:"id" db:"id"`
+	UserID     int        `json:"user_id" db:"user_id"`
+	UserAgent  string     `json:"user_agent" db:"user_agent"`
+	IP         string     `json:"ip" db:"ip"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	LastUsedAt time.Time  `json:"last_used_at" db:"last_used_at"`
+	ExpiresAt  time.Time  `json:"expires_at" db:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	Current    bool       `json:"current"`
+}
+
+// SessionMeta — сведения об устройстве, с которого открыта сессия.
+type SessionMeta struct {
+	UserAgent string
+	IP        string
+}
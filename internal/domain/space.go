@@ -2,16 +2,83 @@ package domain
 
 import "time"
 
+const (
+	DefaultSpaceTimezone = "UTC"
+	DefaultSlotMinutes   = 60
+	// DefaultResponseHours — сколько владелец может думать над заявкой,
+	// прежде чем она истечёт.
+	DefaultResponseHours = 48
+	minutesPerDay        = 24 * 60
+)
+
 type Space struct {
-	ID          int       `json:"id" db:"id"`
-	OwnerID     int       `json:"owner_id" db:"owner_id"`
-	Title       string    `json:"title" db:"title"`
-	Description string    `json:"description" db:"description"`
-	AreaM2      float64   `json:"area_m2" db:"area_m2"`
-	Price       int       `json:"price" db:"price"`
-	Phone       string    `json:"phone" db:"phone"`
-	CreatedAt   time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+	ID                 int                 `json:"id" db:"id"`
+	OwnerID            int                 `json:"owner_id" db:"owner_id"`
+	OrganizationID     *int                `json:"organization_id" db:"organization_id"`
+	Title              string              `json:"title" db:"title"`
+	Description        string              `json:"description" db:"description"`
+	AreaM2             float64             `json:"area_m2" db:"area_m2"`
+	Price              int                 `json:"price" db:"price"`
+	Phone              string              `json:"phone" db:"phone"`
+	Timezone           string              `json:"timezone" db:"timezone"`
+	SlotMinutes        int                 `json:"slot_minutes" db:"slot_minutes"`
+	ResponseHours      int                 `json:"response_hours" db:"response_hours"`
+	InstantBooking     InstantBookingRules `json:"instant_booking"`
+	CancellationPolicy CancellationPolicy  `json:"cancellation_policy"`
+	IsActive           bool                `json:"is_active" db:"is_active"`
+	DeletedAt          *time.Time          `json:"-" db:"deleted_at"`
+	UnpublishedAt      *time.Time          `json:"unpublished_at,omitempty" db:"unpublished_at"`
+	UnpublishReason    *string             `json:"unpublish_reason,omitempty" db:"unpublish_reason"`
+	CreatedAt          time.Time           `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time           `json:"updated_at" db:"updated_at"`
+}
+
+// Bookable сообщает, принимает ли помещение новые брони.
+func (s *Space) Bookable() bool {
+	return s.IsActive && s.DeletedAt == nil && s.UnpublishedAt == nil
+}
+
+// InstantBookingRules — условия, при которых заявка на помещение
+// одобряется сразу. Пустые ограничения не проверяются; если хоть одно
+// не выполнено, заявка идёт обычным путём через владельца.
+type InstantBookingRules struct {
+	Enabled            bool `json:"enabled"`
+	MaxDurationMinutes *int `json:"max_duration_minutes" binding:"omitempty,gt=0"`
+	MinLeadMinutes     *int `json:"min_lead_minutes" binding:"omitempty,gte=0"`
+	VerifiedOnly       bool `json:"verified_only"`
+}
+
+// Allows сообщает, подходит ли бронь [from, to), сделанная в момент now
+// арендатором с подтверждённым (или нет) email, под мгновенное одобрение.
+func (r InstantBookingRules) Allows(from, to, now time.Time, verified bool) bool {
+	if !r.Enabled {
+		return false
+	}
+	if r.VerifiedOnly && !verified {
+		return false
+	}
+	if r.MaxDurationMinutes != nil && to.Sub(from) > time.Duration(*r.MaxDurationMinutes)*time.Minute {
+		return false
+	}
+	if r.MinLeadMinutes != nil && from.Sub(now) < time.Duration(*r.MinLeadMinutes)*time.Minute {
+		return false
+	}
+	return true
+}
+
+// Location возвращает часовой пояс помещения, в котором трактуются
+// даты без смещения и проверяется выравнивание по слотам.
+func (s *Space) Location() (*time.Location, error) {
+	if s.Timezone == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(s.Timezone)
+}
+
+// ValidSlotMinutes сообщает, делит ли слот сутки нацело: только такие
+// слоты позволяют посуточной брони оставаться выровненной.
+func ValidSlotMinutes(m int) bool {
+	return m > 0 && minutesPerDay%m == 0
 }
 
 type CreateSpaceRequest struct {
@@ -20,4 +87,23 @@ type CreateSpaceRequest struct {
 	AreaM2      float64 `json:"area_m2" binding:"required,gt=0"`
 	Price       int     `json:"price" binding:"required,gt=0"`
 	Phone       string  `json:"phone" binding:"required"`
+	Timezone    string  `json:"timezone"`
+	SlotMinutes int     `json:"slot_minutes" binding:"omitempty,gt=0"`
+	// ResponseHours — срок ответа на заявку; по умолчанию DefaultResponseHours.
+	ResponseHours int `json:"response_hours" binding:"omitempty,gt=0"`
+	// OrganizationID сразу отдаёт помещение под управление организации,
+	// в которой создатель — администратор.
+	OrganizationID *int `json:"organization_id"`
+}
+
+// UpdateSpaceRequest — частичное обновление: nil означает "не менять".
+type UpdateSpaceRequest struct {
+	Title         *string  `json:"title" binding:"omitempty,min=1"`
+	Description   *string  `json:"description"`
+	AreaM2        *float64 `json:"area_m2" binding:"omitempty,gt=0"`
+	Price         *int     `json:"price" binding:"omitempty,gt=0"`
+	Phone         *string  `json:"phone" binding:"omitempty,min=1"`
+	Timezone      *string  `json:"timezone"`
+	SlotMinutes   *int     `json:"slot_minutes" binding:"omitempty,gt=0"`
+	ResponseHours *int     `json:"response_hours" binding:"omitempty,gt=0"`
 }
@@ -9,27 +9,62 @@ type UserRole string
 const (
 	RoleOwner  UserRole = "owner"
 	RoleTenant UserRole = "tenant"
+	// RoleAdmin нельзя получить при регистрации, только через cmd/promote-admin.
+	RoleAdmin UserRole = "admin"
 )
 
+const (
+	LocaleRU      = "ru"
+	LocaleEN      = "en"
+	DefaultLocale = LocaleRU
+)
+
+func ValidLocale(l string) bool {
+	return l == LocaleRU || l == LocaleEN
+}
+
 type User struct {
-	ID           int       `json:"id" db:"id"`
-	Email        string    `json:"email" db:"email"`
-	PasswordHash string    `json:"-" db:"password_hash"`
-	Role         UserRole  `json:"role" db:"role"`
-	FirstName    string    `json:"first_name" db:"first_name"`
-	LastName     string    `json:"last_name" db:"last_name"`
-	Phone        string    `json:"phone" db:"phone"`
-	CreatedAt    time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+	ID               int        `json:"id" db:"id"`
+	Email            string     `json:"email" db:"email"`
+	PasswordHash     string     `json:"-" db:"password_hash"`
+	Roles            []UserRole `json:"roles" db:"roles"`
+	FirstName        string     `json:"first_name" db:"first_name"`
+	LastName         string     `json:"last_name" db:"last_name"`
+	Phone            string     `json:"phone" db:"phone"`
+	Locale           string     `json:"locale" db:"locale"`
+	TokenVersion     int        `json:"-" db:"token_version"`
+	EmailVerifiedAt  *time.Time `json:"email_verified_at" db:"email_verified_at"`
+	SuspendedAt      *time.Time `json:"suspended_at,omitempty" db:"suspended_at"`
+	SuspensionReason *string    `json:"suspension_reason,omitempty" db:"suspension_reason"`
+	CreatedAt        time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// HasRole сообщает, входит ли role в набор ролей пользователя.
+func (u *User) HasRole(role UserRole) bool {
+	return ContainsRole(u.Roles, role)
+}
+
+func ContainsRole(roles []UserRole, role UserRole) bool {
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
 }
 
+// RegisterRequest принимает одну роль в role (как раньше) или несколько
+// в roles; нужна хотя бы одна.
 type RegisterRequest struct {
-	Email     string   `json:"email" binding:"required,email"`
-	Password  string   `json:"password" binding:"required,min=6"`
-	Role      UserRole `json:"role" binding:"required,oneof=owner tenant"`
-	FirstName string   `json:"first_name" binding:"required"`
-	LastName  string   `json:"last_name" binding:"required"`
-	Phone     string   `json:"phone"`
+	Email     string     `json:"email" binding:"required,email"`
+	Password  string     `json:"password" binding:"required,min=6"`
+	Role      UserRole   `json:"role" binding:"omitempty,oneof=owner tenant"`
+	Roles     []UserRole `json:"roles" binding:"omitempty,dive,oneof=owner tenant"`
+	FirstName string     `json:"first_name" binding:"required"`
+	LastName  string     `json:"last_name" binding:"required"`
+	Phone     string     `json:"phone"`
+	Locale    string     `json:"locale" binding:"omitempty,oneof=ru en"`
 }
 
 type LoginRequest struct {
@@ -43,6 +78,38 @@ type AuthResponse struct {
 	User         User   `json:"user"`
 }
 
+// AccessTokenResponse возвращается, когда меняются права внутри текущей
+// сессии: refresh-токен остаётся прежним.
+type AccessTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	User        User   `json:"user"`
+}
+
 type RefreshTokenRequest struct {
 	RefreshToken string `json:"refresh_token" binding:"required"`
 }
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required,min=6"`
+}
+
+type UserTokenPurpose string
+
+const (
+	UserTokenPasswordReset     UserTokenPurpose = "password_reset"
+	UserTokenEmailVerification UserTokenPurpose = "email_verification"
+)
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=6"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
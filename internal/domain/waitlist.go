@@ -0,0 +1,42 @@
+package domain
+
+import "time"
+
+type WaitlistStatus string
+
+const (
+	WaitlistStatusWaiting   WaitlistStatus = "waiting"
+	WaitlistStatusNotified  WaitlistStatus = "notified"
+	WaitlistStatusBooked    WaitlistStatus = "booked"
+	WaitlistStatusCancelled WaitlistStatus = "cancelled"
+)
+
+// WaitlistEntry — место арендатора в очереди на занятые даты помещения.
+type WaitlistEntry struct {
+	ID         int            `json:"id"`
+	SpaceID    int            `json:"space_id"`
+	TenantID   int            `json:"tenant_id"`
+	DateFrom   time.Time      `json:"date_from"`
+	DateTo     time.Time      `json:"date_to"`
+	AutoBook   bool           `json:"auto_book"`
+	Status     WaitlistStatus `json:"status"`
+	BookingID  *int           `json:"booking_id,omitempty"`
+	NotifiedAt *time.Time     `json:"notified_at,omitempty"`
+	CreatedAt  time.Time      `json:"created_at"`
+}
+
+// JoinWaitlistRequest — даты в тех же форматах, что и при бронировании.
+type JoinWaitlistRequest struct {
+	DateFrom string `json:"date_from" binding:"required"`
+	DateTo   string `json:"date_to" binding:"required"`
+	AutoBook bool   `json:"auto_book"`
+}
+
+// WaitlistDemand — сколько арендаторов ждут освобождения помещения.
+type WaitlistDemand struct {
+	SpaceID      int        `json:"space_id"`
+	SpaceTitle   string     `json:"space_title"`
+	Waiting      int        `json:"waiting"`
+	EarliestFrom *time.Time `json:"earliest_from,omitempty"`
+	LatestTo     *time.Time `json:"latest_to,omitempty"`
+}
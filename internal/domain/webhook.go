@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type WebhookEndpoint struct {
+	ID         int                `json:"id" db:"id"`
+	UserID     int                `json:"user_id" db:"user_id"`
+	URL        string             `json:"url" db:"url"`
+	Secret     string             `json:"secret,omitempty" db:"secret"`
+	EventTypes []BookingEventType `json:"event_types" db:"event_types"`
+	IsActive   bool               `json:"is_active" db:"is_active"`
+	CreatedAt  time.Time          `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time          `json:"updated_at" db:"updated_at"`
+}
+
+// Accepts сообщает, подписан ли endpoint на событие: пустой список — на все.
+func (e *WebhookEndpoint) Accepts(t BookingEventType) bool {
+	if len(e.EventTypes) == 0 {
+		return true
+	}
+	for _, et := range e.EventTypes {
+		if et == t {
+			return true
+		}
+	}
+	return false
+}
+
+type CreateWebhookRequest struct {
+	URL        string             `json:"url" binding:"required,url"`
+	Secret     string             `json:"secret" binding:"omitempty,min=16"`
+	EventTypes []BookingEventType `json:"event_types" binding:"dive,oneof=created approved confirmed rejected cancelled expired modified checked_in completed no_show"`
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliveryDelivered WebhookDeliveryStatus = "delivered"
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed"
+)
+
+type WebhookDelivery struct {
+	ID             int64                 `json:"id" db:"id"`
+	EndpointID     int                   `json:"endpoint_id" db:"endpoint_id"`
+	EventID        int64                 `json:"event_id" db:"event_id"`
+	EventType      BookingEventType      `json:"event_type" db:"event_type"`
+	Payload        json.RawMessage       `json:"payload" db:"payload"`
+	Status         WebhookDeliveryStatus `json:"status" db:"status"`
+	Attempts       int                   `json:"attempts" db:"attempts"`
+	NextAttemptAt  time.Time             `json:"next_attempt_at" db:"next_attempt_at"`
+	ResponseStatus *int                  `json:"response_status,omitempty" db:"response_status"`
+	LastError      *string               `json:"last_error,omitempty" db:"last_error"`
+	CreatedAt      time.Time             `json:"created_at" db:"created_at"`
+	DeliveredAt    *time.Time            `json:"delivered_at,omitempty" db:"delivered_at"`
+}
+
+type WebhookDeliveryAttempt struct {
+	ID             int64     `json:"id" db:"id"`
+	DeliveryID     int64     `json:"delivery_id" db:"delivery_id"`
+	ResponseStatus *int      `json:"response_status,omitempty" db:"response_status"`
+	Error          *string   `json:"error,omitempty" db:"error"`
+	DurationMs     int       `json:"duration_ms" db:"duration_ms"`
+	AttemptedAt    time.Time `json:"attempted_at" db:"attempted_at"`
+}
+
+// WebhookPayload — тело запроса, которое получает endpoint.
+type WebhookPayload struct {
+	ID        int64            `json:"id"`
+	Type      BookingEventType `json:"type"`
+	CreatedAt time.Time        `json:"created_at"`
+	Data      BookingEvent     `json:"data"`
+}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"SpaceBookProject/internal/domain"
+	"SpaceBookProject/internal/repository"
+	"SpaceBookProject/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AdminHandler struct {
+	svc *services.AdminService
+}
+
+func NewAdminHandler(svc *services.AdminService) *AdminHandler {
+	return &AdminHandler{svc: svc}
+}
+
+func (h *AdminHandler) ListUsers(c *gin.Context) {
+	var f repository.UserFilter
+
+	if q := c.Query("q"); q != "" {
+		f.Query = &q
+	}
+	if role := c.Query("role"); role != "" {
+		r := domain.UserRole(role)
+		f.Role = &r
+	}
+	if v := c.Query("suspended"); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			f.Suspended = &b
+		}
+	}
+	f.Limit, _ = strconv.Atoi(c.Query("limit"))
+	f.Offset, _ = strconv.Atoi(c.Query("offset"))
+
+	users, err := h.svc.ListUsers(f)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load users"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": users})
+}
+
+func (h *AdminHandler) SuspendUser(c *gin.Context) {
+	h.moderate(c, h.svc.SuspendUser)
+}
+
+func (h *AdminHandler) ReinstateUser(c *gin.Context) {
+	h.moderate(c, h.svc.ReinstateUser)
+}
+
+func (h *AdminHandler) CancelBooking(c *gin.Context) {
+	h.moderate(c, h.svc.ForceCancelBooking)
+}
+
+func (h *AdminHandler) UnpublishSpace(c *gin.Context) {
+	h.moderate(c, h.svc.UnpublishSpace)
+}
+
+func (h *AdminHandler) RepublishSpace(c *gin.Context) {
+	h.moderate(c, h.svc.RepublishSpace)
+}
+
+func (h *AdminHandler) ListAudit(c *gin.Context) {
+	var f repository.AuditFilter
+
+	if v := c.Query("admin_id"); v != "" {
+		if id, err := strconv.Atoi(v); err == nil {
+			f.AdminID = &id
+		}
+	}
+	if v := c.Query("target_type"); v != "" {
+		t := domain.AuditTargetType(v)
+		f.TargetType = &t
+	}
+	if v := c.Query("target_id"); v != "" {
+		if id, err := strconv.ParseInt(v, 10, 64); err == nil {
+			f.TargetID = &id
+		}
+	}
+	f.Limit, _ = strconv.Atoi(c.Query("limit"))
+	f.Offset, _ = strconv.Atoi(c.Query("offset"))
+
+	entries, err := h.svc.ListAudit(f)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load audit log"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": entries})
+}
+
+// moderate разбирает id цели и причину и выполняет действие от имени
+// текущего администратора.
+func (h *AdminHandler) moderate(c *gin.Context, action func(adminID, targetID int, reason string) (*domain.AuditEntry, error)) {
+	targetID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req domain.ModerationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	rawID, ok := c.Get("userID")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	entry, err := action(rawID.(int), targetID, req.Reason)
+	if err != nil {
+		writeAdminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+func writeAdminError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repository.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+	case errors.Is(err, repository.ErrBookingNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "booking not found"})
+	case errors.Is(err, repository.ErrSpaceNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "space not found"})
+	case errors.Is(err, services.ErrCannotModerateAdmin):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrWrongStatus):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrAlreadySuspended),
+		errors.Is(err, services.ErrNotSuspended),
+		errors.Is(err, services.ErrAlreadyUnpublished),
+		errors.Is(err, services.ErrNotUnpublished):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "moderation action failed"})
+	}
+}
@@ -1,10 +1,14 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 	"strings"
 
+	"SpaceBookProject/internal/auth"
 	"SpaceBookProject/internal/domain"
+	"SpaceBookProject/internal/repository"
 	"SpaceBookProject/internal/services"
 
 	"github.com/gin-gonic/gin"
@@ -30,8 +34,14 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
-	response, err := h.authService.Register(&req)
+	response, err := h.authService.Register(&req, sessionMeta(c))
 	if err != nil {
+		if errors.Is(err, services.ErrRoleRequired) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error: "Either role or roles must be provided",
+			})
+			return
+		}
 		if err.Error() == "user already exists" {
 			c.JSON(http.StatusConflict, ErrorResponse{
 				Error: "User with this email already exists",
@@ -57,7 +67,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	response, err := h.authService.Login(&req)
+	response, err := h.authService.Login(&req, sessionMeta(c))
 	if err != nil {
 		if err == services.ErrInvalidCredentials {
 			c.JSON(http.StatusUnauthorized, ErrorResponse{
@@ -65,6 +75,12 @@ func (h *AuthHandler) Login(c *gin.Context) {
 			})
 			return
 		}
+		if err == services.ErrUserSuspended {
+			c.JSON(http.StatusForbidden, ErrorResponse{
+				Error: "Account is suspended",
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
 			Error: "Failed to login",
 		})
@@ -84,11 +100,24 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 		return
 	}
 
-	response, err := h.authService.RefreshToken(req.RefreshToken)
+	response, err := h.authService.RefreshToken(req.RefreshToken, sessionMeta(c))
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, ErrorResponse{
-			Error: "Invalid or expired refresh token",
-		})
+		switch {
+		case errors.Is(err, repository.ErrRefreshTokenReused):
+			c.JSON(http.StatusUnauthorized, ErrorResponse{
+				Error: "Refresh token has already been used, session revoked",
+			})
+		case errors.Is(err, repository.ErrSessionNotFound),
+			errors.Is(err, repository.ErrSessionRevoked),
+			errors.Is(err, repository.ErrSessionExpired):
+			c.JSON(http.StatusUnauthorized, ErrorResponse{
+				Error: "Invalid or expired refresh token",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error: "Failed to refresh token",
+			})
+		}
 		return
 	}
 
@@ -96,7 +125,7 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 }
 
 func (h *AuthHandler) Logout(c *gin.Context) {
-	userID, exists := c.Get("userID")
+	rawClaims, exists := c.Get("claims")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, ErrorResponse{
 			Error: "User not authenticated",
@@ -104,7 +133,7 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 		return
 	}
 
-	if err := h.authService.Logout(userID.(int)); err != nil {
+	if err := h.authService.Logout(rawClaims.(*auth.TokenClaims)); err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
 			Error: "Failed to logout",
 		})
@@ -116,6 +145,184 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 	})
 }
 
+func (h *AuthHandler) LogoutAll(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error: "User not authenticated",
+		})
+		return
+	}
+
+	if err := h.authService.LogoutAll(userID.(int)); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: "Failed to logout",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{
+		Message: "Successfully logged out from all devices",
+	})
+}
+
+func (h *AuthHandler) ChangePassword(c *gin.Context) {
+	var req domain.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "Invalid request format: " + err.Error(),
+		})
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error: "User not authenticated",
+		})
+		return
+	}
+
+	if err := h.authService.ChangePassword(userID.(int), &req); err != nil {
+		if errors.Is(err, services.ErrInvalidCredentials) {
+			c.JSON(http.StatusUnauthorized, ErrorResponse{
+				Error: "Current password is incorrect",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: "Failed to change password",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{
+		Message: "Password changed, please log in again",
+	})
+}
+
+func (h *AuthHandler) ForgotPassword(c *gin.Context) {
+	var req domain.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "Invalid request format: " + err.Error(),
+		})
+		return
+	}
+
+	if err := h.authService.ForgotPassword(c.Request.Context(), &req); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: "Failed to process request",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{
+		Message: "If this email is registered, a reset link has been sent",
+	})
+}
+
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var req domain.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "Invalid request format: " + err.Error(),
+		})
+		return
+	}
+
+	if err := h.authService.ResetPassword(&req); err != nil {
+		if errors.Is(err, repository.ErrUserTokenInvalid) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error: "Invalid or expired reset token",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: "Failed to reset password",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{
+		Message: "Password has been reset, please log in",
+	})
+}
+
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	var req domain.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "Invalid request format: " + err.Error(),
+		})
+		return
+	}
+
+	if err := h.authService.VerifyEmail(&req); err != nil {
+		if errors.Is(err, repository.ErrUserTokenInvalid) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error: "Invalid or expired verification token",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: "Failed to verify email",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{
+		Message: "Email verified",
+	})
+}
+
+func (h *AuthHandler) ResendEmailVerification(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error: "User not authenticated",
+		})
+		return
+	}
+
+	if err := h.authService.ResendEmailVerification(c.Request.Context(), userID.(int)); err != nil {
+		if errors.Is(err, services.ErrEmailAlreadyVerified) {
+			c.JSON(http.StatusConflict, ErrorResponse{
+				Error: "Email is already verified",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: "Failed to send verification email",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{
+		Message: "Verification email sent",
+	})
+}
+
+func (h *AuthHandler) BecomeOwner(c *gin.Context) {
+	rawClaims, exists := c.Get("claims")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error: "User not authenticated",
+		})
+		return
+	}
+
+	response, err := h.authService.BecomeOwner(rawClaims.(*auth.TokenClaims))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: "Failed to add owner role",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
 func (h *AuthHandler) GetMe(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
@@ -136,6 +343,68 @@ func (h *AuthHandler) GetMe(c *gin.Context) {
 	c.JSON(http.StatusOK, user)
 }
 
+func (h *AuthHandler) ListSessions(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error: "User not authenticated",
+		})
+		return
+	}
+
+	sessions, err := h.authService.ListSessions(userID.(int), c.GetInt64("sessionID"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: "Failed to load sessions",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": sessions})
+}
+
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error: "User not authenticated",
+		})
+		return
+	}
+
+	sessionID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "Invalid session id",
+		})
+		return
+	}
+
+	if err := h.authService.RevokeSession(userID.(int), sessionID); err != nil {
+		if errors.Is(err, repository.ErrSessionNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error: "Session not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: "Failed to revoke session",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{
+		Message: "Session revoked",
+	})
+}
+
+func sessionMeta(c *gin.Context) domain.SessionMeta {
+	return domain.SessionMeta{
+		UserAgent: c.Request.UserAgent(),
+		IP:        c.ClientIP(),
+	}
+}
+
 func ExtractToken(c *gin.Context) string {
 	bearerToken := c.GetHeader("Authorization")
 	if len(strings.Split(bearerToken, " ")) == 2 {
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"SpaceBookProject/internal/domain"
+	"SpaceBookProject/internal/repository"
+	"SpaceBookProject/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AvailabilityHandler struct {
+	svc *services.AvailabilityService
+}
+
+func NewAvailabilityHandler(svc *services.AvailabilityService) *AvailabilityHandler {
+	return &AvailabilityHandler{svc: svc}
+}
+
+func (h *AvailabilityHandler) GetAvailability(c *gin.Context) {
+	spaceID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid space id"})
+		return
+	}
+	includePending, _ := strconv.ParseBool(c.Query("include_pending"))
+
+	availability, err := h.svc.GetAvailability(spaceID, c.Query("from"), c.Query("to"), includePending)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrSpaceNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "space not found"})
+		case errors.Is(err, services.ErrInvalidBookingTime),
+			errors.Is(err, services.ErrInvalidRange),
+			errors.Is(err, services.ErrRangeTooLong):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load availability"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, availability)
+}
+
+func (h *AvailabilityHandler) ListBlackouts(c *gin.Context) {
+	spaceID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid space id"})
+		return
+	}
+
+	uidVal, ok := c.Get("userID")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	ownerID := uidVal.(int)
+
+	items, err := h.svc.ListBlackouts(ownerID, spaceID)
+	if err != nil {
+		writeBlackoutError(c, err, "failed to load blackouts")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+func (h *AvailabilityHandler) CreateBlackout(c *gin.Context) {
+	spaceID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid space id"})
+		return
+	}
+
+	var req domain.CreateBlackoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+		return
+	}
+
+	uidVal, ok := c.Get("userID")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	ownerID := uidVal.(int)
+
+	blackout, err := h.svc.CreateBlackout(ownerID, spaceID, &req)
+	if err != nil {
+		writeBlackoutError(c, err, "failed to create blackout")
+		return
+	}
+
+	c.JSON(http.StatusCreated, blackout)
+}
+
+func (h *AvailabilityHandler) DeleteBlackout(c *gin.Context) {
+	spaceID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid space id"})
+		return
+	}
+	blackoutID, err := strconv.Atoi(c.Param("blackoutId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid blackout id"})
+		return
+	}
+
+	uidVal, ok := c.Get("userID")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	ownerID := uidVal.(int)
+
+	if err := h.svc.DeleteBlackout(ownerID, spaceID, blackoutID); err != nil {
+		writeBlackoutError(c, err, "failed to delete blackout")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "blackout deleted"})
+}
+
+func writeBlackoutError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, repository.ErrSpaceNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "space not found"})
+	case errors.Is(err, repository.ErrBlackoutNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "blackout not found"})
+	case errors.Is(err, services.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": "this space does not belong to you"})
+	case errors.Is(err, services.ErrInvalidBookingTime),
+		errors.Is(err, services.ErrInvalidRange),
+		errors.Is(err, services.ErrBlackoutPast):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -2,8 +2,10 @@ package handlers
 
 import (
 	"SpaceBookProject/internal/domain"
+	"SpaceBookProject/internal/repository"
 	"SpaceBookProject/internal/services"
 	"errors"
+	"io"
 	"net/http"
 	"strconv"
 
@@ -34,6 +36,10 @@ func (h *BookingHandler) CreateBooking(c *gin.Context) {
 
 	booking, err := h.svc.CreateBooking(tenantID, &req)
 	if err != nil {
+		if errors.Is(err, services.ErrEmailNotVerified) || errors.Is(err, services.ErrNotCompanyMember) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -76,10 +82,12 @@ func (h *BookingHandler) OwnerBookings(c *gin.Context) {
 }
 
 func (h *BookingHandler) CancelBooking(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.Atoi(idStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid booking id"})
+	id, ok := parseIDParam(c, "id", "invalid booking id")
+	if !ok {
+		return
+	}
+	reason, ok := bindReason(c)
+	if !ok {
 		return
 	}
 
@@ -90,31 +98,125 @@ func (h *BookingHandler) CancelBooking(c *gin.Context) {
 	}
 	tenantID := uidVal.(int)
 
-	err = h.svc.CancelBooking(id, tenantID)
+	// scope=following отменяет это вхождение серии и все последующие
+	var cancelled []domain.Booking
+	var cancellation *domain.Cancellation
+	var err error
+	if c.Query("scope") == "following" {
+		cancelled, err = h.svc.CancelFollowing(id, tenantID, reason)
+	} else {
+		cancellation, err = h.svc.CancelBooking(id, tenantID, reason)
+	}
 	if err != nil {
 		switch {
 		case errors.Is(err, services.ErrForbidden):
 			c.JSON(http.StatusForbidden, gin.H{"error": "you can cancel only your own booking"})
+		case errors.Is(err, repository.ErrBookingNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "booking not found"})
+		case errors.Is(err, services.ErrNotInSeries), errors.Is(err, services.ErrNothingToChange):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		case errors.Is(err, services.ErrAlreadyStarted):
 			c.JSON(http.StatusBadRequest, gin.H{"error": "booking already started"})
 		case errors.Is(err, services.ErrWrongStatus):
-			c.JSON(http.StatusBadRequest, gin.H{"error": "booking cannot be cancelled in this status"})
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		default:
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to cancel booking"})
 		}
 		return
 	}
 
+	if cancelled != nil {
+		c.JSON(http.StatusOK, gin.H{"message": "bookings cancelled", "items": cancelled})
+		return
+	}
 	c.JSON(http.StatusOK, gin.H{
-		"message": "booking cancelled",
+		"message":      "booking cancelled",
+		"cancellation": cancellation,
 	})
 }
 
-func (h *BookingHandler) ApproveBooking(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.Atoi(idStr)
+// CancellationPreview показывает арендатору возврат до отмены брони.
+func (h *BookingHandler) CancellationPreview(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", "invalid booking id")
+	if !ok {
+		return
+	}
+
+	preview, err := h.svc.CancellationPreview(c.GetInt("userID"), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": "you can cancel only your own booking"})
+		case errors.Is(err, repository.ErrBookingNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "booking not found"})
+		case errors.Is(err, services.ErrAlreadyStarted):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "booking already started"})
+		case errors.Is(err, services.ErrWrongStatus):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to calculate refund"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, preview)
+}
+
+func (h *BookingHandler) GetCancellation(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", "invalid booking id")
+	if !ok {
+		return
+	}
+
+	cancellation, err := h.svc.GetCancellation(c.GetInt("userID"), id)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid booking id"})
+		switch {
+		case errors.Is(err, services.ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": "you are not allowed to see this booking"})
+		case errors.Is(err, repository.ErrBookingNotFound), errors.Is(err, repository.ErrCancellationNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load cancellation"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, cancellation)
+}
+
+func (h *BookingHandler) ApproveBooking(c *gin.Context) {
+	h.ownerAction(c, h.svc.ApproveBooking, "booking approved")
+}
+
+func (h *BookingHandler) RejectBooking(c *gin.Context) {
+	h.ownerAction(c, h.svc.RejectBooking, "booking rejected")
+}
+
+func (h *BookingHandler) CheckInBooking(c *gin.Context) {
+	h.ownerAction(c, h.svc.CheckInBooking, "tenant checked in")
+}
+
+func (h *BookingHandler) CompleteBooking(c *gin.Context) {
+	h.ownerAction(c, h.svc.CompleteBooking, "booking completed")
+}
+
+func (h *BookingHandler) NoShowBooking(c *gin.Context) {
+	h.ownerAction(c, h.svc.MarkNoShow, "booking marked as no-show")
+}
+
+// OwnerCancelBooking — отмена владельцем; арендатору возвращается вся сумма.
+func (h *BookingHandler) OwnerCancelBooking(c *gin.Context) {
+	h.ownerAction(c, h.svc.OwnerCancelBooking, "booking cancelled")
+}
+
+// ownerAction — общий обработчик смены статуса со стороны помещения.
+func (h *BookingHandler) ownerAction(c *gin.Context, act func(id, userID int, reason string) error, message string) {
+	id, ok := parseIDParam(c, "id", "invalid booking id")
+	if !ok {
+		return
+	}
+	reason, ok := bindReason(c)
+	if !ok {
 		return
 	}
 
@@ -125,55 +227,320 @@ func (h *BookingHandler) ApproveBooking(c *gin.Context) {
 	}
 	ownerID := uidVal.(int)
 
-	err = h.svc.ApproveBooking(id, ownerID)
-	if err != nil {
+	if err := act(id, ownerID, reason); err != nil {
 		switch {
 		case errors.Is(err, services.ErrForbidden):
-			c.JSON(http.StatusForbidden, gin.H{"error": "this booking does not belong to your spaces"})
-		case errors.Is(err, services.ErrWrongStatus):
-			c.JSON(http.StatusBadRequest, gin.H{"error": "only pending bookings can be approved"})
+			c.JSON(http.StatusForbidden, gin.H{"error": "you are not allowed to decide on bookings for this space"})
+		case errors.Is(err, repository.ErrBookingNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "booking not found"})
+		case errors.Is(err, services.ErrWrongStatus),
+			errors.Is(err, services.ErrNotStarted),
+			errors.Is(err, services.ErrAlreadyStarted),
+			errors.Is(err, services.ErrAlreadyEnded),
+			errors.Is(err, services.ErrResponseOverdue):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		case errors.Is(err, services.ErrOverlappingBooking):
 			c.JSON(http.StatusConflict, gin.H{"error": "booking overlaps with existing approved booking"})
 		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to approve booking"})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update booking"})
 		}
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "booking approved",
+		"message": message,
 	})
 }
 
-func (h *BookingHandler) RejectBooking(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.Atoi(idStr)
+// bindReason читает необязательную причину смены статуса; пустое тело допустимо.
+func bindReason(c *gin.Context) (string, bool) {
+	var req domain.StatusChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+		return "", false
+	}
+	return req.Reason, true
+}
+
+// ModifyBooking меняет даты заявки сразу, а для одобренной брони создаёт
+// запрос на перенос и отвечает 202.
+func (h *BookingHandler) ModifyBooking(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", "invalid booking id")
+	if !ok {
+		return
+	}
+
+	var req domain.ModifyBookingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+		return
+	}
+
+	booking, change, err := h.svc.ModifyBooking(c.GetInt("userID"), id, &req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid booking id"})
+		switch {
+		case errors.Is(err, services.ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": "you can modify only your own booking"})
+		case errors.Is(err, repository.ErrBookingNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "booking not found"})
+		case errors.Is(err, services.ErrOverlappingBooking), errors.Is(err, services.ErrAlreadyBooked):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
 		return
 	}
 
-	uidVal, ok := c.Get("userID")
+	if change != nil {
+		c.JSON(http.StatusAccepted, gin.H{"booking": booking, "change_request": change})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"booking": booking})
+}
+
+func (h *BookingHandler) BookingChanges(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", "invalid booking id")
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 		return
 	}
-	ownerID := uidVal.(int)
 
-	err = h.svc.RejectBooking(id, ownerID)
+	items, err := h.svc.ListBookingChanges(c.GetInt("userID"), id)
 	if err != nil {
 		switch {
 		case errors.Is(err, services.ErrForbidden):
-			c.JSON(http.StatusForbidden, gin.H{"error": "this booking does not belong to your spaces"})
-		case errors.Is(err, services.ErrWrongStatus):
-			c.JSON(http.StatusBadRequest, gin.H{"error": "only pending bookings can be rejected"})
+			c.JSON(http.StatusForbidden, gin.H{"error": "you are not allowed to see this booking"})
+		case errors.Is(err, repository.ErrBookingNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "booking not found"})
 		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to reject booking"})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load change requests"})
 		}
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message": "booking rejected",
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+func (h *BookingHandler) ApproveBookingChange(c *gin.Context) {
+	h.changeDecision(c, func(userID, bookingID int, changeID int64) (any, error) {
+		return h.svc.ApproveBookingChange(userID, bookingID, changeID)
 	})
 }
+
+func (h *BookingHandler) RejectBookingChange(c *gin.Context) {
+	h.changeDecision(c, func(userID, bookingID int, changeID int64) (any, error) {
+		return h.svc.RejectBookingChange(userID, bookingID, changeID)
+	})
+}
+
+func (h *BookingHandler) changeDecision(c *gin.Context, decide func(userID, bookingID int, changeID int64) (any, error)) {
+	bookingID, ok := parseIDParam(c, "id", "invalid booking id")
+	if !ok {
+		return
+	}
+	changeID, err := strconv.ParseInt(c.Param("changeId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid change request id"})
+		return
+	}
+
+	result, err := decide(c.GetInt("userID"), bookingID, changeID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": "you are not allowed to decide on bookings for this space"})
+		case errors.Is(err, repository.ErrBookingNotFound), errors.Is(err, repository.ErrChangeRequestNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrOverlappingBooking), errors.Is(err, repository.ErrChangeRequestDecided):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrNotModifiable),
+			errors.Is(err, services.ErrAlreadyStarted),
+			errors.Is(err, services.ErrSpaceUnavailable):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to decide on change request"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// CreateSeries создаёт серию броней. Если часть вхождений занята, отвечает
+// 409 со списком конфликтов, а с skip_conflicts — создаёт остальные.
+func (h *BookingHandler) CreateSeries(c *gin.Context) {
+	var req domain.CreateSeriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+		return
+	}
+
+	series, skipped, err := h.svc.CreateSeries(c.GetInt("userID"), &req)
+	if err != nil {
+		var conflictErr *services.SeriesConflictError
+		switch {
+		case errors.As(err, &conflictErr):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "conflicts": conflictErr.Conflicts})
+		case errors.Is(err, services.ErrEmailNotVerified), errors.Is(err, services.ErrNotCompanyMember):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, repository.ErrSpaceNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "space not found"})
+		case errors.Is(err, services.ErrEmptySeries):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "conflicts": skipped})
+		case errors.Is(err, services.ErrOverlappingBooking):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"series": series, "skipped": skipped})
+}
+
+func (h *BookingHandler) GetSeries(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", "invalid series id")
+	if !ok {
+		return
+	}
+
+	series, err := h.svc.GetSeries(c.GetInt("userID"), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": "you are not allowed to see this series"})
+		case errors.Is(err, repository.ErrSeriesNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "booking series not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load booking series"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, series)
+}
+
+func (h *BookingHandler) ApproveSeries(c *gin.Context) {
+	h.seriesDecision(c, h.svc.ApproveSeries, "series approved")
+}
+
+func (h *BookingHandler) RejectSeries(c *gin.Context) {
+	h.seriesDecision(c, h.svc.RejectSeries, "series rejected")
+}
+
+func (h *BookingHandler) seriesDecision(c *gin.Context, decide func(userID, seriesID int, reason string) ([]domain.Booking, error), message string) {
+	id, ok := parseIDParam(c, "id", "invalid series id")
+	if !ok {
+		return
+	}
+	reason, ok := bindReason(c)
+	if !ok {
+		return
+	}
+
+	items, err := decide(c.GetInt("userID"), id, reason)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": "you are not allowed to decide on bookings for this space"})
+		case errors.Is(err, repository.ErrSeriesNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "booking series not found"})
+		case errors.Is(err, services.ErrOverlappingBooking):
+			c.JSON(http.StatusConflict, gin.H{"error": "an occurrence overlaps with existing approved booking"})
+		case errors.Is(err, services.ErrNothingToChange),
+			errors.Is(err, services.ErrResponseOverdue),
+			errors.Is(err, services.ErrWrongStatus):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update booking series"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": message, "items": items})
+}
+
+func (h *BookingHandler) BookingHistory(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", "invalid booking id")
+	if !ok {
+		return
+	}
+
+	items, err := h.svc.BookingHistory(c.GetInt("userID"), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": "you are not allowed to see this booking"})
+		case errors.Is(err, repository.ErrBookingNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "booking not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load booking history"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+func (h *BookingHandler) CompanyBookings(c *gin.Context) {
+	orgID, ok := parseIDParam(c, "id", "invalid organization id")
+	if !ok {
+		return
+	}
+
+	var status *domain.BookingStatus
+	if v := c.Query("status"); v != "" {
+		st := domain.BookingStatus(v)
+		status = &st
+	}
+
+	items, err := h.svc.ListCompanyBookings(c.GetInt("userID"), orgID, status)
+	if err != nil {
+		if errors.Is(err, services.ErrForbidden) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "only company admins and managers can see company bookings"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load company bookings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+func (h *BookingHandler) CompanyApproveBooking(c *gin.Context) {
+	h.companyDecision(c, h.svc.CompanyApproveBooking, "booking sent to the owner")
+}
+
+func (h *BookingHandler) CompanyRejectBooking(c *gin.Context) {
+	h.companyDecision(c, h.svc.CompanyRejectBooking, "booking rejected by the company")
+}
+
+func (h *BookingHandler) companyDecision(c *gin.Context, decide func(userID, orgID, bookingID int, reason string) error, message string) {
+	orgID, ok := parseIDParam(c, "id", "invalid organization id")
+	if !ok {
+		return
+	}
+	bookingID, ok := parseIDParam(c, "bookingId", "invalid booking id")
+	if !ok {
+		return
+	}
+	reason, ok := bindReason(c)
+	if !ok {
+		return
+	}
+
+	if err := decide(c.GetInt("userID"), orgID, bookingID, reason); err != nil {
+		switch {
+		case errors.Is(err, services.ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": "only company admins and managers can decide on company bookings"})
+		case errors.Is(err, repository.ErrBookingNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "booking not found"})
+		case errors.Is(err, services.ErrWrongStatus):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update booking"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": message})
+}
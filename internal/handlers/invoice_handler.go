@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"SpaceBookProject/internal/domain"
+	"SpaceBookProject/internal/pdf"
+	"SpaceBookProject/internal/repository"
+	"SpaceBookProject/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type InvoiceHandler struct {
+	svc *services.InvoiceService
+}
+
+func NewInvoiceHandler(svc *services.InvoiceService) *InvoiceHandler {
+	return &InvoiceHandler{svc: svc}
+}
+
+// Invoice отдаёт счёт брони в PDF, а с ?format=json — его данные.
+func (h *InvoiceHandler) Invoice(c *gin.Context) {
+	bookingID, ok := parseIDParam(c, "id", "invalid booking id")
+	if !ok {
+		return
+	}
+
+	inv, err := h.svc.Invoice(c.GetInt("userID"), bookingID)
+	if err != nil {
+		writeInvoiceError(c, err)
+		return
+	}
+	writeInvoice(c, inv)
+}
+
+func (h *InvoiceHandler) CreditNotes(c *gin.Context) {
+	bookingID, ok := parseIDParam(c, "id", "invalid booking id")
+	if !ok {
+		return
+	}
+
+	items, err := h.svc.CreditNotes(c.GetInt("userID"), bookingID)
+	if err != nil {
+		writeInvoiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+func (h *InvoiceHandler) CreditNote(c *gin.Context) {
+	bookingID, ok := parseIDParam(c, "id", "invalid booking id")
+	if !ok {
+		return
+	}
+	noteID, ok := parseIDParam(c, "noteId", "invalid credit note id")
+	if !ok {
+		return
+	}
+
+	note, err := h.svc.CreditNote(c.GetInt("userID"), bookingID, noteID)
+	if err != nil {
+		writeInvoiceError(c, err)
+		return
+	}
+	writeInvoice(c, note)
+}
+
+func writeInvoice(c *gin.Context, inv *domain.Invoice) {
+	if c.Query("format") == "json" {
+		c.JSON(http.StatusOK, inv)
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.pdf"`, inv.Number))
+	c.Data(http.StatusOK, "application/pdf", pdf.RenderInvoice(inv))
+}
+
+func writeInvoiceError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": "you are not allowed to see this booking"})
+	case errors.Is(err, repository.ErrBookingNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "booking not found"})
+	case errors.Is(err, repository.ErrInvoiceNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "credit note not found"})
+	case errors.Is(err, services.ErrNotInvoiceable):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load invoice"})
+	}
+}
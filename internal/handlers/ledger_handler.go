@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"SpaceBookProject/internal/repository"
+	"SpaceBookProject/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LedgerHandler struct {
+	svc *services.LedgerService
+}
+
+func NewLedgerHandler(svc *services.LedgerService) *LedgerHandler {
+	return &LedgerHandler{svc: svc}
+}
+
+func (h *LedgerHandler) OwnerPayouts(c *gin.Context) {
+	balances, payouts, err := h.svc.OwnerPayouts(c.GetInt("userID"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load payouts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"balances": balances, "items": payouts})
+}
+
+func (h *LedgerHandler) PayoutStatement(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", "invalid payout id")
+	if !ok {
+		return
+	}
+
+	statement, err := h.svc.PayoutStatement(c.GetInt("userID"), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrPayoutNotFound), errors.Is(err, services.ErrForbidden):
+			c.JSON(http.StatusNotFound, gin.H{"error": "payout not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load payout statement"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, statement)
+}
+
+func (h *LedgerHandler) RunPayouts(c *gin.Context) {
+	payouts, err := h.svc.RunPayouts()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to run payouts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": payouts})
+}
+
+func (h *LedgerHandler) Reconcile(c *gin.Context) {
+	items, err := h.svc.Reconcile()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to reconcile ledger"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"SpaceBookProject/internal/domain"
+	"SpaceBookProject/internal/repository"
+	"SpaceBookProject/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OrganizationHandler struct {
+	svc *services.OrganizationService
+}
+
+func NewOrganizationHandler(svc *services.OrganizationService) *OrganizationHandler {
+	return &OrganizationHandler{svc: svc}
+}
+
+func (h *OrganizationHandler) CreateOrganization(c *gin.Context) {
+	var req domain.CreateOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	org, err := h.svc.CreateOrganization(c.GetInt("userID"), &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create organization"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, org)
+}
+
+func (h *OrganizationHandler) ListMyOrganizations(c *gin.Context) {
+	orgs, err := h.svc.ListMyOrganizations(c.GetInt("userID"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load organizations"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": orgs})
+}
+
+func (h *OrganizationHandler) UpdateOrganization(c *gin.Context) {
+	orgID, ok := parseIDParam(c, "id", "invalid organization id")
+	if !ok {
+		return
+	}
+
+	var req domain.UpdateOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	org, err := h.svc.UpdateOrganization(c.GetInt("userID"), orgID, &req)
+	if err != nil {
+		writeOrgError(c, err, "failed to update organization")
+		return
+	}
+
+	c.JSON(http.StatusOK, org)
+}
+
+func (h *OrganizationHandler) ListMembers(c *gin.Context) {
+	orgID, ok := parseIDParam(c, "id", "invalid organization id")
+	if !ok {
+		return
+	}
+
+	members, err := h.svc.ListMembers(c.GetInt("userID"), orgID)
+	if err != nil {
+		writeOrgError(c, err, "failed to load members")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": members})
+}
+
+func (h *OrganizationHandler) UpdateMember(c *gin.Context) {
+	orgID, ok := parseIDParam(c, "id", "invalid organization id")
+	if !ok {
+		return
+	}
+	memberID, ok := parseIDParam(c, "userId", "invalid user id")
+	if !ok {
+		return
+	}
+
+	var req domain.UpdateMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	if err := h.svc.UpdateMemberRole(c.GetInt("userID"), orgID, memberID, &req); err != nil {
+		writeOrgError(c, err, "failed to update member")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "member updated"})
+}
+
+func (h *OrganizationHandler) RemoveMember(c *gin.Context) {
+	orgID, ok := parseIDParam(c, "id", "invalid organization id")
+	if !ok {
+		return
+	}
+	memberID, ok := parseIDParam(c, "userId", "invalid user id")
+	if !ok {
+		return
+	}
+
+	if err := h.svc.RemoveMember(c.GetInt("userID"), orgID, memberID); err != nil {
+		writeOrgError(c, err, "failed to remove member")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "member removed"})
+}
+
+func (h *OrganizationHandler) Invite(c *gin.Context) {
+	orgID, ok := parseIDParam(c, "id", "invalid organization id")
+	if !ok {
+		return
+	}
+
+	var req domain.InviteMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	inv, err := h.svc.Invite(c.Request.Context(), c.GetInt("userID"), orgID, &req)
+	if err != nil {
+		writeOrgError(c, err, "failed to send invitation")
+		return
+	}
+
+	c.JSON(http.StatusCreated, inv)
+}
+
+func (h *OrganizationHandler) ListInvitations(c *gin.Context) {
+	orgID, ok := parseIDParam(c, "id", "invalid organization id")
+	if !ok {
+		return
+	}
+
+	invitations, err := h.svc.ListInvitations(c.GetInt("userID"), orgID)
+	if err != nil {
+		writeOrgError(c, err, "failed to load invitations")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": invitations})
+}
+
+func (h *OrganizationHandler) RevokeInvitation(c *gin.Context) {
+	orgID, ok := parseIDParam(c, "id", "invalid organization id")
+	if !ok {
+		return
+	}
+	invitationID, err := strconv.ParseInt(c.Param("invitationId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invitation id"})
+		return
+	}
+
+	if err := h.svc.RevokeInvitation(c.GetInt("userID"), orgID, invitationID); err != nil {
+		writeOrgError(c, err, "failed to revoke invitation")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "invitation revoked"})
+}
+
+func (h *OrganizationHandler) AcceptInvitation(c *gin.Context) {
+	var req domain.AcceptInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	inv, err := h.svc.AcceptInvitation(c.GetInt("userID"), &req)
+	if err != nil {
+		writeOrgError(c, err, "failed to accept invitation")
+		return
+	}
+
+	c.JSON(http.StatusOK, inv)
+}
+
+func parseIDParam(c *gin.Context, name, message string) (int, bool) {
+	id, err := strconv.Atoi(c.Param(name))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": message})
+		return 0, false
+	}
+	return id, true
+}
+
+func writeOrgError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, repository.ErrOrganizationNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "organization not found"})
+	case errors.Is(err, repository.ErrNotOrgMember):
+		c.JSON(http.StatusNotFound, gin.H{"error": "member not found"})
+	case errors.Is(err, repository.ErrInvitationNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "invitation not found"})
+	case errors.Is(err, services.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": "only organization admins can do this"})
+	case errors.Is(err, repository.ErrLastOrgAdmin):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrInvitationInvalid):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrInvitationEmailMismatch):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
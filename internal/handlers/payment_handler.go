@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"SpaceBookProject/internal/domain"
+	"SpaceBookProject/internal/payments"
+	"SpaceBookProject/internal/repository"
+	"SpaceBookProject/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PaymentHandler struct {
+	svc *services.PaymentService
+}
+
+func NewPaymentHandler(svc *services.PaymentService) *PaymentHandler {
+	return &PaymentHandler{svc: svc}
+}
+
+// Pay отвечает 201 при успешной оплате, 202 — если плательщик должен
+// подтвердить её у шлюза, и 402 при отказе.
+func (h *PaymentHandler) Pay(c *gin.Context) {
+	bookingID, ok := parseIDParam(c, "id", "invalid booking id")
+	if !ok {
+		return
+	}
+
+	payment, err := h.svc.Pay(c.Request.Context(), c.GetInt("userID"), bookingID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": "you can pay only for your own booking"})
+		case errors.Is(err, repository.ErrBookingNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "booking not found"})
+		case errors.Is(err, services.ErrNotPayable), errors.Is(err, repository.ErrPaymentInProgress):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusBadGateway, gin.H{"error": "payment provider error"})
+		}
+		return
+	}
+
+	switch payment.Status {
+	case domain.PaymentStatusFailed:
+		c.JSON(http.StatusPaymentRequired, payment)
+	case domain.PaymentStatusRequiresAction, domain.PaymentStatusAuthorized:
+		c.JSON(http.StatusAccepted, payment)
+	default:
+		c.JSON(http.StatusCreated, payment)
+	}
+}
+
+func (h *PaymentHandler) ListPayments(c *gin.Context) {
+	bookingID, ok := parseIDParam(c, "id", "invalid booking id")
+	if !ok {
+		return
+	}
+
+	items, err := h.svc.ListPayments(c.GetInt("userID"), bookingID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": "you are not allowed to see this booking"})
+		case errors.Is(err, repository.ErrBookingNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "booking not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load payments"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+// Webhook принимает уведомления шлюза. Любой ответ, кроме 2xx, шлюз
+// считает сбоем и присылает событие повторно.
+func (h *PaymentHandler) Webhook(c *gin.Context) {
+	body, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read request body"})
+		return
+	}
+
+	if err := h.svc.HandleWebhook(c.Request.Context(), c.Request.Header, body); err != nil {
+		switch {
+		case errors.Is(err, payments.ErrInvalidSignature):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		case errors.Is(err, payments.ErrInvalidWebhook):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, repository.ErrPaymentNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "payment not found"})
+		default:
+			log.Printf("[payments] webhook processing failed: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to process webhook"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"received": true})
+}
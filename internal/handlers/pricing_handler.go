@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"SpaceBookProject/internal/domain"
+	"SpaceBookProject/internal/repository"
+	"SpaceBookProject/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PricingHandler struct {
+	svc *services.PricingService
+}
+
+func NewPricingHandler(svc *services.PricingService) *PricingHandler {
+	return &PricingHandler{svc: svc}
+}
+
+func (h *PricingHandler) GetPricing(c *gin.Context) {
+	spaceID, ok := parseIDParam(c, "id", "invalid space id")
+	if !ok {
+		return
+	}
+
+	pricing, err := h.svc.GetPricing(spaceID)
+	if err != nil {
+		writePricingError(c, err, "failed to load pricing")
+		return
+	}
+
+	c.JSON(http.StatusOK, pricing)
+}
+
+func (h *PricingHandler) SetPricing(c *gin.Context) {
+	spaceID, ok := parseIDParam(c, "id", "invalid space id")
+	if !ok {
+		return
+	}
+
+	var req domain.SetPricingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+		return
+	}
+
+	pricing, err := h.svc.SetPricing(c.GetInt("userID"), spaceID, &req)
+	if err != nil {
+		writePricingError(c, err, "failed to update pricing")
+		return
+	}
+
+	c.JSON(http.StatusOK, pricing)
+}
+
+func (h *PricingHandler) Quote(c *gin.Context) {
+	spaceID, ok := parseIDParam(c, "id", "invalid space id")
+	if !ok {
+		return
+	}
+
+	var req domain.QuoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+		return
+	}
+
+	quote, err := h.svc.Quote(spaceID, &req)
+	if err != nil {
+		writePricingError(c, err, "failed to calculate quote")
+		return
+	}
+
+	c.JSON(http.StatusOK, quote)
+}
+
+func writePricingError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, repository.ErrSpaceNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "space not found"})
+	case errors.Is(err, repository.ErrPricingNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": "you are not allowed to manage this space"})
+	case errors.Is(err, domain.ErrInvalidCurrency),
+		errors.Is(err, domain.ErrNoRates),
+		errors.Is(err, domain.ErrInvalidPricing),
+		errors.Is(err, services.ErrInvalidBookingTime),
+		errors.Is(err, services.ErrSlotMisaligned),
+		errors.Is(err, services.ErrSpaceInactive):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
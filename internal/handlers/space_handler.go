@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"SpaceBookProject/internal/repository"
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -82,9 +83,236 @@ func (h *SpaceHandler) CreateSpace(c *gin.Context) {
 
 	space, err := h.svc.CreateSpace(ownerID, &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create space"})
+		switch {
+		case errors.Is(err, services.ErrInvalidTimezone), errors.Is(err, services.ErrInvalidSlotMinutes),
+			errors.Is(err, domain.ErrInvalidCancellationPolicy), errors.Is(err, domain.ErrInvalidRefundTiers):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": "only organization admins can add spaces to it"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create space"})
+		}
 		return
 	}
 
 	c.JSON(http.StatusCreated, space)
 }
+
+func (h *SpaceHandler) GetSpace(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid space id"})
+		return
+	}
+
+	viewerID := 0
+	if rawID, ok := c.Get("userID"); ok {
+		viewerID, _ = rawID.(int)
+	}
+
+	space, err := h.svc.GetSpace(id, viewerID)
+	if err != nil {
+		writeSpaceError(c, err, "failed to load space")
+		return
+	}
+
+	c.JSON(http.StatusOK, space)
+}
+
+func (h *SpaceHandler) OwnerSpaces(c *gin.Context) {
+	rawID, ok := c.Get("userID")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	ownerID := rawID.(int)
+
+	spaces, err := h.svc.ListOwnerSpaces(ownerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load spaces"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": spaces})
+}
+
+func (h *SpaceHandler) UpdateSpace(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid space id"})
+		return
+	}
+
+	var req domain.UpdateSpaceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	rawID, ok := c.Get("userID")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	ownerID := rawID.(int)
+
+	space, err := h.svc.UpdateSpace(ownerID, id, &req)
+	if err != nil {
+		writeSpaceError(c, err, "failed to update space")
+		return
+	}
+
+	c.JSON(http.StatusOK, space)
+}
+
+func (h *SpaceHandler) SetOrganization(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid space id"})
+		return
+	}
+
+	var req domain.SetSpaceOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	rawID, ok := c.Get("userID")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	space, err := h.svc.SetOrganization(rawID.(int), id, req.OrganizationID)
+	if err != nil {
+		writeSpaceError(c, err, "failed to update space organization")
+		return
+	}
+
+	c.JSON(http.StatusOK, space)
+}
+
+func (h *SpaceHandler) SetInstantBooking(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid space id"})
+		return
+	}
+
+	var req domain.InstantBookingRules
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	rawID, ok := c.Get("userID")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	space, err := h.svc.SetInstantBooking(rawID.(int), id, req)
+	if err != nil {
+		writeSpaceError(c, err, "failed to update instant booking")
+		return
+	}
+
+	c.JSON(http.StatusOK, space)
+}
+
+func (h *SpaceHandler) SetCancellationPolicy(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid space id"})
+		return
+	}
+
+	var req domain.CancellationPolicy
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	rawID, ok := c.Get("userID")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	space, err := h.svc.SetCancellationPolicy(rawID.(int), id, req)
+	if err != nil {
+		writeSpaceError(c, err, "failed to update cancellation policy")
+		return
+	}
+
+	c.JSON(http.StatusOK, space)
+}
+
+func (h *SpaceHandler) ActivateSpace(c *gin.Context) {
+	h.setActive(c, true)
+}
+
+func (h *SpaceHandler) DeactivateSpace(c *gin.Context) {
+	h.setActive(c, false)
+}
+
+func (h *SpaceHandler) setActive(c *gin.Context, active bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid space id"})
+		return
+	}
+
+	rawID, ok := c.Get("userID")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	ownerID := rawID.(int)
+
+	space, err := h.svc.SetActive(ownerID, id, active)
+	if err != nil {
+		writeSpaceError(c, err, "failed to update space")
+		return
+	}
+
+	c.JSON(http.StatusOK, space)
+}
+
+func (h *SpaceHandler) DeleteSpace(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid space id"})
+		return
+	}
+
+	rawID, ok := c.Get("userID")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	ownerID := rawID.(int)
+
+	if err := h.svc.DeleteSpace(ownerID, id); err != nil {
+		writeSpaceError(c, err, "failed to delete space")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "space deleted"})
+}
+
+func writeSpaceError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, repository.ErrSpaceNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "space not found"})
+	case errors.Is(err, services.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": "you are not allowed to manage this space"})
+	case errors.Is(err, services.ErrInvalidTimezone), errors.Is(err, services.ErrInvalidSlotMinutes):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrSpaceUnpublished):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"SpaceBookProject/internal/domain"
+	"SpaceBookProject/internal/repository"
+	"SpaceBookProject/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WaitlistHandler struct {
+	svc *services.WaitlistService
+}
+
+func NewWaitlistHandler(svc *services.WaitlistService) *WaitlistHandler {
+	return &WaitlistHandler{svc: svc}
+}
+
+func (h *WaitlistHandler) Join(c *gin.Context) {
+	spaceID, ok := parseIDParam(c, "id", "invalid space id")
+	if !ok {
+		return
+	}
+
+	var req domain.JoinWaitlistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+		return
+	}
+
+	entry, err := h.svc.Join(c.GetInt("userID"), spaceID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrSpaceNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "space not found"})
+		case errors.Is(err, services.ErrSpaceAvailable), errors.Is(err, repository.ErrAlreadyWaitlisted):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, entry)
+}
+
+func (h *WaitlistHandler) Leave(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", "invalid waitlist entry id")
+	if !ok {
+		return
+	}
+
+	if err := h.svc.Leave(c.GetInt("userID"), id); err != nil {
+		if errors.Is(err, repository.ErrWaitlistEntryNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "waitlist entry not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to leave waitlist"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "left waitlist"})
+}
+
+func (h *WaitlistHandler) MyWaitlist(c *gin.Context) {
+	items, err := h.svc.ListMine(c.GetInt("userID"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load waitlist"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+func (h *WaitlistHandler) SpaceWaitlist(c *gin.Context) {
+	spaceID, ok := parseIDParam(c, "id", "invalid space id")
+	if !ok {
+		return
+	}
+
+	items, err := h.svc.ListForSpace(c.GetInt("userID"), spaceID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": "you are not allowed to see this space's waitlist"})
+		case errors.Is(err, repository.ErrSpaceNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "space not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load waitlist"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+// Demand — сводка очереди по помещениям владельца.
+func (h *WaitlistHandler) Demand(c *gin.Context) {
+	items, err := h.svc.Demand(c.GetInt("userID"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load waitlist demand"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
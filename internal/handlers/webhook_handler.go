@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"SpaceBookProject/internal/domain"
+	"SpaceBookProject/internal/repository"
+	"SpaceBookProject/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WebhookHandler struct {
+	svc *services.WebhookService
+}
+
+func NewWebhookHandler(svc *services.WebhookService) *WebhookHandler {
+	return &WebhookHandler{svc: svc}
+}
+
+func (h *WebhookHandler) CreateEndpoint(c *gin.Context) {
+	var req domain.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+		return
+	}
+
+	uidVal, ok := c.Get("userID")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	userID := uidVal.(int)
+
+	endpoint, err := h.svc.CreateEndpoint(userID, &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create webhook"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, endpoint)
+}
+
+func (h *WebhookHandler) ListEndpoints(c *gin.Context) {
+	uidVal, ok := c.Get("userID")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	userID := uidVal.(int)
+
+	items, err := h.svc.ListEndpoints(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load webhooks"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+func (h *WebhookHandler) DeleteEndpoint(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid webhook id"})
+		return
+	}
+
+	uidVal, ok := c.Get("userID")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	userID := uidVal.(int)
+
+	if err := h.svc.DeleteEndpoint(userID, id); err != nil {
+		writeWebhookError(c, err, "failed to delete webhook")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "webhook deleted"})
+}
+
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid webhook id"})
+		return
+	}
+
+	uidVal, ok := c.Get("userID")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	userID := uidVal.(int)
+
+	items, err := h.svc.ListDeliveries(userID, id)
+	if err != nil {
+		writeWebhookError(c, err, "failed to load deliveries")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+func (h *WebhookHandler) ListAttempts(c *gin.Context) {
+	id, deliveryID, ok := parseDeliveryParams(c)
+	if !ok {
+		return
+	}
+
+	uidVal, ok := c.Get("userID")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	userID := uidVal.(int)
+
+	items, err := h.svc.ListAttempts(userID, id, deliveryID)
+	if err != nil {
+		writeWebhookError(c, err, "failed to load delivery attempts")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+func (h *WebhookHandler) Redeliver(c *gin.Context) {
+	id, deliveryID, ok := parseDeliveryParams(c)
+	if !ok {
+		return
+	}
+
+	uidVal, ok := c.Get("userID")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	userID := uidVal.(int)
+
+	if err := h.svc.Redeliver(userID, id, deliveryID); err != nil {
+		writeWebhookError(c, err, "failed to schedule redelivery")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "redelivery scheduled"})
+}
+
+func parseDeliveryParams(c *gin.Context) (int, int64, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid webhook id"})
+		return 0, 0, false
+	}
+	deliveryID, err := strconv.ParseInt(c.Param("deliveryId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid delivery id"})
+		return 0, 0, false
+	}
+	return id, deliveryID, true
+}
+
+func writeWebhookError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, repository.ErrWebhookNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "webhook not found"})
+	case errors.Is(err, repository.ErrWebhookDeliveryNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "delivery not found"})
+	case errors.Is(err, services.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": "this webhook does not belong to you"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -0,0 +1,111 @@
+package notify
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"time"
+
+	"SpaceBookProject/internal/domain"
+	"SpaceBookProject/internal/mailer"
+)
+
+type AccountEmailData struct {
+	RecipientName string
+	Link          string
+	ValidFor      string
+}
+
+type InvitationEmailData struct {
+	OrganizationName string
+	InviterName      string
+	Role             string
+	Link             string
+	ValidFor         string
+}
+
+// AccountNotifier отправляет письма со ссылками сброса пароля,
+// подтверждения email и приглашений в организацию. Ссылки ведут на
+// фронтенд по адресу baseURL.
+type AccountNotifier struct {
+	mailer   mailer.Mailer
+	renderer *Renderer
+	baseURL  string
+}
+
+func NewAccountNotifier(m mailer.Mailer, renderer *Renderer, baseURL string) *AccountNotifier {
+	return &AccountNotifier{
+		mailer:   m,
+		renderer: renderer,
+		baseURL:  strings.TrimRight(baseURL, "/"),
+	}
+}
+
+func (n *AccountNotifier) SendPasswordReset(ctx context.Context, user *domain.User, token string, validFor time.Duration) error {
+	return n.send(ctx, user, "password_reset", "/reset-password", token, validFor)
+}
+
+func (n *AccountNotifier) SendEmailVerification(ctx context.Context, user *domain.User, token string, validFor time.Duration) error {
+	return n.send(ctx, user, "email_verification", "/verify-email", token, validFor)
+}
+
+// SendOrganizationInvitation пишет на адрес из приглашения на языке
+// пригласившего: получатель может ещё не быть зарегистрирован.
+func (n *AccountNotifier) SendOrganizationInvitation(
+	ctx context.Context,
+	inv *domain.OrgInvitation,
+	org *domain.Organization,
+	inviter *domain.User,
+	token string,
+	validFor time.Duration,
+) error {
+	data := InvitationEmailData{
+		OrganizationName: org.Name,
+		InviterName:      fullName(inviter),
+		Role:             string(inv.Role),
+		Link:             n.baseURL + "/invitations/accept?token=" + url.QueryEscape(token),
+		ValidFor:         formatDuration(validFor),
+	}
+
+	subject, body, err := n.renderer.Render(inviter.Locale, "org_invitation", data)
+	if err != nil {
+		return err
+	}
+
+	return n.mailer.Send(ctx, mailer.Message{
+		To:      inv.Email,
+		Subject: subject,
+		Body:    body,
+	})
+}
+
+func (n *AccountNotifier) send(ctx context.Context, user *domain.User, template, path, token string, validFor time.Duration) error {
+	data := AccountEmailData{
+		RecipientName: fullName(user),
+		Link:          n.baseURL + path + "?token=" + url.QueryEscape(token),
+		ValidFor:      formatDuration(validFor),
+	}
+
+	subject, body, err := n.renderer.Render(user.Locale, template, data)
+	if err != nil {
+		return err
+	}
+
+	return n.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: subject,
+		Body:    body,
+	})
+}
+
+// formatDuration превращает "48h0m0s" в "48h", а "30m0s" — в "30m".
+func formatDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
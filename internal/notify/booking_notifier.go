@@ -0,0 +1,143 @@
+package notify
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"SpaceBookProject/internal/domain"
+	"SpaceBookProject/internal/mailer"
+	"SpaceBookProject/internal/repository"
+)
+
+const timeLayout = "02.01.2006 15:04"
+
+// BookingEmailData — данные, доступные шаблонам писем о бронированиях.
+type BookingEmailData struct {
+	RecipientName string
+	TenantName    string
+	SpaceTitle    string
+	BookingID     int
+	Status        domain.BookingStatus
+	From          string
+	To            string
+	Timezone      string
+	// CancelledBy и Refund заполнены только в письмах об отмене; Refund
+	// пуст, если у брони нет стоимости.
+	CancelledBy domain.CancellationParty
+	Refund      string
+}
+
+// BookingNotifier отправляет письма по событиям бронирования: владельцу —
+// о новых заявках и отменах арендатором, арендатору — о решениях
+// владельца, отменах владельцем или администратором, истёкших заявках и
+// отказе в согласовании внутри компании.
+type BookingNotifier struct {
+	mailer   mailer.Mailer
+	renderer *Renderer
+	bookings *repository.BookingRepository
+	spaces   *repository.SpaceRepository
+	users    *repository.UserRepository
+}
+
+func NewBookingNotifier(
+	m mailer.Mailer,
+	renderer *Renderer,
+	bookings *repository.BookingRepository,
+	spaces *repository.SpaceRepository,
+	users *repository.UserRepository,
+) *BookingNotifier {
+	return &BookingNotifier{
+		mailer:   m,
+		renderer: renderer,
+		bookings: bookings,
+		spaces:   spaces,
+		users:    users,
+	}
+}
+
+func (n *BookingNotifier) Notify(ctx context.Context, evt domain.BookingEvent) error {
+	name, toOwner, ok := bookingEmail(evt)
+	if !ok {
+		return nil
+	}
+
+	b, err := n.bookings.GetByID(evt.BookingID)
+	if err != nil {
+		return err
+	}
+	sp, err := n.spaces.GetByID(b.SpaceID)
+	if err != nil {
+		return err
+	}
+	tenant, err := n.users.GetByID(b.TenantID)
+	if err != nil {
+		return err
+	}
+
+	recipient := tenant
+	if toOwner {
+		if recipient, err = n.users.GetByID(sp.OwnerID); err != nil {
+			return err
+		}
+	}
+
+	loc, err := sp.Location()
+	if err != nil {
+		log.Printf("[notify] space %d has invalid timezone %q, using UTC", sp.ID, sp.Timezone)
+		loc = time.UTC
+	}
+
+	data := BookingEmailData{
+		RecipientName: fullName(recipient),
+		TenantName:    fullName(tenant),
+		SpaceTitle:    sp.Title,
+		BookingID:     b.ID,
+		Status:        b.Status,
+		From:          b.DateFrom.In(loc).Format(timeLayout),
+		To:            b.DateTo.In(loc).Format(timeLayout),
+		Timezone:      loc.String(),
+	}
+	if c := evt.Cancellation; c != nil {
+		data.CancelledBy = c.CancelledBy
+		if c.Refund != nil {
+			data.Refund = domain.FormatAmount(c.Refund.Amount) + " " + c.Refund.Currency
+		}
+	}
+
+	subject, body, err := n.renderer.Render(recipient.Locale, name, data)
+	if err != nil {
+		return err
+	}
+
+	return n.mailer.Send(ctx, mailer.Message{
+		To:      recipient.Email,
+		Subject: subject,
+		Body:    body,
+	})
+}
+
+// bookingEmail выбирает шаблон письма о событии и получателя. Об отмене
+// узнаёт другая сторона: владелец — об отмене арендатором, арендатор — об
+// отмене владельцем или администратором.
+func bookingEmail(evt domain.BookingEvent) (name string, toOwner, ok bool) {
+	name = "booking_" + string(evt.Type)
+	switch evt.Type {
+	case domain.BookingEventCreated, domain.BookingEventConfirmed:
+		return name, true, true
+	case domain.BookingEventCancelled:
+		if c := evt.Cancellation; c != nil && c.CancelledBy != domain.CancelledByTenant {
+			return "booking_cancelled_by_owner", false, true
+		}
+		return name, true, true
+	case domain.BookingEventApproved, domain.BookingEventRejected, domain.BookingEventExpired,
+		domain.BookingEventCompanyRejected:
+		return name, false, true
+	}
+	return "", false, false
+}
+
+func fullName(u *domain.User) string {
+	return strings.TrimSpace(u.FirstName + " " + u.LastName)
+}
@@ -0,0 +1,92 @@
+package notify
+
+import (
+	"strings"
+	"testing"
+
+	"SpaceBookProject/internal/domain"
+)
+
+func TestBookingEmailCancellation(t *testing.T) {
+	refund := &domain.Money{Amount: 125050, Currency: "EUR"}
+	tests := []struct {
+		name        string
+		by          domain.CancellationParty
+		refund      *domain.Money
+		wantName    string
+		wantToOwner bool
+		wantBody    []string
+	}{
+		{
+			name:        "tenant cancellation goes to owner",
+			by:          domain.CancelledByTenant,
+			refund:      &domain.Money{Amount: 62525, Currency: "EUR"},
+			wantName:    "booking_cancelled",
+			wantToOwner: true,
+			wantBody:    []string{"Tenant cancelled booking #7", "refunded 625.25 EUR"},
+		},
+		{
+			name:     "owner cancellation goes to tenant",
+			by:       domain.CancelledByOwner,
+			refund:   refund,
+			wantName: "booking_cancelled_by_owner",
+			wantBody: []string{"The owner cancelled your booking #7", "refunded 1 250.50 EUR"},
+		},
+		{
+			name:     "admin cancellation goes to tenant",
+			by:       domain.CancelledByAdmin,
+			refund:   refund,
+			wantName: "booking_cancelled_by_owner",
+			wantBody: []string{"An administrator cancelled your booking #7", "refunded 1 250.50 EUR"},
+		},
+		{
+			name:     "booking without price",
+			by:       domain.CancelledByOwner,
+			wantName: "booking_cancelled_by_owner",
+			wantBody: []string{"The owner cancelled your booking #7"},
+		},
+	}
+	renderer := NewRenderer("")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evt := domain.BookingEvent{
+				Type:         domain.BookingEventCancelled,
+				BookingID:    7,
+				Cancellation: &domain.Cancellation{BookingID: 7, CancelledBy: tt.by, Refund: tt.refund},
+			}
+			name, toOwner, ok := bookingEmail(evt)
+			if !ok || name != tt.wantName || toOwner != tt.wantToOwner {
+				t.Fatalf("bookingEmail() = %q, %v, %v, want %q, %v, true", name, toOwner, ok, tt.wantName, tt.wantToOwner)
+			}
+
+			data := BookingEmailData{RecipientName: "Recipient", TenantName: "Tenant", SpaceTitle: "Room", BookingID: 7, CancelledBy: tt.by}
+			if tt.refund != nil {
+				data.Refund = domain.FormatAmount(tt.refund.Amount) + " " + tt.refund.Currency
+			}
+			for _, locale := range []string{"en", "ru"} {
+				_, body, err := renderer.Render(locale, name, data)
+				if err != nil {
+					t.Fatalf("render %s: %v", locale, err)
+				}
+				if locale != "en" {
+					continue
+				}
+				for _, want := range tt.wantBody {
+					if !strings.Contains(body, want) {
+						t.Errorf("body %q does not contain %q", body, want)
+					}
+				}
+				if tt.refund == nil && strings.Contains(body, "refunded") {
+					t.Errorf("body %q mentions a refund for a booking without price", body)
+				}
+			}
+		})
+	}
+}
+
+func TestBookingEmailCancellationWithoutCalculation(t *testing.T) {
+	name, toOwner, ok := bookingEmail(domain.BookingEvent{Type: domain.BookingEventCancelled})
+	if !ok || name != "booking_cancelled" || !toOwner {
+		t.Fatalf("bookingEmail() = %q, %v, %v, want booking_cancelled to owner", name, toOwner, ok)
+	}
+}
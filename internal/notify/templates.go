@@ -0,0 +1,69 @@
+package notify
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"SpaceBookProject/internal/domain"
+)
+
+//go:embed templates
+var builtinTemplates embed.FS
+
+// Renderer рендерит письма из шаблонов templates/<locale>/<name>.tmpl.
+// Каждый шаблон определяет блоки "subject" и "body". Файл с тем же путём
+// в overrideDir имеет приоритет над встроенным и перечитывается при каждой
+// отправке, поэтому тексты можно менять без пересборки.
+type Renderer struct {
+	overrideDir string
+}
+
+func NewRenderer(overrideDir string) *Renderer {
+	return &Renderer{overrideDir: overrideDir}
+}
+
+func (r *Renderer) Render(locale, name string, data any) (subject, body string, err error) {
+	if !domain.ValidLocale(locale) {
+		locale = domain.DefaultLocale
+	}
+
+	src, err := r.load(locale, name)
+	if err != nil {
+		return "", "", err
+	}
+
+	tmpl, err := template.New(name).Parse(string(src))
+	if err != nil {
+		return "", "", err
+	}
+
+	var subj, text bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&subj, "subject", data); err != nil {
+		return "", "", err
+	}
+	if err := tmpl.ExecuteTemplate(&text, "body", data); err != nil {
+		return "", "", err
+	}
+	return strings.TrimSpace(subj.String()), strings.TrimSpace(text.String()) + "\n", nil
+}
+
+func (r *Renderer) load(locale, name string) ([]byte, error) {
+	file := name + ".tmpl"
+	if r.overrideDir != "" {
+		src, err := os.ReadFile(filepath.Join(r.overrideDir, locale, file))
+		if err == nil {
+			return src, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return builtinTemplates.ReadFile(path.Join("templates", locale, file))
+}
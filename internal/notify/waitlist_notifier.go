@@ -0,0 +1,77 @@
+package notify
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"SpaceBookProject/internal/domain"
+	"SpaceBookProject/internal/mailer"
+)
+
+// WaitlistEmailData — данные письма об освободившихся датах. BookingID
+// не ноль, если для арендатора уже создана заявка.
+type WaitlistEmailData struct {
+	RecipientName string
+	SpaceTitle    string
+	From          string
+	To            string
+	Timezone      string
+	BookingID     int
+	Link          string
+}
+
+// WaitlistNotifier сообщает арендаторам из листа ожидания, что нужные
+// им даты освободились.
+type WaitlistNotifier struct {
+	mailer   mailer.Mailer
+	renderer *Renderer
+	baseURL  string
+}
+
+func NewWaitlistNotifier(m mailer.Mailer, renderer *Renderer, baseURL string) *WaitlistNotifier {
+	return &WaitlistNotifier{
+		mailer:   m,
+		renderer: renderer,
+		baseURL:  strings.TrimRight(baseURL, "/"),
+	}
+}
+
+func (n *WaitlistNotifier) SendWaitlistOpening(
+	ctx context.Context,
+	tenant *domain.User,
+	sp *domain.Space,
+	entry *domain.WaitlistEntry,
+	booking *domain.Booking,
+) error {
+	loc, err := sp.Location()
+	if err != nil {
+		log.Printf("[notify] space %d has invalid timezone %q, using UTC", sp.ID, sp.Timezone)
+		loc = time.UTC
+	}
+
+	data := WaitlistEmailData{
+		RecipientName: fullName(tenant),
+		SpaceTitle:    sp.Title,
+		From:          entry.DateFrom.In(loc).Format(timeLayout),
+		To:            entry.DateTo.In(loc).Format(timeLayout),
+		Timezone:      loc.String(),
+		Link:          n.baseURL + "/spaces/" + strconv.Itoa(sp.ID),
+	}
+	if booking != nil {
+		data.BookingID = booking.ID
+	}
+
+	subject, body, err := n.renderer.Render(tenant.Locale, "waitlist_opening", data)
+	if err != nil {
+		return err
+	}
+
+	return n.mailer.Send(ctx, mailer.Message{
+		To:      tenant.Email,
+		Subject: subject,
+		Body:    body,
+	})
+}
@@ -0,0 +1,85 @@
+package payments
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+)
+
+// FakeSignatureHeader — заголовок с подписью вебхуков FakeProvider.
+const FakeSignatureHeader = "X-Fake-Signature"
+
+// FakeProvider — шлюз для локальной разработки и тестов: деньги никуда не
+// уходят, состояние не хранится. Сумма, оканчивающаяся на 02 минимальные
+// единицы, отклоняется, на 03 — требует подтверждения плательщиком, чей
+// результат затем присылается вебхуком, подписанным SignFakeWebhook.
+type FakeProvider struct {
+	secret string
+}
+
+func NewFakeProvider(webhookSecret string) *FakeProvider {
+	return &FakeProvider{secret: webhookSecret}
+}
+
+func (p *FakeProvider) Name() string {
+	return "fake"
+}
+
+func (p *FakeProvider) Authorize(ctx context.Context, req AuthorizeRequest) (*Authorization, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	ref, err := fakeRef("fake_pay_")
+	if err != nil {
+		return nil, err
+	}
+
+	switch req.Amount % 100 {
+	case 2:
+		return &Authorization{Ref: ref, Status: AuthorizationDeclined, DeclineReason: "card declined"}, nil
+	case 3:
+		return &Authorization{Ref: ref, Status: AuthorizationRequiresAction, ActionURL: "https://fake-payments.local/confirm/" + ref}, nil
+	default:
+		return &Authorization{Ref: ref, Status: AuthorizationApproved}, nil
+	}
+}
+
+func (p *FakeProvider) Capture(ctx context.Context, _ CaptureRequest) error {
+	return ctx.Err()
+}
+
+func (p *FakeProvider) Refund(ctx context.Context, _ RefundRequest) error {
+	return ctx.Err()
+}
+
+func (p *FakeProvider) VerifyWebhook(header http.Header, body []byte) (*WebhookEvent, error) {
+	expected := SignFakeWebhook(p.secret, body)
+	if !hmac.Equal([]byte(header.Get(FakeSignatureHeader)), []byte(expected)) {
+		return nil, ErrInvalidSignature
+	}
+
+	var evt WebhookEvent
+	if err := json.Unmarshal(body, &evt); err != nil || evt.ID == "" || evt.Ref == "" {
+		return nil, ErrInvalidWebhook
+	}
+	return &evt, nil
+}
+
+// SignFakeWebhook считает подпись "sha256=<hex>" от HMAC-SHA256(secret, body).
+func SignFakeWebhook(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func fakeRef(prefix string) (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return prefix + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,82 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrInvalidWebhook   = errors.New("malformed webhook payload")
+)
+
+type AuthorizationStatus string
+
+const (
+	AuthorizationApproved       AuthorizationStatus = "authorized"
+	AuthorizationRequiresAction AuthorizationStatus = "requires_action"
+	AuthorizationDeclined       AuthorizationStatus = "declined"
+)
+
+type WebhookEventType string
+
+const (
+	WebhookPaymentAuthorized WebhookEventType = "payment.authorized"
+	WebhookPaymentCaptured   WebhookEventType = "payment.captured"
+	WebhookPaymentFailed     WebhookEventType = "payment.failed"
+	WebhookRefundSucceeded   WebhookEventType = "refund.succeeded"
+)
+
+// Provider — платёжный шлюз. IdempotencyKey во всех запросах позволяет
+// шлюзу распознать повтор после сбоя и не списать или не вернуть деньги
+// дважды. Реализации должны быть безопасны для использования из
+// нескольких горутин.
+type Provider interface {
+	Name() string
+	// Authorize блокирует сумму у плательщика, не списывая её.
+	Authorize(ctx context.Context, req AuthorizeRequest) (*Authorization, error)
+	Capture(ctx context.Context, req CaptureRequest) error
+	Refund(ctx context.Context, req RefundRequest) error
+	// VerifyWebhook проверяет подпись уведомления шлюза и разбирает его.
+	VerifyWebhook(header http.Header, body []byte) (*WebhookEvent, error)
+}
+
+type AuthorizeRequest struct {
+	Amount         int64
+	Currency       string
+	Description    string
+	IdempotencyKey string
+}
+
+// Authorization — ответ шлюза на Authorize. ActionURL заполнен, когда
+// плательщику нужно подтвердить платёж; DeclineReason — при отказе.
+type Authorization struct {
+	Ref           string
+	Status        AuthorizationStatus
+	ActionURL     string
+	DeclineReason string
+}
+
+type CaptureRequest struct {
+	Ref            string
+	Amount         int64
+	IdempotencyKey string
+}
+
+type RefundRequest struct {
+	Ref            string
+	Amount         int64
+	IdempotencyKey string
+}
+
+// WebhookEvent — уведомление шлюза о платеже Ref. ID уникален у шлюза и
+// служит для отбрасывания повторных доставок. Amount у refund.succeeded —
+// вся возвращённая к этому моменту сумма, а не сумма одного возврата.
+type WebhookEvent struct {
+	ID     string           `json:"id"`
+	Type   WebhookEventType `json:"type"`
+	Ref    string           `json:"payment_ref"`
+	Amount int64            `json:"amount"`
+	Reason string           `json:"reason,omitempty"`
+}
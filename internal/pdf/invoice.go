@@ -0,0 +1,112 @@
+package pdf
+
+import (
+	"fmt"
+
+	"SpaceBookProject/internal/domain"
+)
+
+const (
+	margin   = 50.0
+	right    = PageWidth - margin
+	lineStep = 14.0
+)
+
+// RenderInvoice печатает счёт, pro-forma или credit note на одной странице A4.
+func RenderInvoice(inv *domain.Invoice) []byte {
+	title := "Invoice"
+	switch inv.Kind {
+	case domain.InvoiceKindCreditNote:
+		title = "Credit note"
+	case domain.InvoiceKindProforma:
+		title = "Pro forma invoice"
+	}
+	doc := New(fmt.Sprintf("%s %s", title, inv.Number))
+	p := doc.AddPage()
+
+	y := PageHeight - margin - 10
+	p.Text(margin, y, Bold, 20, title)
+	p.TextRight(right, y, Bold, 12, inv.Number)
+	y -= 2 * lineStep
+	p.Text(margin, y, Regular, 10, "Issued: "+inv.IssuedAt.UTC().Format("2006-01-02"))
+	p.TextRight(right, y, Regular, 10, fmt.Sprintf("Booking #%d", inv.BookingID))
+	y -= 3 * lineStep
+
+	p.Text(margin, y, Bold, 11, "From")
+	p.Text(PageWidth/2, y, Bold, 11, "Bill to")
+	y -= lineStep
+	issuer, customer := partyLines(inv.Issuer), partyLines(inv.Customer)
+	for i := 0; i < max(len(issuer), len(customer)); i++ {
+		if i < len(issuer) {
+			p.Text(margin, y, Regular, 10, issuer[i])
+		}
+		if i < len(customer) {
+			p.Text(PageWidth/2, y, Regular, 10, customer[i])
+		}
+		y -= lineStep
+	}
+	y -= 2 * lineStep
+
+	const qtyRight, unitRight = right - 190, right - 95
+	p.Text(margin, y, Bold, 10, "Description")
+	p.TextRight(qtyRight, y, Bold, 10, "Qty")
+	p.TextRight(unitRight, y, Bold, 10, "Unit price")
+	p.TextRight(right, y, Bold, 10, "Amount")
+	y -= 6
+	p.Line(margin, y, right, y, 0.8)
+	y -= lineStep
+	for _, l := range inv.Lines {
+		p.Text(margin, y, Regular, 10, fit(l.Description, qtyRight-margin-40, 10))
+		p.TextRight(qtyRight, y, Regular, 10, fmt.Sprintf("%d", l.Quantity))
+		p.TextRight(unitRight, y, Regular, 10, domain.FormatAmount(l.UnitAmount))
+		p.TextRight(right, y, Regular, 10, domain.FormatAmount(l.Amount))
+		y -= lineStep
+	}
+	p.Line(margin, y+4, right, y+4, 0.5)
+	y -= lineStep
+
+	total := func(label string, amount int64, font Font) {
+		p.TextRight(unitRight, y, font, 10, label)
+		p.TextRight(right, y, font, 10, domain.FormatAmount(amount)+" "+inv.Currency)
+		y -= lineStep
+	}
+	total("Subtotal", inv.Subtotal, Regular)
+	for _, t := range inv.Taxes {
+		total(fmt.Sprintf("%s %d%% included", t.Name, t.Percent), t.Amount, Regular)
+	}
+	total("Total", inv.Total, Bold)
+
+	switch inv.Kind {
+	case domain.InvoiceKindCreditNote:
+		y -= lineStep
+		p.Text(margin, y, Regular, 9, "This credit note reduces the amount due under the referenced invoice.")
+	case domain.InvoiceKindProforma:
+		y -= lineStep
+		p.Text(margin, y, Regular, 9, "This pro forma is not a tax invoice; the amount may change until the booking is confirmed.")
+	}
+	p.Text(margin, margin, Regular, 8, "Issued electronically by SpaceBook. Amounts include taxes.")
+
+	return doc.Bytes()
+}
+
+func partyLines(p domain.InvoiceParty) []string {
+	var lines []string
+	for _, s := range []string{p.Name, p.Email, p.Phone} {
+		if s != "" {
+			lines = append(lines, s)
+		}
+	}
+	return lines
+}
+
+// fit обрезает строку до ширины width, добавляя многоточие.
+func fit(s string, width, size float64) string {
+	if TextWidth(s, size) <= width {
+		return s
+	}
+	r := []rune(s)
+	for len(r) > 0 && TextWidth(string(r)+"...", size) > width {
+		r = r[:len(r)-1]
+	}
+	return string(r) + "..."
+}
@@ -0,0 +1,186 @@
+// Package pdf — минимальный генератор PDF на стандартной библиотеке:
+// страницы A4 с текстом встроенными шрифтами Helvetica и линиями.
+// Шрифты не встраиваются, поэтому доступны только символы WinAnsi;
+// кириллица транслитерируется, остальное заменяется на "?".
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+const (
+	PageWidth  = 595.28
+	PageHeight = 841.89
+)
+
+type Font int
+
+const (
+	Regular Font = iota
+	Bold
+)
+
+var fontNames = [...]string{Regular: "F1", Bold: "F2"}
+
+type Document struct {
+	pages []*Page
+	title string
+}
+
+type Page struct {
+	content bytes.Buffer
+}
+
+func New(title string) *Document {
+	return &Document{title: title}
+}
+
+func (d *Document) AddPage() *Page {
+	p := &Page{}
+	d.pages = append(d.pages, p)
+	return p
+}
+
+// Text пишет строку с левым нижним углом в (x, y); начало координат —
+// левый нижний угол страницы.
+func (p *Page) Text(x, y float64, font Font, size float64, s string) {
+	fmt.Fprintf(&p.content, "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n",
+		fontNames[font], size, x, y, escape(encode(s)))
+}
+
+// TextRight пишет строку, выровненную по правому краю right.
+func (p *Page) TextRight(right, y float64, font Font, size float64, s string) {
+	p.Text(right-TextWidth(s, size), y, font, size, s)
+}
+
+func (p *Page) Line(x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(&p.content, "%.2f w %.2f %.2f m %.2f %.2f l S\n", width, x1, y1, x2, y2)
+}
+
+// Bytes собирает документ: каталог, дерево страниц, шрифты, страницы с
+// содержимым и таблицу перекрёстных ссылок.
+func (d *Document) Bytes() []byte {
+	var buf bytes.Buffer
+	var offsets []int
+	obj := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// 1 — каталог, 2 — страницы, 3 и 4 — шрифты, 5 — сведения,
+	// дальше по два объекта на страницу: сама страница и её содержимое
+	const firstPage = 6
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+
+	obj("<< /Type /Catalog /Pages 2 0 R >>")
+	obj(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	obj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	obj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	obj(fmt.Sprintf("<< /Title (%s) /Producer (SpaceBook) >>", escape(encode(d.title))))
+
+	for i, p := range d.pages {
+		obj(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] "+
+			"/Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			PageWidth, PageHeight, firstPage+2*i+1))
+		obj(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", p.content.Len(), p.content.String()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R /Info 5 0 R >>\nstartxref\n%d\n%%%%EOF\n",
+		len(offsets)+1, xref)
+	return buf.Bytes()
+}
+
+// TextWidth — ширина строки шрифтом Helvetica размера size в пунктах.
+func TextWidth(s string, size float64) float64 {
+	var w int
+	for _, c := range encode(s) {
+		if c >= 32 && int(c-32) < len(helveticaWidths) {
+			w += helveticaWidths[c-32]
+		} else {
+			w += 556
+		}
+	}
+	return float64(w) * size / 1000
+}
+
+// helveticaWidths — ширины символов 32–126 Helvetica в тысячных кегля.
+var helveticaWidths = [...]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+func escape(b []byte) string {
+	var sb strings.Builder
+	for _, c := range b {
+		switch c {
+		case '(', ')', '\\':
+			sb.WriteByte('\\')
+			sb.WriteByte(c)
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
+
+// encode переводит строку в WinAnsi: Latin-1 остаётся как есть,
+// кириллица транслитерируется, прочее становится "?".
+func encode(s string) []byte {
+	out := make([]byte, 0, len(s))
+	for _, r := range s {
+		switch {
+		case r == '\n' || r == '\t':
+			out = append(out, ' ')
+		case r >= 32 && r < 127, r >= 0xA0 && r <= 0xFF:
+			out = append(out, byte(r))
+		case r == '–' || r == '—':
+			out = append(out, '-')
+		case r == '«' || r == '»':
+			out = append(out, '"')
+		default:
+			if t, ok := translit[r]; ok {
+				out = append(out, t...)
+			} else {
+				out = append(out, '?')
+			}
+		}
+	}
+	return out
+}
+
+var translit = func() map[rune]string {
+	lower := map[rune]string{
+		'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh",
+		'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+		'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts",
+		'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu",
+		'я': "ya", 'ә': "a", 'ғ': "g", 'қ': "q", 'ң': "n", 'ө': "o", 'ұ': "u", 'ү': "u",
+		'һ': "h", 'і': "i",
+	}
+	m := make(map[rune]string, 2*len(lower))
+	for r, t := range lower {
+		m[r] = t
+		upper := []rune(strings.ToUpper(string(r)))[0]
+		if t != "" {
+			t = strings.ToUpper(t[:1]) + t[1:]
+		}
+		m[upper] = t
+	}
+	return m
+}()
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"SpaceBookProject/internal/domain"
+)
+
+var ErrBlackoutNotFound = errors.New("blackout not found")
+
+type BlackoutRepository struct {
+	db *sql.DB
+}
+
+func NewBlackoutRepository(db *sql.DB) *BlackoutRepository {
+	return &BlackoutRepository{db: db}
+}
+
+func (r *BlackoutRepository) Create(b *domain.Blackout) error {
+	const q = `
+		INSERT INTO space_blackouts (space_id, starts_at, ends_at, reason, created_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		RETURNING id, created_at`
+
+	return r.db.QueryRow(q, b.SpaceID, b.StartsAt, b.EndsAt, b.Reason).Scan(&b.ID, &b.CreatedAt)
+}
+
+// ListInRange возвращает закрытые периоды помещения, пересекающиеся с [from, to).
+func (r *BlackoutRepository) ListInRange(spaceID int, from, to time.Time) ([]domain.Blackout, error) {
+	const q = `
+        SELECT id, space_id, starts_at, ends_at, reason, created_at
+        FROM space_blackouts
+        WHERE space_id = $1
+          AND tstzrange(starts_at, ends_at, '[)') && tstzrange($2, $3, '[)')
+        ORDER BY starts_at, id`
+
+	rows, err := r.db.Query(q, spaceID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []domain.Blackout
+	for rows.Next() {
+		var b domain.Blackout
+		if err := rows.Scan(&b.ID, &b.SpaceID, &b.StartsAt, &b.EndsAt, &b.Reason, &b.CreatedAt); err != nil {
+			return nil, err
+		}
+		res = append(res, b)
+	}
+	return res, rows.Err()
+}
+
+func (r *BlackoutRepository) ListBySpace(spaceID int) ([]domain.Blackout, error) {
+	const q = `
+        SELECT id, space_id, starts_at, ends_at, reason, created_at
+        FROM space_blackouts
+        WHERE space_id = $1
+        ORDER BY starts_at, id`
+
+	rows, err := r.db.Query(q, spaceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []domain.Blackout
+	for rows.Next() {
+		var b domain.Blackout
+		if err := rows.Scan(&b.ID, &b.SpaceID, &b.StartsAt, &b.EndsAt, &b.Reason, &b.CreatedAt); err != nil {
+			return nil, err
+		}
+		res = append(res, b)
+	}
+	return res, rows.Err()
+}
+
+func (r *BlackoutRepository) HasOverlap(spaceID int, from, to time.Time) (bool, error) {
+	const q = `
+        SELECT EXISTS (
+            SELECT 1
+            FROM space_blackouts
+            WHERE space_id = $1
+              AND tstzrange(starts_at, ends_at, '[)') && tstzrange($2, $3, '[)')
+        )`
+
+	var exists bool
+	if err := r.db.QueryRow(q, spaceID, from, to).Scan(&exists); err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+
+func (r *BlackoutRepository) Delete(id, spaceID int) error {
+	const q = `DELETE FROM space_blackouts WHERE id = $1 AND space_id = $2`
+
+	res, err := r.db.Exec(q, id, spaceID)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrBlackoutNotFound
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"SpaceBookProject/internal/domain"
+)
+
+var (
+	ErrChangeRequestNotFound = errors.New("change request not found")
+	ErrChangeRequestDecided  = errors.New("change request was already decided")
+)
+
+const changeRequestColumns = `id, booking_id, date_from, date_to, status, reason, requested_by, decided_by, decided_at, created_at`
+
+type BookingChangeRepository struct {
+	db *sql.DB
+}
+
+func NewBookingChangeRepository(db *sql.DB) *BookingChangeRepository {
+	return &BookingChangeRepository{db: db}
+}
+
+func scanChangeRequest(row scanner) (*domain.BookingChangeRequest, error) {
+	cr := &domain.BookingChangeRequest{}
+	err := row.Scan(
+		&cr.ID, &cr.BookingID, &cr.DateFrom, &cr.DateTo, &cr.Status, &cr.Reason,
+		&cr.RequestedBy, &cr.DecidedBy, &cr.DecidedAt, &cr.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return cr, nil
+}
+
+// Create сохраняет запрос на перенос, заменяя неотвеченный предыдущий.
+func (r *BookingChangeRepository) Create(cr *domain.BookingChangeRequest) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	const supersedeQ = `
+		UPDATE booking_change_requests
+		SET status = 'superseded', decided_at = NOW()
+		WHERE booking_id = $1 AND status = 'pending'`
+
+	if _, err := tx.Exec(supersedeQ, cr.BookingID); err != nil {
+		return err
+	}
+
+	const insertQ = `
+		INSERT INTO booking_change_requests (booking_id, date_from, date_to, reason, requested_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		RETURNING id, status, created_at`
+
+	err = tx.QueryRow(insertQ, cr.BookingID, cr.DateFrom, cr.DateTo, cr.Reason, cr.RequestedBy).
+		Scan(&cr.ID, &cr.Status, &cr.CreatedAt)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *BookingChangeRepository) GetByID(bookingID int, id int64) (*domain.BookingChangeRequest, error) {
+	const q = `
+		SELECT ` + changeRequestColumns + `
+		FROM booking_change_requests
+		WHERE id = $1 AND booking_id = $2`
+
+	cr, err := scanChangeRequest(r.db.QueryRow(q, id, bookingID))
+	if err == sql.ErrNoRows {
+		return nil, ErrChangeRequestNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return cr, nil
+}
+
+func (r *BookingChangeRepository) ListByBooking(bookingID int) ([]domain.BookingChangeRequest, error) {
+	const q = `
+		SELECT ` + changeRequestColumns + `
+		FROM booking_change_requests
+		WHERE booking_id = $1
+		ORDER BY created_at DESC, id DESC`
+
+	rows, err := r.db.Query(q, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []domain.BookingChangeRequest
+	for rows.Next() {
+		cr, err := scanChangeRequest(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *cr)
+	}
+	return result, rows.Err()
+}
+
+// Approve переносит бронь на даты из запроса по стоимости price в одной
+// транзакции: запрос и бронь блокируются, check проверяет права и статус
+// брони, пересечение ищется среди других одобренных броней. Гонку с чужим
+// одобрением разрешает constraint bookings_no_approved_overlap.
+func (r *BookingChangeRepository) Approve(
+	bookingID int,
+	id int64,
+	deciderID int,
+	price *domain.Money,
+	check func(b *domain.Booking) error,
+) (*domain.Booking, *domain.BookingChangeRequest, error) {
+	var b *domain.Booking
+	cr, err := r.decide(bookingID, id, deciderID, domain.BookingChangeApproved, func(tx *sql.Tx, cr *domain.BookingChangeRequest) error {
+		var err error
+		if b, err = lockBooking(tx, bookingID, check); err != nil {
+			return err
+		}
+
+		overlap, err := hasApprovedOverlap(tx, b.SpaceID, cr.DateFrom, cr.DateTo, &b.ID)
+		if err != nil {
+			return err
+		}
+		if overlap {
+			return ErrOverlappingBooking
+		}
+
+		if err := setBookingDates(tx, b, cr.DateFrom, cr.DateTo, price); err != nil {
+			return err
+		}
+		return enqueueEvent(tx, bookingEvent(domain.BookingEventModified, b))
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return b, cr, nil
+}
+
+func (r *BookingChangeRepository) Reject(
+	bookingID int,
+	id int64,
+	deciderID int,
+	check func(b *domain.Booking) error,
+) (*domain.BookingChangeRequest, error) {
+	return r.decide(bookingID, id, deciderID, domain.BookingChangeRejected, func(tx *sql.Tx, _ *domain.BookingChangeRequest) error {
+		_, err := lockBooking(tx, bookingID, check)
+		return err
+	})
+}
+
+// decide блокирует неотвеченный запрос, выполняет apply и фиксирует решение.
+func (r *BookingChangeRepository) decide(
+	bookingID int,
+	id int64,
+	deciderID int,
+	status domain.BookingChangeStatus,
+	apply func(tx *sql.Tx, cr *domain.BookingChangeRequest) error,
+) (*domain.BookingChangeRequest, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	const selectQ = `
+		SELECT ` + changeRequestColumns + `
+		FROM booking_change_requests
+		WHERE id = $1 AND booking_id = $2
+		FOR UPDATE`
+
+	cr, err := scanChangeRequest(tx.QueryRow(selectQ, id, bookingID))
+	if err == sql.ErrNoRows {
+		return nil, ErrChangeRequestNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if cr.Status != domain.BookingChangePending {
+		return nil, ErrChangeRequestDecided
+	}
+
+	if err := apply(tx, cr); err != nil {
+		return nil, err
+	}
+
+	const decideQ = `
+		UPDATE booking_change_requests
+		SET status = $1, decided_by = $2, decided_at = NOW()
+		WHERE id = $3
+		RETURNING status, decided_by, decided_at`
+
+	if err := tx.QueryRow(decideQ, status, deciderID, id).Scan(&cr.Status, &cr.DecidedBy, &cr.DecidedAt); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return cr, nil
+}
@@ -6,12 +6,18 @@ import (
 	"time"
 
 	"SpaceBookProject/internal/domain"
+
+	"github.com/lib/pq"
 )
 
 var (
-	ErrBookingNotFound = errors.New("booking not found")
+	ErrBookingNotFound      = errors.New("booking not found")
+	ErrOverlappingBooking   = errors.New("overlapping approved booking")
+	ErrCancellationNotFound = errors.New("booking has no cancellation record")
 )
 
+const bookingColumns = `id, space_id, tenant_id, organization_id, series_id, date_from, date_to, respond_by, status, price_amount, price_currency, created_at, updated_at`
+
 type BookingRepository struct {
 	db *sql.DB
 }
@@ -20,37 +26,150 @@ func NewBookingRepository(db *sql.DB) *BookingRepository {
 	return &BookingRepository{db: db}
 }
 
+func scanBooking(row scanner) (*domain.Booking, error) {
+	b := &domain.Booking{}
+	var (
+		amount   sql.NullInt64
+		currency sql.NullString
+	)
+	err := row.Scan(
+		&b.ID, &b.SpaceID, &b.TenantID, &b.OrganizationID, &b.SeriesID,
+		&b.DateFrom, &b.DateTo, &b.RespondBy, &b.Status,
+		&amount, &currency,
+		&b.CreatedAt, &b.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if amount.Valid {
+		b.Price = &domain.Money{Amount: amount.Int64, Currency: currency.String}
+	}
+	return b, nil
+}
+
+func scanBookings(rows *sql.Rows) ([]domain.Booking, error) {
+	defer rows.Close()
+
+	var res []domain.Booking
+	for rows.Next() {
+		b, err := scanBooking(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, *b)
+	}
+	return res, rows.Err()
+}
+
+// Create сохраняет бронь, первую запись её истории и событие created
+// (или company_approval_requested для заявки на согласовании в компании)
+// в одной транзакции.
 func (r *BookingRepository) Create(b *domain.Booking) error {
+	return r.create(b, nil)
+}
+
+// CreateInstant сохраняет заявку и в той же транзакции одобряет её обычным
+// переходом: арендатор получает и created, и approved, а пересечение
+// с одобренной бронью возвращается как ErrOverlappingBooking и не оставляет
+// заявки.
+func (r *BookingRepository) CreateInstant(b *domain.Booking) error {
+	return r.create(b, &domain.BookingTransition{
+		To:     domain.BookingStatusApproved,
+		Reason: "instant booking",
+	})
+}
+
+func (r *BookingRepository) create(b *domain.Booking, then *domain.BookingTransition) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := insertBooking(tx, b); err != nil {
+		return err
+	}
+
+	if then != nil {
+		approved, err := transitionBooking(tx, b.ID, *then, nil)
+		if err != nil {
+			return err
+		}
+		*b = *approved
+	}
+
+	return tx.Commit()
+}
+
+// insertBooking вставляет бронь вместе с первой записью истории и событием.
+func insertBooking(tx *sql.Tx, b *domain.Booking) error {
+	// срок ответа отсчитывается, только когда заявка попадает к владельцу
 	const query = `
-		INSERT INTO bookings (space_id, tenant_id, date_from, date_to, status, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
-		RETURNING id, status, created_at, updated_at;
+		INSERT INTO bookings (space_id, tenant_id, organization_id, series_id, date_from, date_to, status, respond_by,
+		                      price_amount, price_currency, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7,
+		        CASE WHEN $7 = 'pending' THEN (
+		            SELECT LEAST(NOW() + make_interval(hours => response_hours), $5)
+		            FROM spaces WHERE id = $1)
+		        END,
+		        $8, $9, NOW(), NOW())
+		RETURNING id, status, respond_by, created_at, updated_at;
 	`
 
-	err := r.db.QueryRow(
+	amount, currency := priceArgs(b.Price)
+	err := tx.QueryRow(
 		query,
 		b.SpaceID,
 		b.TenantID,
+		b.OrganizationID,
+		b.SeriesID,
 		b.DateFrom,
 		b.DateTo,
 		b.Status,
-	).Scan(&b.ID, &b.Status, &b.CreatedAt, &b.UpdatedAt)
+		amount,
+		currency,
+	).Scan(&b.ID, &b.Status, &b.RespondBy, &b.CreatedAt, &b.UpdatedAt)
+	if err != nil {
+		return err
+	}
 
-	return err
+	initial := domain.BookingTransition{To: b.Status, ActorID: &b.TenantID}
+	if err := recordStatusChange(tx, b.ID, nil, initial); err != nil {
+		return err
+	}
+
+	event := domain.BookingEventCreated
+	if b.Status == domain.BookingStatusPendingCompany {
+		event = domain.BookingEventCompanyApprovalRequested
+	}
+	return enqueueEvent(tx, bookingEvent(event, b))
+}
+
+// priceArgs раскладывает необязательную стоимость на параметры запроса.
+func priceArgs(price *domain.Money) (*int64, *string) {
+	if price == nil {
+		return nil, nil
+	}
+	return &price.Amount, &price.Currency
+}
+
+func bookingEvent(t domain.BookingEventType, b *domain.Booking) domain.BookingEvent {
+	return domain.BookingEvent{
+		Type:      t,
+		BookingID: b.ID,
+		SpaceID:   b.SpaceID,
+		TenantID:  b.TenantID,
+		At:        time.Now(),
+	}
 }
 
 func (r *BookingRepository) GetByID(id int) (*domain.Booking, error) {
 	const q = `
-        SELECT id, space_id, tenant_id, date_from, date_to, status, created_at, updated_at
+        SELECT ` + bookingColumns + `
         FROM bookings
         WHERE id = $1`
 
-	b := &domain.Booking{}
-	err := r.db.QueryRow(q, id).Scan(
-		&b.ID, &b.SpaceID, &b.TenantID,
-		&b.DateFrom, &b.DateTo, &b.Status,
-		&b.CreatedAt, &b.UpdatedAt,
-	)
+	b, err := scanBooking(r.db.QueryRow(q, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, ErrBookingNotFound
@@ -62,7 +181,7 @@ func (r *BookingRepository) GetByID(id int) (*domain.Booking, error) {
 
 func (r *BookingRepository) ListByTenant(tenantID int) ([]domain.Booking, error) {
 	const q = `
-        SELECT id, space_id, tenant_id, date_from, date_to, status, created_at, updated_at
+        SELECT ` + bookingColumns + `
         FROM bookings
         WHERE tenant_id = $1
         ORDER BY date_from DESC, id DESC`
@@ -71,87 +190,423 @@ func (r *BookingRepository) ListByTenant(tenantID int) ([]domain.Booking, error)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-
-	var res []domain.Booking
-	for rows.Next() {
-		var b domain.Booking
-		if err := rows.Scan(
-			&b.ID, &b.SpaceID, &b.TenantID,
-			&b.DateFrom, &b.DateTo, &b.Status,
-			&b.CreatedAt, &b.UpdatedAt,
-		); err != nil {
-			return nil, err
-		}
-		res = append(res, b)
-	}
-	return res, rows.Err()
+	return scanBookings(rows)
 }
 
+// ListByOwner возвращает брони помещений пользователя и помещений
+// организаций, в которых он состоит. Заявки, ещё не согласованные
+// компанией арендатора, владельцу не показываются.
 func (r *BookingRepository) ListByOwner(ownerID int) ([]domain.Booking, error) {
 	const q = `
-        SELECT b.id, b.space_id, b.tenant_id, b.date_from, b.date_to,
-               b.status, b.created_at, b.updated_at
+        SELECT b.id, b.space_id, b.tenant_id, b.organization_id, b.series_id, b.date_from, b.date_to,
+               b.respond_by, b.status, b.price_amount, b.price_currency, b.created_at, b.updated_at
         FROM bookings b
         JOIN spaces s ON s.id = b.space_id
-        WHERE s.owner_id = $1
+        WHERE (s.owner_id = $1
+           OR s.organization_id IN (
+               SELECT organization_id FROM organization_members WHERE user_id = $1))
+          AND b.status <> 'pending_company'
         ORDER BY b.date_from DESC, b.id DESC`
 
 	rows, err := r.db.Query(q, ownerID)
 	if err != nil {
 		return nil, err
 	}
+	return scanBookings(rows)
+}
+
+// ListByOrganization возвращает брони, сделанные от имени компании;
+// status, если задан, ограничивает выборку.
+func (r *BookingRepository) ListByOrganization(orgID int, status *domain.BookingStatus) ([]domain.Booking, error) {
+	q := `
+        SELECT ` + bookingColumns + `
+        FROM bookings
+        WHERE organization_id = $1`
+	args := []any{orgID}
+	if status != nil {
+		q += " AND status = $2"
+		args = append(args, *status)
+	}
+	q += " ORDER BY date_from DESC, id DESC"
+
+	rows, err := r.db.Query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	return scanBookings(rows)
+}
+
+// Transition переводит бронирование в статус t.To в одной транзакции:
+// строка блокируется через SELECT ... FOR UPDATE, check проверяет права
+// и условия вызывающего, а допустимость перехода и его событие определяет
+// таблица domain.TransitionEvent. Гонку двух одобрений пересекающихся
+// броней разрешает constraint bookings_no_approved_overlap, его нарушение
+// возвращается как ErrOverlappingBooking. Запись истории и событие
+// попадают в базу в той же транзакции.
+func (r *BookingRepository) Transition(
+	id int,
+	t domain.BookingTransition,
+	check func(b *domain.Booking) error,
+) (*domain.Booking, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	b, err := transitionBooking(tx, id, t, check)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+func transitionBooking(
+	tx *sql.Tx,
+	id int,
+	t domain.BookingTransition,
+	check func(b *domain.Booking) error,
+) (*domain.Booking, error) {
+	b, err := lockBooking(tx, id, check)
+	if err != nil {
+		return nil, err
+	}
+
+	event, err := domain.TransitionEvent(b.Status, t.To)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.To == domain.BookingStatusApproved {
+		overlap, err := hasApprovedOverlap(tx, b.SpaceID, b.DateFrom, b.DateTo, &b.ID)
+		if err != nil {
+			return nil, err
+		}
+		if overlap {
+			return nil, ErrOverlappingBooking
+		}
+	}
+
+	// заявка, согласованная компанией, получает срок ответа владельца
+	const updateQ = `
+        UPDATE bookings b
+        SET status = $1, updated_at = NOW(),
+            respond_by = CASE WHEN $1 = 'pending' THEN (
+                SELECT LEAST(NOW() + make_interval(hours => s.response_hours), b.date_from)
+                FROM spaces s WHERE s.id = b.space_id)
+            ELSE b.respond_by END
+        WHERE b.id = $2
+        RETURNING b.updated_at, b.respond_by`
+
+	if err := tx.QueryRow(updateQ, t.To, id).Scan(&b.UpdatedAt, &b.RespondBy); err != nil {
+		if isPQError(err, pqExclusionViolation) {
+			return nil, ErrOverlappingBooking
+		}
+		return nil, err
+	}
+	from := b.Status
+	b.Status = t.To
+
+	if err := recordStatusChange(tx, b.ID, &from, t); err != nil {
+		return nil, err
+	}
+
+	evt := bookingEvent(event, b)
+	if t.To == domain.BookingStatusCancelled && t.CancelledBy != "" {
+		if evt.Cancellation, err = recordCancellation(tx, b, t); err != nil {
+			return nil, err
+		}
+	}
+
+	if event != "" {
+		if err := enqueueEvent(tx, evt); err != nil {
+			return nil, err
+		}
+	}
+
+	return b, nil
+}
+
+// recordCancellation рассчитывает возврат по политике помещения, действующей
+// в момент отмены, и сохраняет его вместе с тем, кто отменил бронь.
+func recordCancellation(tx *sql.Tx, b *domain.Booking, t domain.BookingTransition) (*domain.Cancellation, error) {
+	var (
+		kind  domain.CancellationPolicyKind
+		tiers []byte
+	)
+	const policyQ = `SELECT cancellation_policy, cancellation_tiers FROM spaces WHERE id = $1`
+	if err := tx.QueryRow(policyQ, b.SpaceID).Scan(&kind, &tiers); err != nil {
+		return nil, err
+	}
+	policy, err := cancellationPolicy(kind, tiers)
+	if err != nil {
+		return nil, err
+	}
+
+	c := policy.Cancel(b, t.CancelledBy, time.Now())
+	var refund, fee, currency any
+	if c.Refund != nil {
+		refund, fee, currency = c.Refund.Amount, c.Fee.Amount, c.Refund.Currency
+	}
+
+	const q = `
+		INSERT INTO booking_cancellations (booking_id, cancelled_by, actor_id, policy, refund_percent,
+		                                   refund_amount, fee_amount, currency, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+
+	_, err = tx.Exec(q, b.ID, c.CancelledBy, t.ActorID, c.Policy, c.RefundPercent, refund, fee, currency, c.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// GetCancellation возвращает расчёт возврата, сохранённый при отмене брони.
+func (r *BookingRepository) GetCancellation(bookingID int) (*domain.Cancellation, error) {
+	const q = `
+        SELECT booking_id, cancelled_by, policy, refund_percent, refund_amount, fee_amount, currency, created_at
+        FROM booking_cancellations
+        WHERE booking_id = $1`
+
+	c := &domain.Cancellation{}
+	var (
+		refund, fee sql.NullInt64
+		currency    sql.NullString
+	)
+	err := r.db.QueryRow(q, bookingID).Scan(
+		&c.BookingID, &c.CancelledBy, &c.Policy, &c.RefundPercent, &refund, &fee, &currency, &c.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrCancellationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if refund.Valid {
+		c.Refund = &domain.Money{Amount: refund.Int64, Currency: currency.String}
+		c.Fee = &domain.Money{Amount: fee.Int64, Currency: currency.String}
+	}
+	return c, nil
+}
+
+// lockBooking читает бронь с блокировкой строки до конца транзакции
+// и передаёт её check, если он задан.
+func lockBooking(tx *sql.Tx, id int, check func(b *domain.Booking) error) (*domain.Booking, error) {
+	const q = `
+        SELECT ` + bookingColumns + `
+        FROM bookings
+        WHERE id = $1
+        FOR UPDATE`
+
+	b, err := scanBooking(tx.QueryRow(q, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrBookingNotFound
+		}
+		return nil, err
+	}
+
+	if check != nil {
+		if err := check(b); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// Reschedule переносит ещё не одобренную бронь на новые даты по новой
+// стоимости price; check проверяет, что перенос всё ещё допустим. Срок
+// ответа владельца не может оказаться позже нового начала. Владельцу
+// уходит событие modified, если заявка уже у него.
+func (r *BookingRepository) Reschedule(id int, from, to time.Time, price *domain.Money, check func(b *domain.Booking) error) (*domain.Booking, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	b, err := lockBooking(tx, id, check)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := setBookingDates(tx, b, from, to, price); err != nil {
+		return nil, err
+	}
+
+	if b.Status == domain.BookingStatusPending {
+		if err := enqueueEvent(tx, bookingEvent(domain.BookingEventModified, b)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// setBookingDates переносит бронь; стоимость пересчитывается вместе с датами.
+func setBookingDates(tx *sql.Tx, b *domain.Booking, from, to time.Time, price *domain.Money) error {
+	const q = `
+        UPDATE bookings
+        SET date_from = $1, date_to = $2,
+            respond_by = CASE WHEN respond_by IS NULL THEN NULL ELSE LEAST(respond_by, $1) END,
+            price_amount = $3, price_currency = $4, updated_at = NOW()
+        WHERE id = $5
+        RETURNING respond_by, updated_at`
+
+	amount, currency := priceArgs(price)
+	if err := tx.QueryRow(q, from, to, amount, currency, b.ID).Scan(&b.RespondBy, &b.UpdatedAt); err != nil {
+		if isPQError(err, pqExclusionViolation) {
+			return ErrOverlappingBooking
+		}
+		return err
+	}
+	b.DateFrom, b.DateTo, b.Price = from, to, price
+	return nil
+}
+
+// recordStatusChange пишет строку истории; from пуст для первой записи.
+func recordStatusChange(tx execer, bookingID int, from *domain.BookingStatus, t domain.BookingTransition) error {
+	const q = `
+        INSERT INTO booking_status_history (booking_id, from_status, to_status, actor_id, reason, created_at)
+        VALUES ($1, $2, $3, $4, NULLIF($5, ''), NOW())`
+
+	_, err := tx.Exec(q, bookingID, from, t.To, t.ActorID, t.Reason)
+	return err
+}
+
+// History возвращает смены статусов брони в хронологическом порядке.
+func (r *BookingRepository) History(bookingID int) ([]domain.BookingStatusChange, error) {
+	const q = `
+        SELECT id, booking_id, from_status, to_status, actor_id, reason, created_at
+        FROM booking_status_history
+        WHERE booking_id = $1
+        ORDER BY created_at, id`
+
+	rows, err := r.db.Query(q, bookingID)
+	if err != nil {
+		return nil, err
+	}
 	defer rows.Close()
 
-	var res []domain.Booking
+	var res []domain.BookingStatusChange
 	for rows.Next() {
-		var b domain.Booking
-		if err := rows.Scan(
-			&b.ID, &b.SpaceID, &b.TenantID,
-			&b.DateFrom, &b.DateTo, &b.Status,
-			&b.CreatedAt, &b.UpdatedAt,
-		); err != nil {
+		var c domain.BookingStatusChange
+		if err := rows.Scan(&c.ID, &c.BookingID, &c.FromStatus, &c.ToStatus, &c.ActorID, &c.Reason, &c.CreatedAt); err != nil {
 			return nil, err
 		}
-		res = append(res, b)
+		res = append(res, c)
 	}
 	return res, rows.Err()
 }
 
-func (r *BookingRepository) UpdateStatus(id int, status domain.BookingStatus) error {
-	const q = `
-        UPDATE bookings
-        SET status = $1, updated_at = NOW()
-        WHERE id = $2`
+const (
+	expiryReasonNoResponse = "owner did not respond in time"
+	expiryReasonStarted    = "booking start passed before a decision"
+)
 
-	res, err := r.db.Exec(q, status, id)
+// ExpireDue переводит в expired заявки, на которые владелец не ответил
+// в срок или чьё начало уже наступило, — не больше limit за вызов.
+// Advisory-блокировка транзакции гарантирует, что проход выполняет одна
+// реплика: остальные сразу получают пустой результат.
+func (r *BookingRepository) ExpireDue(limit int) ([]domain.Booking, error) {
+	tx, err := r.db.Begin()
 	if err != nil {
-		return err
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var locked bool
+	if err := tx.QueryRow(`SELECT pg_try_advisory_xact_lock(hashtext('booking_expiry'))`).Scan(&locked); err != nil {
+		return nil, err
 	}
-	n, err := res.RowsAffected()
+	if !locked {
+		return nil, nil
+	}
+
+	const dueQ = `
+        SELECT id, respond_by IS NOT NULL AND respond_by <= NOW()
+        FROM bookings
+        WHERE status IN ('pending_company', 'pending')
+          AND (respond_by <= NOW() OR date_from <= NOW())
+        ORDER BY id
+        LIMIT $1
+        FOR UPDATE SKIP LOCKED`
+
+	rows, err := tx.Query(dueQ, limit)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	reasons := make(map[int]string)
+	var ids []int
+	for rows.Next() {
+		var (
+			id       int
+			timedOut bool
+		)
+		if err := rows.Scan(&id, &timedOut); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ids = append(ids, id)
+		reasons[id] = expiryReasonStarted
+		if timedOut {
+			reasons[id] = expiryReasonNoResponse
+		}
 	}
-	if n == 0 {
-		return ErrBookingNotFound
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
-	return nil
+
+	expired := make([]domain.Booking, 0, len(ids))
+	for _, id := range ids {
+		b, err := transitionBooking(tx, id, domain.BookingTransition{
+			To:     domain.BookingStatusExpired,
+			Reason: reasons[id],
+		}, nil)
+		if err != nil {
+			return nil, err
+		}
+		expired = append(expired, *b)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return expired, nil
 }
 
 func (r *BookingRepository) HasApprovedOverlap(
 	spaceID int,
 	from, to time.Time,
 	excludeID *int,
+) (bool, error) {
+	return hasApprovedOverlap(r.db, spaceID, from, to, excludeID)
+}
+
+func hasApprovedOverlap(
+	q queryRower,
+	spaceID int,
+	from, to time.Time,
+	excludeID *int,
 ) (bool, error) {
 	query := `
         SELECT EXISTS (
             SELECT 1
             FROM bookings
             WHERE space_id = $1
-              AND status = 'approved'
-              -- нет пересечения = (date_to <= from) OR (date_from >= to)
-              -- нам нужны ИМЕННО пересекающиеся, поэтому NOT (...)
-              AND NOT (date_to <= $2 OR date_from >= $3)
+              AND status IN ('approved', 'confirmed', 'checked_in', 'completed')
+              -- полуоткрытые интервалы [from, to): брони "встык" не пересекаются,
+              -- то же выражение использует constraint bookings_no_approved_overlap
+              AND tstzrange(date_from, date_to, '[)') && tstzrange($2, $3, '[)')
     `
 	args := []any{spaceID, from, to}
 
@@ -163,8 +618,35 @@ func (r *BookingRepository) HasApprovedOverlap(
 	query += ")"
 
 	var exists bool
-	if err := r.db.QueryRow(query, args...).Scan(&exists); err != nil {
+	if err := q.QueryRow(query, args...).Scan(&exists); err != nil {
 		return false, err
 	}
 	return exists, nil
 }
+
+// ListInRange возвращает брони помещения с указанными статусами,
+// пересекающиеся с интервалом [from, to).
+func (r *BookingRepository) ListInRange(
+	spaceID int,
+	from, to time.Time,
+	statuses []domain.BookingStatus,
+) ([]domain.Booking, error) {
+	const q = `
+        SELECT ` + bookingColumns + `
+        FROM bookings
+        WHERE space_id = $1
+          AND status = ANY($4)
+          AND tstzrange(date_from, date_to, '[)') && tstzrange($2, $3, '[)')
+        ORDER BY date_from, id`
+
+	names := make([]string, len(statuses))
+	for i, st := range statuses {
+		names[i] = string(st)
+	}
+
+	rows, err := r.db.Query(q, spaceID, from, to, pq.Array(names))
+	if err != nil {
+		return nil, err
+	}
+	return scanBookings(rows)
+}
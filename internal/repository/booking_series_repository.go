@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"SpaceBookProject/internal/domain"
+
+	"github.com/lib/pq"
+)
+
+var ErrSeriesNotFound = errors.New("booking series not found")
+
+type BookingSeriesRepository struct {
+	db *sql.DB
+}
+
+func NewBookingSeriesRepository(db *sql.DB) *BookingSeriesRepository {
+	return &BookingSeriesRepository{db: db}
+}
+
+// Create сохраняет серию и все её вхождения в одной транзакции: серия
+// либо создаётся целиком, либо не создаётся вовсе.
+func (r *BookingSeriesRepository) Create(series *domain.BookingSeries, bookings []domain.Booking) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	const q = `
+		INSERT INTO booking_series (space_id, tenant_id, organization_id, rrule, exceptions, created_at)
+		VALUES ($1, $2, $3, $4, $5::date[], NOW())
+		RETURNING id, created_at`
+
+	err = tx.QueryRow(q, series.SpaceID, series.TenantID, series.OrganizationID, series.RRule, pq.Array(series.Exceptions)).
+		Scan(&series.ID, &series.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	for i := range bookings {
+		bookings[i].SeriesID = &series.ID
+		if err := insertBooking(tx, &bookings[i]); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	series.Bookings = bookings
+	return nil
+}
+
+func (r *BookingSeriesRepository) GetByID(id int) (*domain.BookingSeries, error) {
+	const q = `
+		SELECT id, space_id, tenant_id, organization_id, rrule, exceptions::text[], created_at
+		FROM booking_series
+		WHERE id = $1`
+
+	s := &domain.BookingSeries{}
+	var exceptions pq.StringArray
+	err := r.db.QueryRow(q, id).Scan(&s.ID, &s.SpaceID, &s.TenantID, &s.OrganizationID, &s.RRule, &exceptions, &s.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrSeriesNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.Exceptions = []string(exceptions)
+	return s, nil
+}
+
+func (r *BookingSeriesRepository) ListBookings(seriesID int) ([]domain.Booking, error) {
+	const q = `
+        SELECT ` + bookingColumns + `
+        FROM bookings
+        WHERE series_id = $1
+        ORDER BY date_from, id`
+
+	rows, err := r.db.Query(q, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	return scanBookings(rows)
+}
+
+// Transition переводит вхождения серии в статусах from (и, если задан
+// since, начинающиеся не раньше него) по общим правилам transitionBooking.
+// Все вхождения меняются в одной транзакции: одно пересечение или отказ
+// check отменяет всё решение.
+func (r *BookingSeriesRepository) Transition(
+	seriesID int,
+	from []domain.BookingStatus,
+	since *time.Time,
+	t domain.BookingTransition,
+	check func(b *domain.Booking) error,
+) ([]domain.Booking, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	names := make([]string, len(from))
+	for i, st := range from {
+		names[i] = string(st)
+	}
+
+	const q = `
+        SELECT id
+        FROM bookings
+        WHERE series_id = $1
+          AND status = ANY($2)
+          AND ($3::timestamptz IS NULL OR date_from >= $3)
+        ORDER BY date_from, id
+        FOR UPDATE`
+
+	rows, err := tx.Query(q, seriesID, pq.Array(names), since)
+	if err != nil {
+		return nil, err
+	}
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	changed := make([]domain.Booking, 0, len(ids))
+	for _, id := range ids {
+		b, err := transitionBooking(tx, id, t, check)
+		if err != nil {
+			return nil, err
+		}
+		changed = append(changed, *b)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return changed, nil
+}
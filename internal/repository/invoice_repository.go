@@ -0,0 +1,327 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"SpaceBookProject/internal/domain"
+)
+
+var ErrInvoiceNotFound = errors.New("invoice not found")
+
+const invoiceColumns = `id, kind, series, sequence, number, booking_id, payment_id, corrects_id,
+		issuer, customer, lines, taxes, subtotal, tax_total, total, currency, issued_at`
+
+// InvoiceRepository выставляет счета и credit notes. Документы не
+// меняются после выставления: возврат оформляется новым документом.
+type InvoiceRepository struct {
+	db  *sql.DB
+	tax domain.TaxRate
+}
+
+func NewInvoiceRepository(db *sql.DB, tax domain.TaxRate) *InvoiceRepository {
+	return &InvoiceRepository{db: db, tax: tax}
+}
+
+func scanInvoice(row scanner) (*domain.Invoice, error) {
+	inv := &domain.Invoice{}
+	var issuer, customer, lines, taxes []byte
+	err := row.Scan(
+		&inv.ID, &inv.Kind, &inv.Series, &inv.Sequence, &inv.Number, &inv.BookingID, &inv.PaymentID, &inv.CorrectsID,
+		&issuer, &customer, &lines, &taxes, &inv.Subtotal, &inv.TaxTotal, &inv.Total, &inv.Currency, &inv.IssuedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range []struct {
+		raw  []byte
+		dest any
+	}{{issuer, &inv.Issuer}, {customer, &inv.Customer}, {lines, &inv.Lines}, {taxes, &inv.Taxes}} {
+		if err := json.Unmarshal(f.raw, f.dest); err != nil {
+			return nil, err
+		}
+	}
+	return inv, nil
+}
+
+// Issue возвращает счёт брони, выставляя его при первом обращении.
+func (r *InvoiceRepository) Issue(bookingID int) (*domain.Invoice, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	inv, err := r.issueInvoice(tx, bookingID, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+func (r *InvoiceRepository) GetInvoice(bookingID int) (*domain.Invoice, error) {
+	const q = `SELECT ` + invoiceColumns + ` FROM invoices WHERE booking_id = $1 AND kind = 'invoice'`
+
+	inv, err := scanInvoice(r.db.QueryRow(q, bookingID))
+	if err == sql.ErrNoRows {
+		return nil, ErrInvoiceNotFound
+	}
+	return inv, err
+}
+
+func (r *InvoiceRepository) GetByID(id int) (*domain.Invoice, error) {
+	const q = `SELECT ` + invoiceColumns + ` FROM invoices WHERE id = $1`
+
+	inv, err := scanInvoice(r.db.QueryRow(q, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrInvoiceNotFound
+	}
+	return inv, err
+}
+
+// ListCreditNotes возвращает credit notes брони в порядке выставления.
+func (r *InvoiceRepository) ListCreditNotes(bookingID int) ([]domain.Invoice, error) {
+	const q = `
+		SELECT ` + invoiceColumns + `
+		FROM invoices
+		WHERE booking_id = $1 AND kind = 'credit_note'
+		ORDER BY id`
+
+	rows, err := r.db.Query(q, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []domain.Invoice
+	for rows.Next() {
+		inv, err := scanInvoice(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *inv)
+	}
+	return result, rows.Err()
+}
+
+// Preview собирает pro-forma счёт брони, который ещё может измениться:
+// без номера серии и без сохранения.
+func (r *InvoiceRepository) Preview(bookingID int) (*domain.Invoice, error) {
+	inv, err := r.draftInvoice(r.db, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	inv.Kind = domain.InvoiceKindProforma
+	inv.Number = domain.ProformaNumber(bookingID)
+	inv.IssuedAt = time.Now()
+	return inv, nil
+}
+
+// issueInvoice выставляет счёт на стоимость брони, если его ещё нет.
+// Строка брони блокируется, поэтому счёт у брони всегда один.
+func (r *InvoiceRepository) issueInvoice(tx *sql.Tx, bookingID int, paymentID *int) (*domain.Invoice, error) {
+	var locked int
+	err := tx.QueryRow(`SELECT id FROM bookings WHERE id = $1 FOR UPDATE`, bookingID).Scan(&locked)
+	if err == sql.ErrNoRows {
+		return nil, ErrBookingNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	const existingQ = `SELECT ` + invoiceColumns + ` FROM invoices WHERE booking_id = $1 AND kind = 'invoice'`
+	inv, err := scanInvoice(tx.QueryRow(existingQ, bookingID))
+	if err == nil {
+		return inv, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	inv, err = r.draftInvoice(tx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	inv.PaymentID = paymentID
+	if err := insertInvoice(tx, inv, nil); err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+// draftInvoice собирает счёт по текущим датам и стоимости брони, не
+// присваивая ему номер.
+func (r *InvoiceRepository) draftInvoice(db queryRower, bookingID int) (*domain.Invoice, error) {
+	const q = `
+		SELECT b.date_from, b.date_to, b.price_amount, b.price_currency,
+		       s.title, s.owner_id, s.organization_id, s.phone, s.timezone, o.name,
+		       ou.first_name, ou.last_name, ou.email,
+		       tu.first_name, tu.last_name, tu.email, tu.phone
+		FROM bookings b
+		JOIN spaces s ON s.id = b.space_id
+		LEFT JOIN organizations o ON o.id = s.organization_id
+		JOIN users ou ON ou.id = s.owner_id
+		JOIN users tu ON tu.id = b.tenant_id
+		WHERE b.id = $1`
+
+	var (
+		from, to                             time.Time
+		amount                               sql.NullInt64
+		currency, orgName                    sql.NullString
+		title, phone, timezone               string
+		ownerID                              int
+		orgID                                *int
+		ownerFirst, ownerLast, ownerEmail    string
+		tenantFirst, tenantLast, tenantEmail string
+		tenantPhone                          string
+	)
+	err := db.QueryRow(q, bookingID).Scan(
+		&from, &to, &amount, &currency,
+		&title, &ownerID, &orgID, &phone, &timezone, &orgName,
+		&ownerFirst, &ownerLast, &ownerEmail,
+		&tenantFirst, &tenantLast, &tenantEmail, &tenantPhone,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrBookingNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !amount.Valid {
+		return nil, fmt.Errorf("booking %d has no price to invoice", bookingID)
+	}
+
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	const layout = "2006-01-02 15:04"
+
+	inv := &domain.Invoice{
+		Kind:      domain.InvoiceKindInvoice,
+		Series:    domain.InvoiceSeries(ownerID, orgID),
+		BookingID: bookingID,
+		Issuer: domain.InvoiceParty{
+			Name:  personName(ownerFirst, ownerLast),
+			Email: ownerEmail,
+			Phone: phone,
+		},
+		Customer: domain.InvoiceParty{
+			Name:  personName(tenantFirst, tenantLast),
+			Email: tenantEmail,
+			Phone: tenantPhone,
+		},
+		Lines: []domain.InvoiceLine{{
+			Description: fmt.Sprintf("Rental of %q, %s - %s (%s)",
+				title, from.In(loc).Format(layout), to.In(loc).Format(layout), loc),
+			Quantity:   1,
+			UnitAmount: amount.Int64,
+			Amount:     amount.Int64,
+		}},
+		Total:    amount.Int64,
+		Currency: currency.String,
+	}
+	if orgName.Valid {
+		inv.Issuer.Name = orgName.String
+	}
+	inv.ApplyTax(r.tax)
+	return inv, nil
+}
+
+// issueCreditNote оформляет возврат, доведший общую сумму возвратов
+// платежа с prevTotal до p.RefundedAmount, корректировкой к счёту брони.
+func (r *InvoiceRepository) issueCreditNote(tx *sql.Tx, p *domain.Payment, prevTotal int64) error {
+	refund := p.RefundedAmount - prevTotal
+	if refund <= 0 {
+		return nil
+	}
+	inv, err := r.issueInvoice(tx, p.BookingID, &p.ID)
+	if err != nil {
+		return err
+	}
+
+	note := &domain.Invoice{
+		Kind:       domain.InvoiceKindCreditNote,
+		Series:     inv.Series,
+		BookingID:  p.BookingID,
+		PaymentID:  &p.ID,
+		CorrectsID: &inv.ID,
+		Issuer:     inv.Issuer,
+		Customer:   inv.Customer,
+		Lines: []domain.InvoiceLine{{
+			Description: fmt.Sprintf("Refund to invoice %s", inv.Number),
+			Quantity:    1,
+			UnitAmount:  refund,
+			Amount:      refund,
+		}},
+		Total:    refund,
+		Currency: p.Currency,
+	}
+	note.ApplyTax(r.tax)
+
+	reference := fmt.Sprintf("payment-%d-refund-%d", p.ID, p.RefundedAmount)
+	return insertInvoice(tx, note, &reference)
+}
+
+// insertInvoice берёт следующий номер серии и сохраняет документ. Если
+// документ с таким reference уже выставлен, ничего не делает.
+func insertInvoice(tx *sql.Tx, inv *domain.Invoice, reference *string) error {
+	if reference != nil {
+		var exists bool
+		if err := tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM invoices WHERE reference = $1)`, *reference).Scan(&exists); err != nil {
+			return err
+		}
+		if exists {
+			return nil
+		}
+	}
+
+	const seqQ = `
+		INSERT INTO invoice_counters (series, kind, last_number)
+		VALUES ($1, $2, 1)
+		ON CONFLICT (series, kind) DO UPDATE SET last_number = invoice_counters.last_number + 1
+		RETURNING last_number`
+	if err := tx.QueryRow(seqQ, inv.Series, inv.Kind).Scan(&inv.Sequence); err != nil {
+		return err
+	}
+	inv.Number = domain.InvoiceNumber(inv.Kind, inv.Series, inv.Sequence)
+
+	issuer, err := json.Marshal(inv.Issuer)
+	if err != nil {
+		return err
+	}
+	customer, err := json.Marshal(inv.Customer)
+	if err != nil {
+		return err
+	}
+	lines, err := json.Marshal(inv.Lines)
+	if err != nil {
+		return err
+	}
+	taxes, err := json.Marshal(inv.Taxes)
+	if err != nil {
+		return err
+	}
+
+	const q = `
+		INSERT INTO invoices (kind, series, sequence, number, booking_id, payment_id, corrects_id, reference,
+		                      issuer, customer, lines, taxes, subtotal, tax_total, total, currency, issued_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, NOW())
+		RETURNING id, issued_at`
+
+	return tx.QueryRow(q,
+		inv.Kind, inv.Series, inv.Sequence, inv.Number, inv.BookingID, inv.PaymentID, inv.CorrectsID, reference,
+		issuer, customer, lines, taxes, inv.Subtotal, inv.TaxTotal, inv.Total, inv.Currency,
+	).Scan(&inv.ID, &inv.IssuedAt)
+}
+
+func personName(first, last string) string {
+	return strings.TrimSpace(first + " " + last)
+}
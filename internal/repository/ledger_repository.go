@@ -0,0 +1,577 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"SpaceBookProject/internal/domain"
+)
+
+var ErrPayoutNotFound = errors.New("payout not found")
+
+// LedgerRepository ведёт главную книгу по двойной записи. Балансы нигде
+// не хранятся: баланс счёта — сумма его проводок, поэтому книгу можно
+// сверить в любой момент, не доверяя никаким другим таблицам.
+type LedgerRepository struct {
+	db                *sql.DB
+	commissionPercent int
+}
+
+func NewLedgerRepository(db *sql.DB, commissionPercent int) *LedgerRepository {
+	return &LedgerRepository{db: db, commissionPercent: commissionPercent}
+}
+
+// ledgerTx — транзакция книги до записи. Reference уникален: повторная
+// запись той же операции ничего не меняет.
+type ledgerTx struct {
+	Kind        domain.LedgerTransactionKind
+	Reference   string
+	BookingID   *int
+	PaymentID   *int
+	Description string
+	Currency    string
+	Lines       []domain.LedgerLine
+}
+
+// postLedger записывает транзакцию с проводками. Несбалансированную
+// транзакцию отвергает ещё до базы, а база проверяет это повторно при
+// коммите. Возвращает 0, если транзакция с таким Reference уже есть.
+func postLedger(tx *sql.Tx, t ledgerTx) (int64, error) {
+	var sum int64
+	for _, l := range t.Lines {
+		sum += l.Amount
+	}
+	if sum != 0 {
+		return 0, fmt.Errorf("ledger transaction %s is not balanced: %d", t.Reference, sum)
+	}
+
+	const txQ = `
+		INSERT INTO ledger_transactions (kind, reference, booking_id, payment_id, description, created_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		ON CONFLICT (reference) DO NOTHING
+		RETURNING id`
+
+	var id int64
+	err := tx.QueryRow(txQ, t.Kind, t.Reference, t.BookingID, t.PaymentID, t.Description).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	const entryQ = `INSERT INTO ledger_entries (transaction_id, account_id, amount) VALUES ($1, $2, $3)`
+	for _, l := range t.Lines {
+		if l.Amount == 0 {
+			continue
+		}
+		accountID, err := ledgerAccount(tx, l.Kind, l.UserID, t.Currency)
+		if err != nil {
+			return 0, err
+		}
+		if _, err := tx.Exec(entryQ, id, accountID, l.Amount); err != nil {
+			return 0, err
+		}
+	}
+	return id, nil
+}
+
+// ledgerAccount возвращает счёт, заводя его при первой проводке.
+func ledgerAccount(tx *sql.Tx, kind domain.LedgerAccountKind, userID *int, currency string) (int, error) {
+	const q = `
+		INSERT INTO ledger_accounts (kind, user_id, currency, created_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (kind, COALESCE(user_id, 0), currency) DO UPDATE SET kind = EXCLUDED.kind
+		RETURNING id`
+
+	var id int
+	err := tx.QueryRow(q, kind, userID, currency).Scan(&id)
+	return id, err
+}
+
+// bookingParties возвращает владельца помещения и арендатора брони.
+func bookingParties(tx *sql.Tx, bookingID int) (ownerID, tenantID int, err error) {
+	const q = `
+		SELECT s.owner_id, b.tenant_id
+		FROM bookings b
+		JOIN spaces s ON s.id = b.space_id
+		WHERE b.id = $1`
+
+	err = tx.QueryRow(q, bookingID).Scan(&ownerID, &tenantID)
+	return ownerID, tenantID, err
+}
+
+// recordCharge проводит списанный платёж: арендатор оплатил бронь, деньги
+// пришли на счёт платформы, и она теперь должна их владельцу за вычетом
+// комиссии.
+func (r *LedgerRepository) recordCharge(tx *sql.Tx, p *domain.Payment) error {
+	ownerID, tenantID, err := bookingParties(tx, p.BookingID)
+	if err != nil {
+		return err
+	}
+
+	_, err = postLedger(tx, ledgerTx{
+		Kind:        domain.LedgerBookingCharge,
+		Reference:   fmt.Sprintf("payment-%d-charge", p.ID),
+		BookingID:   &p.BookingID,
+		PaymentID:   &p.ID,
+		Description: fmt.Sprintf("Booking #%d", p.BookingID),
+		Currency:    p.Currency,
+		Lines: []domain.LedgerLine{
+			{Kind: domain.LedgerAccountTenant, UserID: &tenantID, Amount: p.Amount},
+			{Kind: domain.LedgerAccountOwner, UserID: &ownerID, Amount: -p.Amount},
+			{Kind: domain.LedgerAccountPlatform, Amount: p.Amount},
+			{Kind: domain.LedgerAccountTenant, UserID: &tenantID, Amount: -p.Amount},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	commission := domain.Commission(p.Amount, r.commissionPercent)
+	if commission == 0 {
+		return nil
+	}
+	_, err = postLedger(tx, ledgerTx{
+		Kind:        domain.LedgerCommission,
+		Reference:   fmt.Sprintf("payment-%d-commission", p.ID),
+		BookingID:   &p.BookingID,
+		PaymentID:   &p.ID,
+		Description: fmt.Sprintf("Commission %d%% for booking #%d", r.commissionPercent, p.BookingID),
+		Currency:    p.Currency,
+		Lines: []domain.LedgerLine{
+			{Kind: domain.LedgerAccountOwner, UserID: &ownerID, Amount: commission},
+			{Kind: domain.LedgerAccountFees, Amount: -commission},
+		},
+	})
+	return err
+}
+
+// recordRefund проводит возврат, доведший общую сумму возвратов платежа
+// с prevTotal до p.RefundedAmount. Возврат идёт за счёт владельца, а
+// комиссия с возвращённой части возвращается владельцу.
+func (r *LedgerRepository) recordRefund(tx *sql.Tx, p *domain.Payment, prevTotal int64) error {
+	refund := p.RefundedAmount - prevTotal
+	if refund <= 0 {
+		return nil
+	}
+	ownerID, tenantID, err := bookingParties(tx, p.BookingID)
+	if err != nil {
+		return err
+	}
+
+	_, err = postLedger(tx, ledgerTx{
+		Kind:        domain.LedgerRefund,
+		Reference:   fmt.Sprintf("payment-%d-refund-%d", p.ID, p.RefundedAmount),
+		BookingID:   &p.BookingID,
+		PaymentID:   &p.ID,
+		Description: fmt.Sprintf("Refund for booking #%d", p.BookingID),
+		Currency:    p.Currency,
+		Lines: []domain.LedgerLine{
+			{Kind: domain.LedgerAccountOwner, UserID: &ownerID, Amount: refund},
+			{Kind: domain.LedgerAccountTenant, UserID: &tenantID, Amount: -refund},
+			{Kind: domain.LedgerAccountTenant, UserID: &tenantID, Amount: refund},
+			{Kind: domain.LedgerAccountPlatform, Amount: -refund},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	// комиссия удерживается только с невозвращённой части платежа
+	reversal := domain.Commission(p.Amount-prevTotal, r.commissionPercent) -
+		domain.Commission(p.Amount-p.RefundedAmount, r.commissionPercent)
+	if reversal == 0 {
+		return nil
+	}
+	_, err = postLedger(tx, ledgerTx{
+		Kind:        domain.LedgerCommission,
+		Reference:   fmt.Sprintf("payment-%d-commission-refund-%d", p.ID, p.RefundedAmount),
+		BookingID:   &p.BookingID,
+		PaymentID:   &p.ID,
+		Description: fmt.Sprintf("Commission returned for booking #%d", p.BookingID),
+		Currency:    p.Currency,
+		Lines: []domain.LedgerLine{
+			{Kind: domain.LedgerAccountFees, Amount: reversal},
+			{Kind: domain.LedgerAccountOwner, UserID: &ownerID, Amount: -reversal},
+		},
+	})
+	return err
+}
+
+// RunPayouts создаёт пакет выплат: каждому владельцу выплачивается весь
+// долг платформы перед ним. Пакет не создаётся, если предыдущий моложе
+// minInterval или выплачивать нечего. Одновременно пакет создаёт только
+// одна реплика.
+func (r *LedgerRepository) RunPayouts(minInterval time.Duration) ([]domain.Payout, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var locked bool
+	if err := tx.QueryRow(`SELECT pg_try_advisory_xact_lock(hashtext('payout_batch'))`).Scan(&locked); err != nil {
+		return nil, err
+	}
+	if !locked {
+		return nil, nil
+	}
+
+	var recent bool
+	const recentQ = `SELECT EXISTS (SELECT 1 FROM payout_batches WHERE created_at > NOW() - $1::float8 * INTERVAL '1 second')`
+	if err := tx.QueryRow(recentQ, minInterval.Seconds()).Scan(&recent); err != nil {
+		return nil, err
+	}
+	if recent {
+		return nil, nil
+	}
+
+	// новые транзакции ждут конца пакета, а незакоммиченные к этому
+	// моменту успевают закоммититься — иначе их проводки, оказавшись
+	// ниже отсечки, не попали бы ни в одну выплату
+	if _, err := tx.Exec(`LOCK TABLE ledger_transactions IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+		return nil, err
+	}
+	var cutoff int64
+	if err := tx.QueryRow(`SELECT COALESCE(MAX(id), 0) FROM ledger_transactions`).Scan(&cutoff); err != nil {
+		return nil, err
+	}
+
+	const owedQ = `
+		SELECT a.user_id, a.currency, -SUM(e.amount)
+		FROM ledger_entries e
+		JOIN ledger_accounts a ON a.id = e.account_id
+		WHERE a.kind = 'owner' AND e.transaction_id <= $1
+		GROUP BY a.id, a.user_id, a.currency
+		HAVING SUM(e.amount) < 0
+		ORDER BY a.user_id, a.currency`
+
+	rows, err := tx.Query(owedQ, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	var due []domain.Payout
+	for rows.Next() {
+		var p domain.Payout
+		if err := rows.Scan(&p.OwnerID, &p.Currency, &p.ClosingBalance); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		p.Amount = p.ClosingBalance
+		p.ToTransaction = cutoff
+		due = append(due, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(due) == 0 {
+		return nil, nil
+	}
+
+	var batchID int
+	const batchQ = `INSERT INTO payout_batches (cutoff_transaction_id, created_at) VALUES ($1, NOW()) RETURNING id`
+	if err := tx.QueryRow(batchQ, cutoff).Scan(&batchID); err != nil {
+		return nil, err
+	}
+
+	for i := range due {
+		p := &due[i]
+		p.BatchID = batchID
+		if err := r.createPayout(tx, p); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return due, nil
+}
+
+// createPayout продолжает выписку владельца с места предыдущей выплаты
+// и проводит выплату: долг перед владельцем гасится деньгами платформы.
+func (r *LedgerRepository) createPayout(tx *sql.Tx, p *domain.Payout) error {
+	const prevQ = `
+		SELECT COALESCE(MAX(to_transaction_id), 0)
+		FROM payouts
+		WHERE owner_id = $1 AND currency = $2`
+	if err := tx.QueryRow(prevQ, p.OwnerID, p.Currency).Scan(&p.FromTransaction); err != nil {
+		return err
+	}
+
+	const openingQ = `
+		SELECT COALESCE(-SUM(e.amount), 0)
+		FROM ledger_entries e
+		JOIN ledger_accounts a ON a.id = e.account_id
+		WHERE a.kind = 'owner' AND a.user_id = $1 AND a.currency = $2 AND e.transaction_id <= $3`
+	if err := tx.QueryRow(openingQ, p.OwnerID, p.Currency, p.FromTransaction).Scan(&p.OpeningBalance); err != nil {
+		return err
+	}
+
+	ledgerID, err := postLedger(tx, ledgerTx{
+		Kind:        domain.LedgerPayout,
+		Reference:   fmt.Sprintf("payout-batch-%d-owner-%d-%s", p.BatchID, p.OwnerID, p.Currency),
+		Description: fmt.Sprintf("Payout batch #%d", p.BatchID),
+		Currency:    p.Currency,
+		Lines: []domain.LedgerLine{
+			{Kind: domain.LedgerAccountOwner, UserID: &p.OwnerID, Amount: p.Amount},
+			{Kind: domain.LedgerAccountPlatform, Amount: -p.Amount},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	const q = `
+		INSERT INTO payouts (batch_id, owner_id, currency, amount, opening_balance, closing_balance,
+		                     from_transaction_id, to_transaction_id, ledger_transaction_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW())
+		RETURNING id, created_at`
+
+	return tx.QueryRow(q,
+		p.BatchID, p.OwnerID, p.Currency, p.Amount, p.OpeningBalance, p.ClosingBalance,
+		p.FromTransaction, p.ToTransaction, ledgerID,
+	).Scan(&p.ID, &p.CreatedAt)
+}
+
+const payoutColumns = `id, batch_id, owner_id, currency, amount, opening_balance, closing_balance,
+		from_transaction_id, to_transaction_id, created_at`
+
+func scanPayout(row scanner) (*domain.Payout, error) {
+	p := &domain.Payout{}
+	err := row.Scan(
+		&p.ID, &p.BatchID, &p.OwnerID, &p.Currency, &p.Amount, &p.OpeningBalance, &p.ClosingBalance,
+		&p.FromTransaction, &p.ToTransaction, &p.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (r *LedgerRepository) ListPayouts(ownerID int) ([]domain.Payout, error) {
+	const q = `
+		SELECT ` + payoutColumns + `
+		FROM payouts
+		WHERE owner_id = $1
+		ORDER BY id DESC`
+
+	rows, err := r.db.Query(q, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []domain.Payout
+	for rows.Next() {
+		p, err := scanPayout(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *p)
+	}
+	return result, rows.Err()
+}
+
+func (r *LedgerRepository) GetPayout(id int) (*domain.Payout, error) {
+	const q = `SELECT ` + payoutColumns + ` FROM payouts WHERE id = $1`
+
+	p, err := scanPayout(r.db.QueryRow(q, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrPayoutNotFound
+	}
+	return p, err
+}
+
+// StatementEntries возвращает проводки счёта владельца, вошедшие в выплату.
+func (r *LedgerRepository) StatementEntries(p *domain.Payout) ([]domain.LedgerEntry, error) {
+	const q = `
+		SELECT e.id, t.id, t.kind, e.account_id, e.amount, a.currency, t.booking_id, t.payment_id,
+		       t.description, t.created_at
+		FROM ledger_entries e
+		JOIN ledger_accounts a ON a.id = e.account_id
+		JOIN ledger_transactions t ON t.id = e.transaction_id
+		WHERE a.kind = 'owner' AND a.user_id = $1 AND a.currency = $2
+		  AND e.transaction_id > $3 AND e.transaction_id <= $4
+		ORDER BY e.transaction_id, e.id`
+
+	rows, err := r.db.Query(q, p.OwnerID, p.Currency, p.FromTransaction, p.ToTransaction)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []domain.LedgerEntry
+	for rows.Next() {
+		var e domain.LedgerEntry
+		if err := rows.Scan(
+			&e.ID, &e.TransactionID, &e.Kind, &e.AccountID, &e.Amount, &e.Currency,
+			&e.BookingID, &e.PaymentID, &e.Description, &e.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		result = append(result, e)
+	}
+	return result, rows.Err()
+}
+
+// OwnerBalances возвращает текущий долг платформы перед владельцем по валютам.
+func (r *LedgerRepository) OwnerBalances(ownerID int) ([]domain.OwnerBalance, error) {
+	const q = `
+		SELECT a.currency, COALESCE(-SUM(e.amount), 0)
+		FROM ledger_accounts a
+		LEFT JOIN ledger_entries e ON e.account_id = a.id
+		WHERE a.kind = 'owner' AND a.user_id = $1
+		GROUP BY a.currency
+		ORDER BY a.currency`
+
+	rows, err := r.db.Query(q, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []domain.OwnerBalance
+	for rows.Next() {
+		var b domain.OwnerBalance
+		if err := rows.Scan(&b.Currency, &b.Amount); err != nil {
+			return nil, err
+		}
+		result = append(result, b)
+	}
+	return result, rows.Err()
+}
+
+// Reconcile сверяет книгу по валютам. В сверенной книге суммы по видам
+// счетов в сумме дают ноль, несбалансированных транзакций нет, а на счёте
+// platform лежит ровно то, что списано платежами, минус возвраты и выплаты.
+func (r *LedgerRepository) Reconcile() ([]domain.LedgerReconciliation, error) {
+	byCurrency := make(map[string]*domain.LedgerReconciliation)
+	var order []string
+	get := func(currency string) *domain.LedgerReconciliation {
+		rec, ok := byCurrency[currency]
+		if !ok {
+			rec = &domain.LedgerReconciliation{
+				Currency:        currency,
+				Balances:        make(map[domain.LedgerAccountKind]int64),
+				UnbalancedTxIDs: []int64{},
+			}
+			byCurrency[currency] = rec
+			order = append(order, currency)
+		}
+		return rec
+	}
+
+	const balancesQ = `
+		SELECT a.currency, a.kind, SUM(e.amount)
+		FROM ledger_entries e
+		JOIN ledger_accounts a ON a.id = e.account_id
+		GROUP BY a.currency, a.kind
+		ORDER BY a.currency, a.kind`
+	rows, err := r.db.Query(balancesQ)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var (
+			currency string
+			kind     domain.LedgerAccountKind
+			sum      int64
+		)
+		if err := rows.Scan(&currency, &kind, &sum); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		get(currency).Balances[kind] = sum
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	const unbalancedQ = `
+		SELECT a.currency, e.transaction_id
+		FROM ledger_entries e
+		JOIN ledger_accounts a ON a.id = e.account_id
+		GROUP BY a.currency, e.transaction_id
+		HAVING SUM(e.amount) <> 0
+		ORDER BY e.transaction_id`
+	rows, err = r.db.Query(unbalancedQ)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var (
+			currency string
+			id       int64
+		)
+		if err := rows.Scan(&currency, &id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		rec := get(currency)
+		rec.UnbalancedTxIDs = append(rec.UnbalancedTxIDs, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	const paymentsQ = `
+		SELECT currency, SUM(amount - refunded_amount)
+		FROM payments
+		WHERE status IN ('captured', 'refunded')
+		GROUP BY currency`
+	rows, err = r.db.Query(paymentsQ)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var (
+			currency string
+			net      int64
+		)
+		if err := rows.Scan(&currency, &net); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		get(currency).PaymentsNet = net
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	const payoutsQ = `SELECT currency, SUM(amount) FROM payouts GROUP BY currency`
+	rows, err = r.db.Query(payoutsQ)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var (
+			currency string
+			total    int64
+		)
+		if err := rows.Scan(&currency, &total); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		get(currency).PayoutsTotal = total
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	result := make([]domain.LedgerReconciliation, 0, len(order))
+	for _, currency := range order {
+		rec := byCurrency[currency]
+		rec.PlatformDifference = rec.Balances[domain.LedgerAccountPlatform] - (rec.PaymentsNet - rec.PayoutsTotal)
+		result = append(result, *rec)
+	}
+	return result, nil
+}
@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"SpaceBookProject/internal/domain"
+)
+
+// ModerationRepository выполняет действия администратора. Каждое действие
+// и его запись в admin_audit_log фиксируются одной транзакцией, поэтому
+// в журнале нет ни пропущенных, ни несостоявшихся действий.
+type ModerationRepository struct {
+	db *sql.DB
+}
+
+func NewModerationRepository(db *sql.DB) *ModerationRepository {
+	return &ModerationRepository{db: db}
+}
+
+// SuspendUser блокирует пользователя, закрывает все его сессии
+// и поднимает token_version, отзывая выданные access-токены.
+func (r *ModerationRepository) SuspendUser(entry *domain.AuditEntry) error {
+	return r.withAudit(entry, func(tx *sql.Tx) error {
+		const q = `
+			UPDATE users
+			SET suspended_at = NOW(), suspension_reason = $2,
+			    token_version = token_version + 1, updated_at = NOW()
+			WHERE id = $1`
+
+		if err := execAffecting(tx, ErrUserNotFound, q, entry.TargetID, entry.Reason); err != nil {
+			return err
+		}
+		return revokeUserSessions(tx, int(entry.TargetID), RevokeReasonSuspended)
+	})
+}
+
+func (r *ModerationRepository) ReinstateUser(entry *domain.AuditEntry) error {
+	return r.withAudit(entry, func(tx *sql.Tx) error {
+		const q = `
+			UPDATE users
+			SET suspended_at = NULL, suspension_reason = NULL, updated_at = NOW()
+			WHERE id = $1`
+
+		return execAffecting(tx, ErrUserNotFound, q, entry.TargetID)
+	})
+}
+
+// ForceCancelBooking отменяет бронь в обход проверок владельца и арендатора;
+// допустимость перехода проверяет общая таблица статусов, причина модерации
+// попадает и в журнал, и в историю брони.
+func (r *ModerationRepository) ForceCancelBooking(entry *domain.AuditEntry) (*domain.Booking, error) {
+	var b *domain.Booking
+	err := r.withAudit(entry, func(tx *sql.Tx) error {
+		var err error
+		b, err = transitionBooking(tx, int(entry.TargetID), domain.BookingTransition{
+			To:          domain.BookingStatusCancelled,
+			ActorID:     &entry.AdminID,
+			Reason:      entry.Reason,
+			CancelledBy: domain.CancelledByAdmin,
+		}, nil)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (r *ModerationRepository) UnpublishSpace(entry *domain.AuditEntry) error {
+	return r.withAudit(entry, func(tx *sql.Tx) error {
+		const q = `
+			UPDATE spaces
+			SET unpublished_at = NOW(), unpublish_reason = $2, updated_at = NOW()
+			WHERE id = $1 AND deleted_at IS NULL`
+
+		return execAffecting(tx, ErrSpaceNotFound, q, entry.TargetID, entry.Reason)
+	})
+}
+
+func (r *ModerationRepository) RepublishSpace(entry *domain.AuditEntry) error {
+	return r.withAudit(entry, func(tx *sql.Tx) error {
+		const q = `
+			UPDATE spaces
+			SET unpublished_at = NULL, unpublish_reason = NULL, updated_at = NOW()
+			WHERE id = $1 AND deleted_at IS NULL`
+
+		return execAffecting(tx, ErrSpaceNotFound, q, entry.TargetID)
+	})
+}
+
+// AuditFilter — выборка журнала; пустые поля не ограничивают результат.
+type AuditFilter struct {
+	AdminID    *int
+	TargetType *domain.AuditTargetType
+	TargetID   *int64
+	Limit      int
+	Offset     int
+}
+
+func (r *ModerationRepository) ListAudit(f AuditFilter) ([]domain.AuditEntry, error) {
+	query := `
+		SELECT id, admin_id, action, target_type, target_id, reason, created_at
+		FROM admin_audit_log
+	`
+	var (
+		conds []string
+		args  []any
+		i     = 1
+	)
+
+	if f.AdminID != nil {
+		conds = append(conds, fmt.Sprintf("admin_id = $%d", i))
+		args = append(args, *f.AdminID)
+		i++
+	}
+	if f.TargetType != nil {
+		conds = append(conds, fmt.Sprintf("target_type = $%d", i))
+		args = append(args, *f.TargetType)
+		i++
+	}
+	if f.TargetID != nil {
+		conds = append(conds, fmt.Sprintf("target_id = $%d", i))
+		args = append(args, *f.TargetID)
+		i++
+	}
+
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+	query += fmt.Sprintf(" ORDER BY id DESC LIMIT $%d OFFSET $%d", i, i+1)
+	args = append(args, f.Limit, f.Offset)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []domain.AuditEntry
+	for rows.Next() {
+		var e domain.AuditEntry
+		if err := rows.Scan(&e.ID, &e.AdminID, &e.Action, &e.TargetType, &e.TargetID, &e.Reason, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		result = append(result, e)
+	}
+	return result, rows.Err()
+}
+
+func (r *ModerationRepository) withAudit(entry *domain.AuditEntry, action func(tx *sql.Tx) error) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := action(tx); err != nil {
+		return err
+	}
+
+	const q = `
+		INSERT INTO admin_audit_log (admin_id, action, target_type, target_id, reason)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at`
+
+	err = tx.QueryRow(q, entry.AdminID, entry.Action, entry.TargetType, entry.TargetID, entry.Reason).
+		Scan(&entry.ID, &entry.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func execAffecting(tx execer, notFound error, query string, args ...any) error {
+	res, err := tx.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return notFound
+	}
+	return nil
+}
@@ -0,0 +1,357 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+
+	"SpaceBookProject/internal/domain"
+)
+
+var (
+	ErrOrganizationNotFound    = errors.New("organization not found")
+	ErrNotOrgMember            = errors.New("user is not a member of the organization")
+	ErrLastOrgAdmin            = errors.New("organization must keep at least one admin")
+	ErrInvitationInvalid       = errors.New("invitation is invalid or expired")
+	ErrInvitationNotFound      = errors.New("invitation not found")
+	ErrInvitationEmailMismatch = errors.New("invitation was sent to a different email")
+)
+
+const invitationColumns = `id, organization_id, email, role, invited_by, expires_at, accepted_at, revoked_at, created_at`
+
+type OrganizationRepository struct {
+	db *sql.DB
+}
+
+func NewOrganizationRepository(db *sql.DB) *OrganizationRepository {
+	return &OrganizationRepository{db: db}
+}
+
+// Create сохраняет организацию и делает создателя её администратором.
+func (r *OrganizationRepository) Create(org *domain.Organization) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	const insertOrg = `
+		INSERT INTO organizations (name, created_by, created_at, updated_at)
+		VALUES ($1, $2, NOW(), NOW())
+		RETURNING id, created_at, updated_at`
+
+	if err := tx.QueryRow(insertOrg, org.Name, org.CreatedBy).Scan(&org.ID, &org.CreatedAt, &org.UpdatedAt); err != nil {
+		return err
+	}
+
+	const insertMember = `
+		INSERT INTO organization_members (organization_id, user_id, role, created_at)
+		VALUES ($1, $2, $3, NOW())`
+
+	if _, err := tx.Exec(insertMember, org.ID, org.CreatedBy, domain.OrgRoleAdmin); err != nil {
+		return err
+	}
+	org.MyRole = domain.OrgRoleAdmin
+
+	return tx.Commit()
+}
+
+func (r *OrganizationRepository) GetByID(id int) (*domain.Organization, error) {
+	const q = `
+		SELECT id, name, created_by, booking_approval_required, created_at, updated_at
+		FROM organizations
+		WHERE id = $1`
+
+	org := &domain.Organization{}
+	err := r.db.QueryRow(q, id).Scan(&org.ID, &org.Name, &org.CreatedBy, &org.BookingApprovalRequired, &org.CreatedAt, &org.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrOrganizationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return org, nil
+}
+
+// ListByMember возвращает организации пользователя вместе с его ролью в каждой.
+func (r *OrganizationRepository) ListByMember(userID int) ([]domain.Organization, error) {
+	const q = `
+		SELECT o.id, o.name, o.created_by, o.booking_approval_required, o.created_at, o.updated_at, m.role
+		FROM organizations o
+		JOIN organization_members m ON m.organization_id = o.id
+		WHERE m.user_id = $1
+		ORDER BY o.name, o.id`
+
+	rows, err := r.db.Query(q, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []domain.Organization
+	for rows.Next() {
+		var o domain.Organization
+		if err := rows.Scan(&o.ID, &o.Name, &o.CreatedBy, &o.BookingApprovalRequired, &o.CreatedAt, &o.UpdatedAt, &o.MyRole); err != nil {
+			return nil, err
+		}
+		result = append(result, o)
+	}
+	return result, rows.Err()
+}
+
+func (r *OrganizationRepository) Update(org *domain.Organization) error {
+	const q = `
+		UPDATE organizations
+		SET name = $1, booking_approval_required = $2, updated_at = NOW()
+		WHERE id = $3
+		RETURNING updated_at`
+
+	err := r.db.QueryRow(q, org.Name, org.BookingApprovalRequired, org.ID).Scan(&org.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return ErrOrganizationNotFound
+	}
+	return err
+}
+
+func (r *OrganizationRepository) MemberRole(orgID, userID int) (domain.OrgRole, error) {
+	const q = `
+		SELECT role
+		FROM organization_members
+		WHERE organization_id = $1 AND user_id = $2`
+
+	var role domain.OrgRole
+	err := r.db.QueryRow(q, orgID, userID).Scan(&role)
+	if err == sql.ErrNoRows {
+		return "", ErrNotOrgMember
+	}
+	if err != nil {
+		return "", err
+	}
+	return role, nil
+}
+
+func (r *OrganizationRepository) ListMembers(orgID int) ([]domain.OrgMember, error) {
+	const q = `
+		SELECT m.organization_id, m.user_id, u.email, u.first_name, u.last_name, m.role, m.created_at
+		FROM organization_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.organization_id = $1
+		ORDER BY m.created_at, m.user_id`
+
+	rows, err := r.db.Query(q, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []domain.OrgMember
+	for rows.Next() {
+		var m domain.OrgMember
+		if err := rows.Scan(&m.OrganizationID, &m.UserID, &m.Email, &m.FirstName, &m.LastName, &m.Role, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		result = append(result, m)
+	}
+	return result, rows.Err()
+}
+
+// UpdateMemberRole меняет роль участника. Понизить последнего
+// администратора нельзя: организация осталась бы без управления.
+func (r *OrganizationRepository) UpdateMemberRole(orgID, userID int, role domain.OrgRole) error {
+	return r.changeMember(orgID, userID, role != domain.OrgRoleAdmin, func(tx *sql.Tx) error {
+		const q = `
+			UPDATE organization_members
+			SET role = $3
+			WHERE organization_id = $1 AND user_id = $2`
+
+		return execAffecting(tx, ErrNotOrgMember, q, orgID, userID, role)
+	})
+}
+
+func (r *OrganizationRepository) RemoveMember(orgID, userID int) error {
+	return r.changeMember(orgID, userID, true, func(tx *sql.Tx) error {
+		const q = `
+			DELETE FROM organization_members
+			WHERE organization_id = $1 AND user_id = $2`
+
+		return execAffecting(tx, ErrNotOrgMember, q, orgID, userID)
+	})
+}
+
+// changeMember блокирует администраторов организации, чтобы два
+// параллельных запроса не разжаловали последних двух одновременно.
+func (r *OrganizationRepository) changeMember(orgID, userID int, losesAdmin bool, change func(tx *sql.Tx) error) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if losesAdmin {
+		const q = `
+			SELECT user_id
+			FROM organization_members
+			WHERE organization_id = $1 AND role = 'admin'
+			FOR UPDATE`
+
+		rows, err := tx.Query(q, orgID)
+		if err != nil {
+			return err
+		}
+		var admins []int
+		for rows.Next() {
+			var id int
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return err
+			}
+			admins = append(admins, id)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		if len(admins) == 1 && admins[0] == userID {
+			return ErrLastOrgAdmin
+		}
+	}
+
+	if err := change(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func scanInvitation(row scanner) (*domain.OrgInvitation, error) {
+	inv := &domain.OrgInvitation{}
+	err := row.Scan(
+		&inv.ID, &inv.OrganizationID, &inv.Email, &inv.Role, &inv.InvitedBy,
+		&inv.ExpiresAt, &inv.AcceptedAt, &inv.RevokedAt, &inv.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+// CreateInvitation сохраняет приглашение, отзывая прежние неотвеченные
+// приглашения того же адреса в эту организацию.
+func (r *OrganizationRepository) CreateInvitation(inv *domain.OrgInvitation, tokenHash string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	const revokeQ = `
+		UPDATE organization_invitations
+		SET revoked_at = NOW()
+		WHERE organization_id = $1 AND lower(email) = lower($2)
+		  AND accepted_at IS NULL AND revoked_at IS NULL`
+
+	if _, err := tx.Exec(revokeQ, inv.OrganizationID, inv.Email); err != nil {
+		return err
+	}
+
+	const insertQ = `
+		INSERT INTO organization_invitations (organization_id, email, role, token_hash, invited_by, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())
+		RETURNING id, created_at`
+
+	err = tx.QueryRow(insertQ, inv.OrganizationID, inv.Email, inv.Role, tokenHash, inv.InvitedBy, inv.ExpiresAt).
+		Scan(&inv.ID, &inv.CreatedAt)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ListPendingInvitations возвращает неотвеченные и непросроченные приглашения.
+func (r *OrganizationRepository) ListPendingInvitations(orgID int) ([]domain.OrgInvitation, error) {
+	const q = `
+		SELECT ` + invitationColumns + `
+		FROM organization_invitations
+		WHERE organization_id = $1
+		  AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > NOW()
+		ORDER BY created_at DESC, id DESC`
+
+	rows, err := r.db.Query(q, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []domain.OrgInvitation
+	for rows.Next() {
+		inv, err := scanInvitation(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *inv)
+	}
+	return result, rows.Err()
+}
+
+func (r *OrganizationRepository) RevokeInvitation(orgID int, id int64) error {
+	const q = `
+		UPDATE organization_invitations
+		SET revoked_at = NOW()
+		WHERE id = $1 AND organization_id = $2
+		  AND accepted_at IS NULL AND revoked_at IS NULL`
+
+	return execAffecting(r.db, ErrInvitationNotFound, q, id, orgID)
+}
+
+// AcceptInvitation гасит приглашение и добавляет пользователя в организацию.
+// Принять приглашение может только владелец адреса, на который оно
+// отправлено. Если пользователь уже состоит в организации, его роль не
+// меняется: для этого есть UpdateMemberRole с проверкой последнего админа.
+func (r *OrganizationRepository) AcceptInvitation(tokenHash string, userID int, email string) (*domain.OrgInvitation, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	const selectQ = `
+		SELECT ` + invitationColumns + `
+		FROM organization_invitations
+		WHERE token_hash = $1
+		  AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > NOW()
+		FOR UPDATE`
+
+	inv, err := scanInvitation(tx.QueryRow(selectQ, tokenHash))
+	if err == sql.ErrNoRows {
+		return nil, ErrInvitationInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(inv.Email, email) {
+		return nil, ErrInvitationEmailMismatch
+	}
+
+	const upsertMember = `
+		INSERT INTO organization_members (organization_id, user_id, role, created_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (organization_id, user_id) DO NOTHING`
+
+	if _, err := tx.Exec(upsertMember, inv.OrganizationID, userID, inv.Role); err != nil {
+		return nil, err
+	}
+
+	const acceptQ = `
+		UPDATE organization_invitations
+		SET accepted_at = NOW()
+		WHERE id = $1
+		RETURNING accepted_at`
+
+	if err := tx.QueryRow(acceptQ, inv.ID).Scan(&inv.AcceptedAt); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
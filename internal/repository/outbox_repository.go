@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"SpaceBookProject/internal/domain"
+)
+
+// enqueueEvent пишет событие в outbox. Вызывается в той же транзакции,
+// что и изменение брони, поэтому событие не теряется и не появляется
+// без самого изменения.
+func enqueueEvent(tx execer, evt domain.BookingEvent) error {
+	payload, err := json.Marshal(evt)
+	if err != nil {
+		return err
+	}
+
+	const q = `
+		INSERT INTO outbox (event_type, payload, status, next_attempt_at, created_at)
+		VALUES ($1, $2, 'pending', NOW(), NOW())`
+
+	_, err = tx.Exec(q, evt.Type, payload)
+	return err
+}
+
+type OutboxRepository struct {
+	db *sql.DB
+}
+
+func NewOutboxRepository(db *sql.DB) *OutboxRepository {
+	return &OutboxRepository{db: db}
+}
+
+// Claim захватывает до limit готовых к отправке событий на время lease.
+// SKIP LOCKED позволяет нескольким воркерам (и репликам) разбирать очередь
+// параллельно, а аренда возвращает событие в очередь, если воркер упал.
+func (r *OutboxRepository) Claim(limit int, lease time.Duration) ([]domain.OutboxMessage, error) {
+	const q = `
+		UPDATE outbox
+		SET locked_until = NOW() + make_interval(secs => $2),
+		    attempts = attempts + 1
+		WHERE id IN (
+		    SELECT id
+		    FROM outbox
+		    WHERE status = 'pending'
+		      AND next_attempt_at <= NOW()
+		      AND (locked_until IS NULL OR locked_until < NOW())
+		    ORDER BY id
+		    LIMIT $1
+		    FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, payload, attempts`
+
+	rows, err := r.db.Query(q, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []domain.OutboxMessage
+	for rows.Next() {
+		var (
+			msg     domain.OutboxMessage
+			payload []byte
+		)
+		if err := rows.Scan(&msg.ID, &payload, &msg.Attempts); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(payload, &msg.Event); err != nil {
+			return nil, err
+		}
+		msg.Event.ID = msg.ID
+		res = append(res, msg)
+	}
+	return res, rows.Err()
+}
+
+func (r *OutboxRepository) MarkDelivered(id int64) error {
+	const q = `
+		UPDATE outbox
+		SET status = 'delivered', delivered_at = NOW(), locked_until = NULL, last_error = NULL
+		WHERE id = $1`
+
+	_, err := r.db.Exec(q, id)
+	return err
+}
+
+// MarkFailed возвращает событие в очередь с новой попыткой в retryAt.
+func (r *OutboxRepository) MarkFailed(id int64, reason string, retryAt time.Time) error {
+	const q = `
+		UPDATE outbox
+		SET next_attempt_at = $2, locked_until = NULL, last_error = $3
+		WHERE id = $1`
+
+	_, err := r.db.Exec(q, id, retryAt, reason)
+	return err
+}
+
+// MarkDead переводит событие в dead-letter: воркер его больше не берёт.
+func (r *OutboxRepository) MarkDead(id int64, reason string) error {
+	const q = `
+		UPDATE outbox
+		SET status = 'dead', locked_until = NULL, last_error = $2
+		WHERE id = $1`
+
+	_, err := r.db.Exec(q, id, reason)
+	return err
+}
@@ -0,0 +1,290 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"SpaceBookProject/internal/domain"
+
+	"github.com/lib/pq"
+)
+
+var (
+	ErrPaymentNotFound   = errors.New("payment not found")
+	ErrPaymentInProgress = errors.New("booking already has an active payment")
+)
+
+const paymentColumns = `id, booking_id, provider, provider_ref, amount, currency, status, refunded_amount, action_url, failure_reason, created_at, updated_at`
+
+// PaymentRepository проводит списания и возвраты по главной книге и
+// выставляет по ним документы в той же транзакции, что и смену платежа,
+// поэтому ни книга, ни документы не расходятся с платежами.
+type PaymentRepository struct {
+	db       *sql.DB
+	ledger   *LedgerRepository
+	invoices *InvoiceRepository
+}
+
+func NewPaymentRepository(db *sql.DB, ledger *LedgerRepository, invoices *InvoiceRepository) *PaymentRepository {
+	return &PaymentRepository{db: db, ledger: ledger, invoices: invoices}
+}
+
+func scanPayment(row scanner) (*domain.Payment, error) {
+	p := &domain.Payment{}
+	err := row.Scan(
+		&p.ID, &p.BookingID, &p.Provider, &p.ProviderRef, &p.Amount, &p.Currency,
+		&p.Status, &p.RefundedAmount, &p.ActionURL, &p.FailureReason, &p.CreatedAt, &p.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Create сохраняет платёж в статусе pending до обращения к шлюзу: так
+// второй параллельный запрос на оплату брони упрётся в уникальный индекс.
+func (r *PaymentRepository) Create(p *domain.Payment) error {
+	const q = `
+		INSERT INTO payments (booking_id, provider, amount, currency, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, NOW(), NOW())
+		RETURNING ` + paymentColumns
+
+	created, err := scanPayment(r.db.QueryRow(q, p.BookingID, p.Provider, p.Amount, p.Currency))
+	if isPQError(err, pqUniqueViolation) {
+		return ErrPaymentInProgress
+	}
+	if err != nil {
+		return err
+	}
+	*p = *created
+	return nil
+}
+
+func (r *PaymentRepository) GetByRef(provider, ref string) (*domain.Payment, error) {
+	const q = `SELECT ` + paymentColumns + ` FROM payments WHERE provider = $1 AND provider_ref = $2`
+
+	p, err := scanPayment(r.db.QueryRow(q, provider, ref))
+	if err == sql.ErrNoRows {
+		return nil, ErrPaymentNotFound
+	}
+	return p, err
+}
+
+// GetCaptured возвращает списанный платёж брони, из которого ещё можно вернуть деньги.
+func (r *PaymentRepository) GetCaptured(bookingID int) (*domain.Payment, error) {
+	const q = `SELECT ` + paymentColumns + ` FROM payments WHERE booking_id = $1 AND status = 'captured'`
+
+	p, err := scanPayment(r.db.QueryRow(q, bookingID))
+	if err == sql.ErrNoRows {
+		return nil, ErrPaymentNotFound
+	}
+	return p, err
+}
+
+func (r *PaymentRepository) ListByBooking(bookingID int) ([]domain.Payment, error) {
+	const q = `
+		SELECT ` + paymentColumns + `
+		FROM payments
+		WHERE booking_id = $1
+		ORDER BY created_at DESC, id DESC`
+
+	rows, err := r.db.Query(q, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []domain.Payment
+	for rows.Next() {
+		p, err := scanPayment(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *p)
+	}
+	return result, rows.Err()
+}
+
+// SetAuthorization записывает ответ шлюза на Authorize.
+func (r *PaymentRepository) SetAuthorization(id int, ref string, status domain.PaymentStatus, actionURL, failureReason *string) (*domain.Payment, error) {
+	const q = `
+		UPDATE payments
+		SET provider_ref = $2, status = $3, action_url = $4, failure_reason = $5, updated_at = NOW()
+		WHERE id = $1 AND status = 'pending'
+		RETURNING ` + paymentColumns
+
+	p, err := scanPayment(r.db.QueryRow(q, id, ref, status, actionURL, failureReason))
+	if err == sql.ErrNoRows {
+		return nil, ErrPaymentNotFound
+	}
+	return p, err
+}
+
+// Advance переводит платёж в статус to, только если он сейчас в одном из
+// from. Если платёж уже ушёл дальше — например, тот же вебхук обработан
+// параллельно, — возвращается nil без ошибки. Списание проводится по
+// главной книге, и на него выставляется счёт.
+func (r *PaymentRepository) Advance(id int, from []domain.PaymentStatus, to domain.PaymentStatus, failureReason *string) (*domain.Payment, error) {
+	names := make([]string, len(from))
+	for i, st := range from {
+		names[i] = string(st)
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	const q = `
+		UPDATE payments
+		SET status = $2, failure_reason = COALESCE($3, failure_reason), updated_at = NOW()
+		WHERE id = $1 AND status = ANY($4)
+		RETURNING ` + paymentColumns
+
+	p, err := scanPayment(tx.QueryRow(q, id, to, failureReason, pq.Array(names)))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if to == domain.PaymentStatusCaptured {
+		if err := r.ledger.recordCharge(tx, p); err != nil {
+			return nil, err
+		}
+		if _, err := r.invoices.issueInvoice(tx, p.BookingID, &p.ID); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// SetRefunded фиксирует, что всего возвращено total; платёж, возвращённый
+// полностью, становится refunded. Меньшая сумма, чем уже записана, не
+// уменьшает возврат — так повторный вебхук ничего не ломает. Прирост
+// возвратов проводится по главной книге и оформляется credit note.
+func (r *PaymentRepository) SetRefunded(id int, total int64) (*domain.Payment, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	prevTotal, err := lockRefundable(tx, id)
+	if err != nil {
+		return nil, err
+	}
+	p, err := r.refundTo(tx, id, total, prevTotal)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// AddRefund добавляет к возвратам платежа amount, сделанный у шлюза с
+// ключом идемпотентности key. Возврат с уже записанным ключом не
+// учитывается второй раз: повтор после сбоя возвращает платёж как есть.
+func (r *PaymentRepository) AddRefund(id int, key string, amount int64) (*domain.Payment, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	prevTotal, err := lockRefundable(tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	const insertQ = `
+		INSERT INTO payment_refunds (payment_id, idempotency_key, amount, created_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (idempotency_key) DO NOTHING`
+
+	res, err := tx.Exec(insertQ, id, key, amount)
+	if err != nil {
+		return nil, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+
+	var p *domain.Payment
+	if n == 0 {
+		p, err = scanPayment(tx.QueryRow(`SELECT `+paymentColumns+` FROM payments WHERE id = $1`, id))
+	} else {
+		p, err = r.refundTo(tx, id, prevTotal+amount, prevTotal)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// lockRefundable блокирует списанный платёж и возвращает сумму его
+// возвратов на момент блокировки.
+func lockRefundable(tx *sql.Tx, id int) (int64, error) {
+	const q = `SELECT refunded_amount FROM payments WHERE id = $1 AND status IN ('captured', 'refunded') FOR UPDATE`
+
+	var total int64
+	err := tx.QueryRow(q, id).Scan(&total)
+	if err == sql.ErrNoRows {
+		return 0, ErrPaymentNotFound
+	}
+	return total, err
+}
+
+// refundTo доводит возвраты заблокированного платежа с prevTotal до total
+// и проводит прирост по книге и документам.
+func (r *PaymentRepository) refundTo(tx *sql.Tx, id int, total, prevTotal int64) (*domain.Payment, error) {
+	const q = `
+		UPDATE payments
+		SET refunded_amount = GREATEST(refunded_amount, LEAST($2, amount)),
+		    status = CASE WHEN GREATEST(refunded_amount, LEAST($2, amount)) = amount THEN 'refunded' ELSE status END,
+		    updated_at = NOW()
+		WHERE id = $1
+		RETURNING ` + paymentColumns
+
+	p, err := scanPayment(tx.QueryRow(q, id, total))
+	if err != nil {
+		return nil, err
+	}
+	if err := r.ledger.recordRefund(tx, p, prevTotal); err != nil {
+		return nil, err
+	}
+	if err := r.invoices.issueCreditNote(tx, p, prevTotal); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// WebhookProcessed сообщает, обработано ли уже событие шлюза.
+func (r *PaymentRepository) WebhookProcessed(provider, eventID string) (bool, error) {
+	const q = `SELECT EXISTS (SELECT 1 FROM payment_webhook_events WHERE provider = $1 AND event_id = $2)`
+
+	var seen bool
+	err := r.db.QueryRow(q, provider, eventID).Scan(&seen)
+	return seen, err
+}
+
+func (r *PaymentRepository) MarkWebhookProcessed(provider, eventID string) error {
+	const q = `
+		INSERT INTO payment_webhook_events (provider, event_id, received_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT DO NOTHING`
+
+	_, err := r.db.Exec(q, provider, eventID)
+	return err
+}
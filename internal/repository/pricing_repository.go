@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"SpaceBookProject/internal/domain"
+)
+
+var ErrPricingNotFound = errors.New("pricing is not set for this space")
+
+type PricingRepository struct {
+	db *sql.DB
+}
+
+func NewPricingRepository(db *sql.DB) *PricingRepository {
+	return &PricingRepository{db: db}
+}
+
+func (r *PricingRepository) Get(spaceID int) (*domain.SpacePricing, error) {
+	const q = `
+		SELECT space_id, currency, hour_rate, day_rate, week_rate, month_rate,
+		       weekend_percent, seasons, long_stay_discounts, updated_at
+		FROM space_pricing
+		WHERE space_id = $1`
+
+	p := &domain.SpacePricing{}
+	var seasons, discounts []byte
+	err := r.db.QueryRow(q, spaceID).Scan(
+		&p.SpaceID, &p.Currency,
+		&p.Rates.Hour, &p.Rates.Day, &p.Rates.Week, &p.Rates.Month,
+		&p.WeekendPercent, &seasons, &discounts, &p.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrPricingNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(seasons, &p.Seasons); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(discounts, &p.Discounts); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Upsert заменяет тарифы помещения целиком.
+func (r *PricingRepository) Upsert(p *domain.SpacePricing) error {
+	if p.Seasons == nil {
+		p.Seasons = []domain.SeasonalRate{}
+	}
+	if p.Discounts == nil {
+		p.Discounts = []domain.LongStayDiscount{}
+	}
+	seasons, err := json.Marshal(p.Seasons)
+	if err != nil {
+		return err
+	}
+	discounts, err := json.Marshal(p.Discounts)
+	if err != nil {
+		return err
+	}
+
+	const q = `
+		INSERT INTO space_pricing (space_id, currency, hour_rate, day_rate, week_rate, month_rate,
+		                           weekend_percent, seasons, long_stay_discounts, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW())
+		ON CONFLICT (space_id) DO UPDATE
+		SET currency = EXCLUDED.currency,
+		    hour_rate = EXCLUDED.hour_rate,
+		    day_rate = EXCLUDED.day_rate,
+		    week_rate = EXCLUDED.week_rate,
+		    month_rate = EXCLUDED.month_rate,
+		    weekend_percent = EXCLUDED.weekend_percent,
+		    seasons = EXCLUDED.seasons,
+		    long_stay_discounts = EXCLUDED.long_stay_discounts,
+		    updated_at = NOW()
+		RETURNING updated_at`
+
+	return r.db.QueryRow(q,
+		p.SpaceID, p.Currency,
+		p.Rates.Hour, p.Rates.Day, p.Rates.Week, p.Rates.Month,
+		p.WeekendPercent, seasons, discounts,
+	).Scan(&p.UpdatedAt)
+}
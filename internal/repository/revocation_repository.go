@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+)
+
+// RevocationRepository — Postgres-хранилище отзывов access-токенов.
+type RevocationRepository struct {
+	db *sql.DB
+}
+
+func NewRevocationRepository(db *sql.DB) *RevocationRepository {
+	return &RevocationRepository{db: db}
+}
+
+func (r *RevocationRepository) IsAccessTokenRevoked(jti string, sessionID int64, userID, version int) (bool, error) {
+	const q = `
+		SELECT
+		    EXISTS (SELECT 1 FROM revoked_access_tokens WHERE jti = $1)
+		    OR COALESCE((SELECT revoked_at IS NOT NULL FROM sessions WHERE id = $2), TRUE)
+		    OR COALESCE((SELECT token_version <> $4 FROM users WHERE id = $3), TRUE)`
+
+	var revoked bool
+	if err := r.db.QueryRow(q, jti, sessionID, userID, version).Scan(&revoked); err != nil {
+		return false, err
+	}
+	return revoked, nil
+}
+
+// RevokeAccessToken заносит jti в список отозванных до истечения токена
+// и заодно вычищает записи об уже истёкших токенах.
+func (r *RevocationRepository) RevokeAccessToken(jti string, expiresAt time.Time) error {
+	const q = `
+		INSERT INTO revoked_access_tokens (jti, expires_at)
+		VALUES ($1, $2)
+		ON CONFLICT (jti) DO NOTHING`
+
+	if _, err := r.db.Exec(q, jti, expiresAt); err != nil {
+		return err
+	}
+	_, err := r.db.Exec(`DELETE FROM revoked_access_tokens WHERE expires_at < NOW()`)
+	return err
+}
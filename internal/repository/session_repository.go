@@ -0,0 +1,231 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"SpaceBookProject/internal/domain"
+)
+
+var (
+	ErrSessionNotFound    = errors.New("session not found")
+	ErrSessionRevoked     = errors.New("session revoked")
+	ErrSessionExpired     = errors.New("session expired")
+	ErrRefreshTokenReused = errors.New("refresh token reuse detected")
+)
+
+const (
+	RevokeReasonLogout         = "logout"
+	RevokeReasonUser           = "revoked_by_user"
+	RevokeReasonTokenReuse     = "token_reuse"
+	RevokeReasonPasswordChange = "password_change"
+	RevokeReasonSuspended      = "suspended"
+)
+
+const sessionColumns = `s.id, s.user_id, s.user_agent, s.ip, s.created_at, s.last_used_at, s.expires_at, s.revoked_at`
+
+type SessionRepository struct {
+	db *sql.DB
+}
+
+func NewSessionRepository(db *sql.DB) *SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+func scanSession(row scanner, extra ...any) (*domain.Session, error) {
+	s := &domain.Session{}
+	dest := append([]any{
+		&s.ID, &s.UserID, &s.UserAgent, &s.IP,
+		&s.CreatedAt, &s.LastUsedAt, &s.ExpiresAt, &s.RevokedAt,
+	}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Create открывает сессию и сохраняет хеш первого refresh-токена семейства.
+func (r *SessionRepository) Create(s *domain.Session, tokenHash string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	const insertSession = `
+		INSERT INTO sessions (user_id, user_agent, ip, created_at, last_used_at, expires_at)
+		VALUES ($1, $2, $3, NOW(), NOW(), $4)
+		RETURNING id, created_at, last_used_at`
+
+	err = tx.QueryRow(insertSession, s.UserID, s.UserAgent, s.IP, s.ExpiresAt).
+		Scan(&s.ID, &s.CreatedAt, &s.LastUsedAt)
+	if err != nil {
+		return err
+	}
+
+	if err := insertRefreshToken(tx, s.ID, tokenHash); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func insertRefreshToken(tx execer, sessionID int64, tokenHash string) error {
+	const q = `
+		INSERT INTO refresh_tokens (session_id, token_hash, created_at)
+		VALUES ($1, $2, NOW())`
+
+	_, err := tx.Exec(q, sessionID, tokenHash)
+	return err
+}
+
+// Rotate обменивает refresh-токен на новый в пределах той же сессии.
+// Если предъявлен уже использованный токен, значит, его копия утекла:
+// сессия (всё семейство токенов) отзывается и возвращается ErrRefreshTokenReused.
+func (r *SessionRepository) Rotate(tokenHash, newTokenHash string, meta domain.SessionMeta, expiresAt time.Time) (*domain.Session, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	const selectQ = `
+		SELECT ` + sessionColumns + `, rt.id, rt.used_at
+		FROM refresh_tokens rt
+		JOIN sessions s ON s.id = rt.session_id
+		WHERE rt.token_hash = $1
+		FOR UPDATE OF s, rt`
+
+	var (
+		tokenID int64
+		usedAt  *time.Time
+	)
+	s, err := scanSession(tx.QueryRow(selectQ, tokenHash), &tokenID, &usedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrSessionNotFound
+		}
+		return nil, err
+	}
+
+	if s.RevokedAt != nil {
+		return nil, ErrSessionRevoked
+	}
+	if time.Now().After(s.ExpiresAt) {
+		return nil, ErrSessionExpired
+	}
+	if usedAt != nil {
+		if err := revokeSession(tx, s.ID, RevokeReasonTokenReuse); err != nil {
+			return nil, err
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, err
+		}
+		return nil, ErrRefreshTokenReused
+	}
+
+	if _, err := tx.Exec(`UPDATE refresh_tokens SET used_at = NOW() WHERE id = $1`, tokenID); err != nil {
+		return nil, err
+	}
+	if err := insertRefreshToken(tx, s.ID, newTokenHash); err != nil {
+		return nil, err
+	}
+
+	const touchQ = `
+		UPDATE sessions
+		SET last_used_at = NOW(), user_agent = $2, ip = $3, expires_at = $4
+		WHERE id = $1
+		RETURNING last_used_at, user_agent, ip, expires_at`
+
+	if err := tx.QueryRow(touchQ, s.ID, meta.UserAgent, meta.IP, expiresAt).
+		Scan(&s.LastUsedAt, &s.UserAgent, &s.IP, &s.ExpiresAt); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (r *SessionRepository) GetByID(id int64) (*domain.Session, error) {
+	const q = `SELECT ` + sessionColumns + ` FROM sessions s WHERE s.id = $1`
+
+	s, err := scanSession(r.db.QueryRow(q, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrSessionNotFound
+	}
+	return s, err
+}
+
+func (r *SessionRepository) ListActiveByUser(userID int) ([]domain.Session, error) {
+	const q = `
+		SELECT ` + sessionColumns + `
+		FROM sessions s
+		WHERE s.user_id = $1
+		  AND s.revoked_at IS NULL
+		  AND s.expires_at > NOW()
+		ORDER BY s.last_used_at DESC`
+
+	rows, err := r.db.Query(q, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []domain.Session
+	for rows.Next() {
+		s, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, *s)
+	}
+	return res, rows.Err()
+}
+
+// Revoke отзывает сессию пользователя; чужая или уже отозванная сессия
+// даёт ErrSessionNotFound.
+func (r *SessionRepository) Revoke(id int64, userID int, reason string) error {
+	const q = `
+		UPDATE sessions
+		SET revoked_at = NOW(), revoked_reason = $3
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`
+
+	res, err := r.db.Exec(q, id, userID, reason)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+func (r *SessionRepository) RevokeAllForUser(userID int, reason string) error {
+	return revokeUserSessions(r.db, userID, reason)
+}
+
+func revokeUserSessions(q execer, userID int, reason string) error {
+	const query = `
+		UPDATE sessions
+		SET revoked_at = NOW(), revoked_reason = $2
+		WHERE user_id = $1 AND revoked_at IS NULL`
+
+	_, err := q.Exec(query, userID, reason)
+	return err
+}
+
+func revokeSession(tx execer, id int64, reason string) error {
+	const q = `
+		UPDATE sessions
+		SET revoked_at = NOW(), revoked_reason = $2
+		WHERE id = $1 AND revoked_at IS NULL`
+
+	_, err := tx.Exec(q, id, reason)
+	return err
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
@@ -11,15 +12,23 @@ import (
 )
 
 type SpaceFilter struct {
+	OwnerID  *int
 	Query    *string
 	MinPrice *int
 	MaxPrice *int
 	MinArea  *float64
 	MaxArea  *float64
+	// ManagedBy отбирает помещения пользователя и организаций, в которых он состоит.
+	ManagedBy *int
+	// IncludeInactive показывает и выключенные владельцем или снятые
+	// администратором помещения; удалённые не попадают в выборку никогда.
+	IncludeInactive bool
 }
 
 var ErrSpaceNotFound = errors.New("space not found")
 
+const spaceColumns = `id, owner_id, organization_id, title, description, area_m2, price, phone, timezone, slot_minutes, response_hours, instant_booking, instant_max_duration_minutes, instant_min_lead_minutes, instant_verified_only, cancellation_policy, cancellation_tiers, is_active, deleted_at, unpublished_at, unpublish_reason, created_at, updated_at`
+
 type SpaceRepository struct {
 	db *sql.DB
 }
@@ -28,25 +37,63 @@ func NewSpaceRepository(db *sql.DB) *SpaceRepository {
 	return &SpaceRepository{db: db}
 }
 
-func (r *SpaceRepository) GetByID(id int) (*domain.Space, error) {
-	const query = `
-        SELECT id, owner_id, title, description, area_m2, price, phone, created_at, updated_at
-        FROM spaces
-        WHERE id = $1
-    `
-
+func scanSpace(row scanner) (*domain.Space, error) {
 	s := &domain.Space{}
-	err := r.db.QueryRow(query, id).Scan(
+	var tiers []byte
+	err := row.Scan(
 		&s.ID,
 		&s.OwnerID,
+		&s.OrganizationID,
 		&s.Title,
 		&s.Description,
 		&s.AreaM2,
 		&s.Price,
 		&s.Phone,
+		&s.Timezone,
+		&s.SlotMinutes,
+		&s.ResponseHours,
+		&s.InstantBooking.Enabled,
+		&s.InstantBooking.MaxDurationMinutes,
+		&s.InstantBooking.MinLeadMinutes,
+		&s.InstantBooking.VerifiedOnly,
+		&s.CancellationPolicy.Kind,
+		&tiers,
+		&s.IsActive,
+		&s.DeletedAt,
+		&s.UnpublishedAt,
+		&s.UnpublishReason,
 		&s.CreatedAt,
 		&s.UpdatedAt,
 	)
+	if err != nil {
+		return nil, err
+	}
+	if s.CancellationPolicy, err = cancellationPolicy(s.CancellationPolicy.Kind, tiers); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// cancellationPolicy собирает политику из колонок: ступени хранятся только
+// у custom, у стандартных политик они берутся из кода.
+func cancellationPolicy(kind domain.CancellationPolicyKind, tiers []byte) (domain.CancellationPolicy, error) {
+	p := domain.CancellationPolicy{Kind: kind}
+	if kind == domain.CancellationCustom {
+		if err := json.Unmarshal(tiers, &p.Tiers); err != nil {
+			return p, err
+		}
+	}
+	return p.Normalize()
+}
+
+func (r *SpaceRepository) GetByID(id int) (*domain.Space, error) {
+	const query = `
+        SELECT ` + spaceColumns + `
+        FROM spaces
+        WHERE id = $1
+    `
+
+	s, err := scanSpace(r.db.QueryRow(query, id))
 	if err == sql.ErrNoRows {
 		return nil, ErrSpaceNotFound
 	}
@@ -58,15 +105,30 @@ func (r *SpaceRepository) GetByID(id int) (*domain.Space, error) {
 
 func (r *SpaceRepository) ListFiltered(f SpaceFilter) ([]domain.Space, error) {
 	query := `
-		SELECT id, owner_id, title, description, area_m2, price, phone, created_at, updated_at
+		SELECT ` + spaceColumns + `
 		FROM spaces
 	`
 	var (
-		conds []string
+		conds = []string{"deleted_at IS NULL"}
 		args  []any
 		i     = 1
 	)
 
+	if !f.IncludeInactive {
+		conds = append(conds, "is_active", "unpublished_at IS NULL")
+	}
+	if f.OwnerID != nil {
+		conds = append(conds, fmt.Sprintf("owner_id = $%d", i))
+		args = append(args, *f.OwnerID)
+		i++
+	}
+	if f.ManagedBy != nil {
+		conds = append(conds, fmt.Sprintf(`(owner_id = $%d OR organization_id IN (
+			SELECT organization_id FROM organization_members WHERE user_id = $%d))`, i, i))
+		args = append(args, *f.ManagedBy)
+		i++
+	}
+
 	if f.Query != nil && *f.Query != "" {
 		conds = append(conds, fmt.Sprintf("(title ILIKE $%d OR description ILIKE $%d)", i, i+1))
 		pattern := "%" + *f.Query + "%"
@@ -94,9 +156,7 @@ func (r *SpaceRepository) ListFiltered(f SpaceFilter) ([]domain.Space, error) {
 		i++
 	}
 
-	if len(conds) > 0 {
-		query += " WHERE " + strings.Join(conds, " AND ")
-	}
+	query += " WHERE " + strings.Join(conds, " AND ")
 
 	query += " ORDER BY created_at DESC, id DESC"
 
@@ -108,21 +168,11 @@ func (r *SpaceRepository) ListFiltered(f SpaceFilter) ([]domain.Space, error) {
 
 	var result []domain.Space
 	for rows.Next() {
-		var s domain.Space
-		if err := rows.Scan(
-			&s.ID,
-			&s.OwnerID,
-			&s.Title,
-			&s.Description,
-			&s.AreaM2,
-			&s.Price,
-			&s.Phone,
-			&s.CreatedAt,
-			&s.UpdatedAt,
-		); err != nil {
+		s, err := scanSpace(rows)
+		if err != nil {
 			return nil, err
 		}
-		result = append(result, s)
+		result = append(result, *s)
 	}
 	return result, rows.Err()
 }
@@ -131,21 +181,123 @@ func (r *SpaceRepository) Create(space *domain.Space) error {
 	now := time.Now()
 
 	query := `
-		INSERT INTO spaces (owner_id, title, description, area_m2, price, phone, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-		RETURNING id, created_at, updated_at`
+		INSERT INTO spaces (owner_id, organization_id, title, description, area_m2, price, phone, timezone, slot_minutes, response_hours, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING id, is_active, created_at, updated_at`
 
 	err := r.db.QueryRow(
 		query,
 		space.OwnerID,
+		space.OrganizationID,
 		space.Title,
 		space.Description,
 		space.AreaM2,
 		space.Price,
 		space.Phone,
+		space.Timezone,
+		space.SlotMinutes,
+		space.ResponseHours,
 		now,
 		now,
-	).Scan(&space.ID, &space.CreatedAt, &space.UpdatedAt)
+	).Scan(&space.ID, &space.IsActive, &space.CreatedAt, &space.UpdatedAt)
 
 	return err
 }
+
+func (r *SpaceRepository) Update(space *domain.Space) error {
+	const query = `
+		UPDATE spaces
+		SET title = $1, description = $2, area_m2 = $3, price = $4, phone = $5,
+		    timezone = $6, slot_minutes = $7, response_hours = $8, updated_at = NOW()
+		WHERE id = $9 AND deleted_at IS NULL
+		RETURNING updated_at`
+
+	err := r.db.QueryRow(
+		query,
+		space.Title,
+		space.Description,
+		space.AreaM2,
+		space.Price,
+		space.Phone,
+		space.Timezone,
+		space.SlotMinutes,
+		space.ResponseHours,
+		space.ID,
+	).Scan(&space.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return ErrSpaceNotFound
+	}
+	return err
+}
+
+func (r *SpaceRepository) SetActive(id int, active bool) error {
+	const query = `
+		UPDATE spaces
+		SET is_active = $1, updated_at = NOW()
+		WHERE id = $2 AND deleted_at IS NULL`
+
+	return r.execAffectingSpace(query, active, id)
+}
+
+// SetOrganization передаёт помещение под управление организации; nil отвязывает.
+func (r *SpaceRepository) SetOrganization(id int, orgID *int) error {
+	const query = `
+		UPDATE spaces
+		SET organization_id = $1, updated_at = NOW()
+		WHERE id = $2 AND deleted_at IS NULL`
+
+	return r.execAffectingSpace(query, orgID, id)
+}
+
+func (r *SpaceRepository) SetInstantBooking(id int, rules domain.InstantBookingRules) error {
+	const query = `
+		UPDATE spaces
+		SET instant_booking = $1, instant_max_duration_minutes = $2,
+		    instant_min_lead_minutes = $3, instant_verified_only = $4, updated_at = NOW()
+		WHERE id = $5 AND deleted_at IS NULL`
+
+	return r.execAffectingSpace(query, rules.Enabled, rules.MaxDurationMinutes, rules.MinLeadMinutes, rules.VerifiedOnly, id)
+}
+
+// SetCancellationPolicy сохраняет уже нормализованную политику.
+func (r *SpaceRepository) SetCancellationPolicy(id int, p domain.CancellationPolicy) error {
+	tiers := []byte("[]")
+	if p.Kind == domain.CancellationCustom {
+		var err error
+		if tiers, err = json.Marshal(p.Tiers); err != nil {
+			return err
+		}
+	}
+
+	const query = `
+		UPDATE spaces
+		SET cancellation_policy = $1, cancellation_tiers = $2, updated_at = NOW()
+		WHERE id = $3 AND deleted_at IS NULL`
+
+	return r.execAffectingSpace(query, p.Kind, tiers, id)
+}
+
+// SoftDelete помечает помещение удалённым, сохраняя строку ради истории броней.
+func (r *SpaceRepository) SoftDelete(id int) error {
+	const query = `
+		UPDATE spaces
+		SET deleted_at = NOW(), is_active = FALSE, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL`
+
+	return r.execAffectingSpace(query, id)
+}
+
+func (r *SpaceRepository) execAffectingSpace(query string, args ...any) error {
+	res, err := r.db.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrSpaceNotFound
+	}
+	return nil
+}
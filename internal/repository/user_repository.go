@@ -3,9 +3,13 @@ package repository
 import (
 	"database/sql"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"SpaceBookProject/internal/domain"
+
+	"github.com/lib/pq"
 )
 
 var (
@@ -13,6 +17,8 @@ var (
 	ErrUserAlreadyExists = errors.New("user already exists")
 )
 
+const userColumns = `id, email, password_hash, roles, first_name, last_name, phone, locale, token_version, email_verified_at, suspended_at, suspension_reason, created_at, updated_at`
+
 type UserRepository struct {
 	db *sql.DB
 }
@@ -21,10 +27,55 @@ func NewUserRepository(db *sql.DB) *UserRepository {
 	return &UserRepository{db: db}
 }
 
+func scanUser(row scanner) (*domain.User, error) {
+	user := &domain.User{}
+	var roles pq.StringArray
+	err := row.Scan(
+		&user.ID,
+		&user.Email,
+		&user.PasswordHash,
+		&roles,
+		&user.FirstName,
+		&user.LastName,
+		&user.Phone,
+		&user.Locale,
+		&user.TokenVersion,
+		&user.EmailVerifiedAt,
+		&user.SuspendedAt,
+		&user.SuspensionReason,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	user.Roles = userRoles(roles)
+	return user, nil
+}
+
+func userRoles(names pq.StringArray) []domain.UserRole {
+	roles := make([]domain.UserRole, len(names))
+	for i, name := range names {
+		roles[i] = domain.UserRole(name)
+	}
+	return roles
+}
+
+func roleNames(roles []domain.UserRole) pq.StringArray {
+	names := make(pq.StringArray, len(roles))
+	for i, r := range roles {
+		names[i] = string(r)
+	}
+	return names
+}
+
 func (r *UserRepository) Create(user *domain.User) error {
 	query := `
-		INSERT INTO users (email, password_hash, role, first_name, last_name, phone, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO users (email, password_hash, roles, first_name, last_name, phone, locale, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		RETURNING id`
 
 	now := time.Now()
@@ -35,10 +86,11 @@ func (r *UserRepository) Create(user *domain.User) error {
 		query,
 		user.Email,
 		user.PasswordHash,
-		user.Role,
+		roleNames(user.Roles),
 		user.FirstName,
 		user.LastName,
 		user.Phone,
+		user.Locale,
 		user.CreatedAt,
 		user.UpdatedAt,
 	).Scan(&user.ID)
@@ -54,68 +106,109 @@ func (r *UserRepository) Create(user *domain.User) error {
 }
 
 func (r *UserRepository) GetByEmail(email string) (*domain.User, error) {
-	user := &domain.User{}
 	query := `
-		SELECT id, email, password_hash, role, first_name, last_name, phone, created_at, updated_at
+		SELECT ` + userColumns + `
 		FROM users
 		WHERE email = $1`
 
-	err := r.db.QueryRow(query, email).Scan(
-		&user.ID,
-		&user.Email,
-		&user.PasswordHash,
-		&user.Role,
-		&user.FirstName,
-		&user.LastName,
-		&user.Phone,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-	)
-
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, ErrUserNotFound
-		}
-		return nil, err
-	}
-
-	return user, nil
+	return scanUser(r.db.QueryRow(query, email))
 }
 
 func (r *UserRepository) GetByID(id int) (*domain.User, error) {
-	user := &domain.User{}
 	query := `
-		SELECT id, email, password_hash, role, first_name, last_name, phone, created_at, updated_at
+		SELECT ` + userColumns + `
 		FROM users
 		WHERE id = $1`
 
-	err := r.db.QueryRow(query, id).Scan(
-		&user.ID,
-		&user.Email,
-		&user.PasswordHash,
-		&user.Role,
-		&user.FirstName,
-		&user.LastName,
-		&user.Phone,
-		&user.CreatedAt,
-		&user.UpdatedAt,
+	return scanUser(r.db.QueryRow(query, id))
+}
+
+// UserFilter — параметры поиска пользователей в админке.
+type UserFilter struct {
+	Query     *string
+	Role      *domain.UserRole
+	Suspended *bool
+	Limit     int
+	Offset    int
+}
+
+func (r *UserRepository) List(f UserFilter) ([]domain.User, error) {
+	query := `
+		SELECT ` + userColumns + `
+		FROM users
+	`
+	var (
+		conds []string
+		args  []any
+		i     = 1
 	)
 
+	if f.Query != nil && *f.Query != "" {
+		conds = append(conds, fmt.Sprintf("(email ILIKE $%d OR first_name ILIKE $%d OR last_name ILIKE $%d)", i, i, i))
+		args = append(args, "%"+*f.Query+"%")
+		i++
+	}
+	if f.Role != nil {
+		conds = append(conds, fmt.Sprintf("$%d = ANY(roles)", i))
+		args = append(args, *f.Role)
+		i++
+	}
+	if f.Suspended != nil {
+		if *f.Suspended {
+			conds = append(conds, "suspended_at IS NOT NULL")
+		} else {
+			conds = append(conds, "suspended_at IS NULL")
+		}
+	}
+
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+	query += fmt.Sprintf(" ORDER BY id DESC LIMIT $%d OFFSET $%d", i, i+1)
+	args = append(args, f.Limit, f.Offset)
+
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []domain.User
+	for rows.Next() {
+		u, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *u)
+	}
+	return result, rows.Err()
+}
+
+// AddRole добавляет роль в набор, если её там ещё нет. Токены не
+// отзываются: в старых просто нет новой роли.
+func (r *UserRepository) AddRole(userID int, role domain.UserRole) ([]domain.UserRole, error) {
+	const query = `
+		UPDATE users
+		SET roles = CASE WHEN $1 = ANY(roles) THEN roles ELSE array_append(roles, $1) END,
+		    updated_at = NOW()
+		WHERE id = $2
+		RETURNING roles`
+
+	var roles pq.StringArray
+	if err := r.db.QueryRow(query, string(role), userID).Scan(&roles); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, ErrUserNotFound
 		}
 		return nil, err
 	}
-
-	return user, nil
+	return userRoles(roles), nil
 }
 
 func (r *UserRepository) Update(user *domain.User) error {
 	query := `
 		UPDATE users
-		SET first_name = $1, last_name = $2, phone = $3, updated_at = $4
-		WHERE id = $5`
+		SET first_name = $1, last_name = $2, phone = $3, locale = $4, updated_at = $5
+		WHERE id = $6`
 
 	user.UpdatedAt = time.Now()
 
@@ -124,6 +217,7 @@ func (r *UserRepository) Update(user *domain.User) error {
 		user.FirstName,
 		user.LastName,
 		user.Phone,
+		user.Locale,
 		user.UpdatedAt,
 		user.ID,
 	)
@@ -144,43 +238,46 @@ func (r *UserRepository) Update(user *domain.User) error {
 	return nil
 }
 
-func (r *UserRepository) SaveRefreshToken(userID int, token string, expiresAt time.Time) error {
-	query := `
-		INSERT INTO refresh_tokens (user_id, token, expires_at, created_at)
-		VALUES ($1, $2, $3, $4)
-		ON CONFLICT (user_id) 
-		DO UPDATE SET token = $2, expires_at = $3, created_at = $4`
+// UpdatePassword меняет хеш пароля и увеличивает token_version,
+// отзывая тем самым все выданные access-токены.
+func (r *UserRepository) UpdatePassword(userID int, passwordHash string) error {
+	const query = `
+		UPDATE users
+		SET password_hash = $1, token_version = token_version + 1, updated_at = NOW()
+		WHERE id = $2`
 
-	_, err := r.db.Exec(query, userID, token, expiresAt, time.Now())
-	return err
+	return r.execAffectingUser(query, passwordHash, userID)
 }
 
-func (r *UserRepository) GetRefreshToken(token string) (int, error) {
-	var userID int
-	var expiresAt time.Time
-
-	query := `
-		SELECT user_id, expires_at
-		FROM refresh_tokens
-		WHERE token = $1`
+func (r *UserRepository) BumpTokenVersion(userID int) error {
+	const query = `
+		UPDATE users
+		SET token_version = token_version + 1, updated_at = NOW()
+		WHERE id = $1`
 
-	err := r.db.QueryRow(query, token).Scan(&userID, &expiresAt)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return 0, ErrUserNotFound
-		}
-		return 0, err
-	}
+	return r.execAffectingUser(query, userID)
+}
 
-	if time.Now().After(expiresAt) {
-		return 0, errors.New("refresh token expired")
-	}
+func (r *UserRepository) MarkEmailVerified(userID int) error {
+	const query = `
+		UPDATE users
+		SET email_verified_at = COALESCE(email_verified_at, NOW()), updated_at = NOW()
+		WHERE id = $1`
 
-	return userID, nil
+	return r.execAffectingUser(query, userID)
 }
 
-func (r *UserRepository) DeleteRefreshToken(userID int) error {
-	query := `DELETE FROM refresh_tokens WHERE user_id = $1`
-	_, err := r.db.Exec(query, userID)
-	return err
+func (r *UserRepository) execAffectingUser(query string, args ...any) error {
+	result, err := r.db.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrUserNotFound
+	}
+	return nil
 }
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"SpaceBookProject/internal/domain"
+)
+
+var ErrUserTokenInvalid = errors.New("token is invalid or expired")
+
+// UserTokenRepository хранит хеши одноразовых токенов для сброса пароля
+// и подтверждения email.
+type UserTokenRepository struct {
+	db *sql.DB
+}
+
+func NewUserTokenRepository(db *sql.DB) *UserTokenRepository {
+	return &UserTokenRepository{db: db}
+}
+
+// Create сохраняет новый токен, погашая ранее выданные неиспользованные
+// токены того же назначения: действует только последняя ссылка из письма.
+func (r *UserTokenRepository) Create(userID int, purpose domain.UserTokenPurpose, tokenHash string, expiresAt time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	const expireQ = `
+		UPDATE user_tokens
+		SET used_at = NOW()
+		WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL`
+
+	if _, err := tx.Exec(expireQ, userID, purpose); err != nil {
+		return err
+	}
+
+	const insertQ = `
+		INSERT INTO user_tokens (user_id, purpose, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, NOW())`
+
+	if _, err := tx.Exec(insertQ, userID, purpose, tokenHash, expiresAt); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Consume гасит токен и возвращает его владельца. Использованный,
+// просроченный или неизвестный токен даёт ErrUserTokenInvalid.
+func (r *UserTokenRepository) Consume(purpose domain.UserTokenPurpose, tokenHash string) (int, error) {
+	const q = `
+		UPDATE user_tokens
+		SET used_at = NOW()
+		WHERE token_hash = $1
+		  AND purpose = $2
+		  AND used_at IS NULL
+		  AND expires_at > NOW()
+		RETURNING user_id`
+
+	var userID int
+	err := r.db.QueryRow(q, tokenHash, purpose).Scan(&userID)
+	if err == sql.ErrNoRows {
+		return 0, ErrUserTokenInvalid
+	}
+	if err != nil {
+		return 0, err
+	}
+	return userID, nil
+}
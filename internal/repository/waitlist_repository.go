@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"SpaceBookProject/internal/domain"
+)
+
+var (
+	ErrWaitlistEntryNotFound = errors.New("waitlist entry not found")
+	ErrAlreadyWaitlisted     = errors.New("you are already on the waitlist for these dates")
+)
+
+const waitlistColumns = `id, space_id, tenant_id, date_from, date_to, auto_book, status, booking_id, notified_at, created_at`
+
+type WaitlistRepository struct {
+	db *sql.DB
+}
+
+func NewWaitlistRepository(db *sql.DB) *WaitlistRepository {
+	return &WaitlistRepository{db: db}
+}
+
+func scanWaitlistEntry(row scanner) (*domain.WaitlistEntry, error) {
+	e := &domain.WaitlistEntry{}
+	err := row.Scan(
+		&e.ID, &e.SpaceID, &e.TenantID, &e.DateFrom, &e.DateTo,
+		&e.AutoBook, &e.Status, &e.BookingID, &e.NotifiedAt, &e.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func scanWaitlistEntries(rows *sql.Rows) ([]domain.WaitlistEntry, error) {
+	defer rows.Close()
+
+	var result []domain.WaitlistEntry
+	for rows.Next() {
+		e, err := scanWaitlistEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *e)
+	}
+	return result, rows.Err()
+}
+
+func (r *WaitlistRepository) Create(e *domain.WaitlistEntry) error {
+	const q = `
+		INSERT INTO waitlist_entries (space_id, tenant_id, date_from, date_to, auto_book, created_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		RETURNING id, status, created_at`
+
+	err := r.db.QueryRow(q, e.SpaceID, e.TenantID, e.DateFrom, e.DateTo, e.AutoBook).
+		Scan(&e.ID, &e.Status, &e.CreatedAt)
+	if isPQError(err, pqUniqueViolation) {
+		return ErrAlreadyWaitlisted
+	}
+	return err
+}
+
+func (r *WaitlistRepository) ListByTenant(tenantID int) ([]domain.WaitlistEntry, error) {
+	const q = `
+		SELECT ` + waitlistColumns + `
+		FROM waitlist_entries
+		WHERE tenant_id = $1
+		ORDER BY created_at DESC, id DESC`
+
+	rows, err := r.db.Query(q, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return scanWaitlistEntries(rows)
+}
+
+// ListWaiting возвращает очередь помещения в порядке записи.
+func (r *WaitlistRepository) ListWaiting(spaceID int) ([]domain.WaitlistEntry, error) {
+	const q = `
+		SELECT ` + waitlistColumns + `
+		FROM waitlist_entries
+		WHERE space_id = $1 AND status = 'waiting'
+		ORDER BY created_at, id`
+
+	rows, err := r.db.Query(q, spaceID)
+	if err != nil {
+		return nil, err
+	}
+	return scanWaitlistEntries(rows)
+}
+
+// ListWaitingOverlapping возвращает ждущих, чьи даты пересекаются с
+// [from, to), в порядке очереди.
+func (r *WaitlistRepository) ListWaitingOverlapping(spaceID int, from, to time.Time) ([]domain.WaitlistEntry, error) {
+	const q = `
+		SELECT ` + waitlistColumns + `
+		FROM waitlist_entries
+		WHERE space_id = $1 AND status = 'waiting'
+		  AND tstzrange(date_from, date_to, '[)') && tstzrange($2, $3, '[)')
+		ORDER BY created_at, id`
+
+	rows, err := r.db.Query(q, spaceID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return scanWaitlistEntries(rows)
+}
+
+func (r *WaitlistRepository) Cancel(id, tenantID int) error {
+	const q = `
+		UPDATE waitlist_entries
+		SET status = 'cancelled'
+		WHERE id = $1 AND tenant_id = $2 AND status = 'waiting'`
+
+	return execAffecting(r.db, ErrWaitlistEntryNotFound, q, id, tenantID)
+}
+
+// Claim выводит запись из очереди в notified до того, как по ней что-то
+// сделано. false означает, что запись уже обработана: повтор события не
+// должен ни бронировать, ни уведомлять второй раз.
+func (r *WaitlistRepository) Claim(id int) (bool, error) {
+	const q = `
+		UPDATE waitlist_entries
+		SET status = 'notified', notified_at = NOW()
+		WHERE id = $1 AND status = 'waiting'`
+
+	err := execAffecting(r.db, ErrWaitlistEntryNotFound, q, id)
+	if errors.Is(err, ErrWaitlistEntryNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// LinkBooking отмечает взятую запись как booked заявкой bookingID.
+func (r *WaitlistRepository) LinkBooking(id, bookingID int) error {
+	const q = `
+		UPDATE waitlist_entries
+		SET status = 'booked', booking_id = $2
+		WHERE id = $1 AND status = 'notified'`
+
+	return execAffecting(r.db, ErrWaitlistEntryNotFound, q, id, bookingID)
+}
+
+// Demand сводит очередь по помещениям пользователя и его организаций.
+func (r *WaitlistRepository) Demand(userID int) ([]domain.WaitlistDemand, error) {
+	const q = `
+		SELECT s.id, s.title, COUNT(w.id), MIN(w.date_from), MAX(w.date_to)
+		FROM spaces s
+		JOIN waitlist_entries w ON w.space_id = s.id AND w.status = 'waiting'
+		WHERE s.deleted_at IS NULL
+		  AND (s.owner_id = $1 OR s.organization_id IN (
+		      SELECT organization_id FROM organization_members WHERE user_id = $1))
+		GROUP BY s.id, s.title
+		ORDER BY COUNT(w.id) DESC, s.id`
+
+	rows, err := r.db.Query(q, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []domain.WaitlistDemand
+	for rows.Next() {
+		var d domain.WaitlistDemand
+		if err := rows.Scan(&d.SpaceID, &d.SpaceTitle, &d.Waiting, &d.EarliestFrom, &d.LatestTo); err != nil {
+			return nil, err
+		}
+		result = append(result, d)
+	}
+	return result, rows.Err()
+}
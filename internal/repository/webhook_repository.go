@@ -0,0 +1,336 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"SpaceBookProject/internal/domain"
+
+	"github.com/lib/pq"
+)
+
+var (
+	ErrWebhookNotFound         = errors.New("webhook endpoint not found")
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+)
+
+const webhookEndpointColumns = `id, user_id, url, secret, event_types, is_active, created_at, updated_at`
+
+const webhookDeliveryColumns = `id, endpoint_id, event_id, event_type, payload, status, attempts,
+        next_attempt_at, response_status, last_error, created_at, delivered_at`
+
+// ClaimedWebhookDelivery — доставка, захваченная воркером, вместе с адресом
+// и секретом endpoint'а на момент захвата.
+type ClaimedWebhookDelivery struct {
+	Delivery domain.WebhookDelivery
+	URL      string
+	Secret   string
+}
+
+type WebhookRepository struct {
+	db *sql.DB
+}
+
+func NewWebhookRepository(db *sql.DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+func scanWebhookEndpoint(row scanner) (*domain.WebhookEndpoint, error) {
+	e := &domain.WebhookEndpoint{}
+	var types []string
+	err := row.Scan(
+		&e.ID, &e.UserID, &e.URL, &e.Secret, pq.Array(&types),
+		&e.IsActive, &e.CreatedAt, &e.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	e.EventTypes = make([]domain.BookingEventType, len(types))
+	for i, t := range types {
+		e.EventTypes[i] = domain.BookingEventType(t)
+	}
+	return e, nil
+}
+
+func scanWebhookDelivery(row scanner) (*domain.WebhookDelivery, error) {
+	d := &domain.WebhookDelivery{}
+	var payload []byte
+	err := row.Scan(
+		&d.ID, &d.EndpointID, &d.EventID, &d.EventType, &payload, &d.Status, &d.Attempts,
+		&d.NextAttemptAt, &d.ResponseStatus, &d.LastError, &d.CreatedAt, &d.DeliveredAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	d.Payload = payload
+	return d, nil
+}
+
+func (r *WebhookRepository) CreateEndpoint(e *domain.WebhookEndpoint) error {
+	const q = `
+		INSERT INTO webhook_endpoints (user_id, url, secret, event_types, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, TRUE, NOW(), NOW())
+		RETURNING id, is_active, created_at, updated_at`
+
+	types := make([]string, len(e.EventTypes))
+	for i, t := range e.EventTypes {
+		types[i] = string(t)
+	}
+
+	return r.db.QueryRow(q, e.UserID, e.URL, e.Secret, pq.Array(types)).
+		Scan(&e.ID, &e.IsActive, &e.CreatedAt, &e.UpdatedAt)
+}
+
+func (r *WebhookRepository) GetEndpoint(id int) (*domain.WebhookEndpoint, error) {
+	const q = `SELECT ` + webhookEndpointColumns + ` FROM webhook_endpoints WHERE id = $1`
+
+	e, err := scanWebhookEndpoint(r.db.QueryRow(q, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrWebhookNotFound
+	}
+	return e, err
+}
+
+func (r *WebhookRepository) ListEndpointsByUser(userID int) ([]domain.WebhookEndpoint, error) {
+	const q = `
+		SELECT ` + webhookEndpointColumns + `
+		FROM webhook_endpoints
+		WHERE user_id = $1
+		ORDER BY id`
+
+	return r.queryEndpoints(q, userID)
+}
+
+// ListEndpointsForEvent возвращает активные endpoint'ы, которые должны
+// получить событие и подписаны на его тип: владельца помещения и
+// администраторов, которым приходят события всех помещений.
+func (r *WebhookRepository) ListEndpointsForEvent(evt domain.BookingEvent) ([]domain.WebhookEndpoint, error) {
+	const q = `
+		SELECT ` + webhookEndpointColumns + `
+		FROM webhook_endpoints
+		WHERE is_active
+		  AND (cardinality(event_types) = 0 OR $2 = ANY(event_types))
+		  AND (user_id = (SELECT owner_id FROM spaces WHERE id = $1)
+		       OR user_id IN (SELECT id FROM users WHERE 'admin' = ANY(roles)))
+		ORDER BY id`
+
+	return r.queryEndpoints(q, evt.SpaceID, string(evt.Type))
+}
+
+func (r *WebhookRepository) queryEndpoints(q string, args ...any) ([]domain.WebhookEndpoint, error) {
+	rows, err := r.db.Query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []domain.WebhookEndpoint
+	for rows.Next() {
+		e, err := scanWebhookEndpoint(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, *e)
+	}
+	return res, rows.Err()
+}
+
+func (r *WebhookRepository) DeleteEndpoint(id int) error {
+	res, err := r.db.Exec(`DELETE FROM webhook_endpoints WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrWebhookNotFound
+	}
+	return nil
+}
+
+// EnqueueDelivery ставит доставку в очередь. Повторная постановка того же
+// события на тот же endpoint игнорируется, поэтому повтор outbox-события
+// не приводит к дублям.
+func (r *WebhookRepository) EnqueueDelivery(d *domain.WebhookDelivery) error {
+	const q = `
+		INSERT INTO webhook_deliveries (endpoint_id, event_id, event_type, payload, status, next_attempt_at, created_at)
+		VALUES ($1, $2, $3, $4, 'pending', NOW(), NOW())
+		ON CONFLICT (endpoint_id, event_id) DO NOTHING`
+
+	_, err := r.db.Exec(q, d.EndpointID, d.EventID, d.EventType, []byte(d.Payload))
+	return err
+}
+
+func (r *WebhookRepository) ClaimDeliveries(limit int, lease time.Duration) ([]ClaimedWebhookDelivery, error) {
+	const q = `
+		WITH claimed AS (
+		    UPDATE webhook_deliveries
+		    SET locked_until = NOW() + make_interval(secs => $2),
+		        attempts = attempts + 1
+		    WHERE id IN (
+		        SELECT d.id
+		        FROM webhook_deliveries d
+		        JOIN webhook_endpoints e ON e.id = d.endpoint_id
+		        WHERE d.status = 'pending'
+		          AND e.is_active
+		          AND d.next_attempt_at <= NOW()
+		          AND (d.locked_until IS NULL OR d.locked_until < NOW())
+		        ORDER BY d.id
+		        LIMIT $1
+		        FOR UPDATE OF d SKIP LOCKED
+		    )
+		    RETURNING ` + webhookDeliveryColumns + `
+		)
+		SELECT c.*, e.url, e.secret
+		FROM claimed c
+		JOIN webhook_endpoints e ON e.id = c.endpoint_id
+		ORDER BY c.id`
+
+	rows, err := r.db.Query(q, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []ClaimedWebhookDelivery
+	for rows.Next() {
+		var (
+			c       ClaimedWebhookDelivery
+			payload []byte
+		)
+		d := &c.Delivery
+		if err := rows.Scan(
+			&d.ID, &d.EndpointID, &d.EventID, &d.EventType, &payload, &d.Status, &d.Attempts,
+			&d.NextAttemptAt, &d.ResponseStatus, &d.LastError, &d.CreatedAt, &d.DeliveredAt,
+			&c.URL, &c.Secret,
+		); err != nil {
+			return nil, err
+		}
+		d.Payload = payload
+		res = append(res, c)
+	}
+	return res, rows.Err()
+}
+
+func (r *WebhookRepository) RecordAttempt(a *domain.WebhookDeliveryAttempt) error {
+	const q = `
+		INSERT INTO webhook_delivery_attempts (delivery_id, response_status, error, duration_ms, attempted_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		RETURNING id, attempted_at`
+
+	return r.db.QueryRow(q, a.DeliveryID, a.ResponseStatus, a.Error, a.DurationMs).Scan(&a.ID, &a.AttemptedAt)
+}
+
+func (r *WebhookRepository) MarkDelivered(id int64, responseStatus int) error {
+	const q = `
+		UPDATE webhook_deliveries
+		SET status = 'delivered', delivered_at = NOW(), locked_until = NULL,
+		    response_status = $2, last_error = NULL
+		WHERE id = $1`
+
+	_, err := r.db.Exec(q, id, responseStatus)
+	return err
+}
+
+func (r *WebhookRepository) MarkRetry(id int64, responseStatus *int, reason string, retryAt time.Time) error {
+	const q = `
+		UPDATE webhook_deliveries
+		SET next_attempt_at = $4, locked_until = NULL, response_status = $2, last_error = $3
+		WHERE id = $1`
+
+	_, err := r.db.Exec(q, id, responseStatus, reason, retryAt)
+	return err
+}
+
+func (r *WebhookRepository) MarkFailed(id int64, responseStatus *int, reason string) error {
+	const q = `
+		UPDATE webhook_deliveries
+		SET status = 'failed', locked_until = NULL, response_status = $2, last_error = $3
+		WHERE id = $1`
+
+	_, err := r.db.Exec(q, id, responseStatus, reason)
+	return err
+}
+
+func (r *WebhookRepository) GetDelivery(id int64) (*domain.WebhookDelivery, error) {
+	const q = `SELECT ` + webhookDeliveryColumns + ` FROM webhook_deliveries WHERE id = $1`
+
+	d, err := scanWebhookDelivery(r.db.QueryRow(q, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrWebhookDeliveryNotFound
+	}
+	return d, err
+}
+
+func (r *WebhookRepository) ListDeliveries(endpointID, limit int) ([]domain.WebhookDelivery, error) {
+	const q = `
+		SELECT ` + webhookDeliveryColumns + `
+		FROM webhook_deliveries
+		WHERE endpoint_id = $1
+		ORDER BY id DESC
+		LIMIT $2`
+
+	rows, err := r.db.Query(q, endpointID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []domain.WebhookDelivery
+	for rows.Next() {
+		d, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, *d)
+	}
+	return res, rows.Err()
+}
+
+func (r *WebhookRepository) ListAttempts(deliveryID int64) ([]domain.WebhookDeliveryAttempt, error) {
+	const q = `
+		SELECT id, delivery_id, response_status, error, duration_ms, attempted_at
+		FROM webhook_delivery_attempts
+		WHERE delivery_id = $1
+		ORDER BY id`
+
+	rows, err := r.db.Query(q, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []domain.WebhookDeliveryAttempt
+	for rows.Next() {
+		var a domain.WebhookDeliveryAttempt
+		if err := rows.Scan(&a.ID, &a.DeliveryID, &a.ResponseStatus, &a.Error, &a.DurationMs, &a.AttemptedAt); err != nil {
+			return nil, err
+		}
+		res = append(res, a)
+	}
+	return res, rows.Err()
+}
+
+// Redeliver возвращает доставку в очередь с обнулённым счётчиком попыток.
+func (r *WebhookRepository) Redeliver(id int64) error {
+	const q = `
+		UPDATE webhook_deliveries
+		SET status = 'pending', attempts = 0, next_attempt_at = NOW(), locked_until = NULL
+		WHERE id = $1`
+
+	res, err := r.db.Exec(q, id)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrWebhookDeliveryNotFound
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"SpaceBookProject/internal/domain"
+)
+
+// testDB подключается к базе TEST_DATABASE_URL с применёнными миграциями;
+// без неё тесты репозиториев пропускаются.
+func testDB(t *testing.T) *sql.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Ping(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestListEndpointsForEvent(t *testing.T) {
+	db := testDB(t)
+	users := NewUserRepository(db)
+	spaces := NewSpaceRepository(db)
+	webhooks := NewWebhookRepository(db)
+
+	suffix := time.Now().UnixNano()
+	newUser := func(name string, role domain.UserRole) *domain.User {
+		u := &domain.User{
+			Email:     fmt.Sprintf("%s-%d@example.test", name, suffix),
+			Roles:     []domain.UserRole{role},
+			FirstName: name,
+			Locale:    "en",
+		}
+		if err := users.Create(u); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { db.Exec(`DELETE FROM users WHERE id = $1`, u.ID) })
+		return u
+	}
+	owner := newUser("owner", domain.RoleOwner)
+	other := newUser("other", domain.RoleOwner)
+	admin := newUser("admin", domain.RoleAdmin)
+
+	sp := &domain.Space{OwnerID: owner.ID, Title: "Room", AreaM2: 10, Price: 100, Timezone: "UTC", SlotMinutes: 60, ResponseHours: 24}
+	if err := spaces.Create(sp); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Exec(`DELETE FROM spaces WHERE id = $1`, sp.ID) })
+
+	newEndpoint := func(u *domain.User, types ...domain.BookingEventType) int {
+		e := &domain.WebhookEndpoint{UserID: u.ID, URL: "https://example.test/hook", Secret: "secret", EventTypes: types}
+		if err := webhooks.CreateEndpoint(e); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { db.Exec(`DELETE FROM webhook_endpoints WHERE id = $1`, e.ID) })
+		return e.ID
+	}
+	ownerEndpoint := newEndpoint(owner)
+	adminEndpoint := newEndpoint(admin)
+	adminCancelled := newEndpoint(admin, domain.BookingEventCancelled)
+	otherEndpoint := newEndpoint(other)
+
+	// в базе могут быть и другие администраторы, поэтому проверяются только
+	// endpoint'ы, созданные тестом
+	tests := []struct {
+		name    string
+		typ     domain.BookingEventType
+		want    []int
+		notWant []int
+	}{
+		{"space owner and admin", domain.BookingEventCreated, []int{ownerEndpoint, adminEndpoint}, []int{adminCancelled, otherEndpoint}},
+		{"admin subscribed to type", domain.BookingEventCancelled, []int{ownerEndpoint, adminEndpoint, adminCancelled}, []int{otherEndpoint}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoints, err := webhooks.ListEndpointsForEvent(domain.BookingEvent{Type: tt.typ, SpaceID: sp.ID})
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := map[int]bool{}
+			for _, e := range endpoints {
+				got[e.ID] = true
+			}
+			for _, id := range tt.want {
+				if !got[id] {
+					t.Errorf("endpoint %d did not receive %s", id, tt.typ)
+				}
+			}
+			for _, id := range tt.notWant {
+				if got[id] {
+					t.Errorf("endpoint %d unexpectedly received %s", id, tt.typ)
+				}
+			}
+		})
+	}
+}
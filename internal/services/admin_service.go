@@ -0,0 +1,171 @@
+package services
+
+import (
+	"errors"
+
+	"SpaceBookProject/internal/auth"
+	"SpaceBookProject/internal/domain"
+	"SpaceBookProject/internal/repository"
+)
+
+const (
+	defaultAdminPageSize = 50
+	maxAdminPageSize     = 200
+)
+
+var (
+	ErrCannotModerateAdmin = errors.New("administrators cannot be moderated")
+	ErrAlreadySuspended    = errors.New("user is already suspended")
+	ErrNotSuspended        = errors.New("user is not suspended")
+	ErrAlreadyUnpublished  = errors.New("space is already unpublished")
+	ErrNotUnpublished      = errors.New("space is not unpublished")
+)
+
+// AdminService — модерация платформы. Все действия пишутся в журнал
+// вместе с администратором и причиной.
+type AdminService struct {
+	moderation  *repository.ModerationRepository
+	users       *repository.UserRepository
+	spaces      *repository.SpaceRepository
+	revocations *auth.RevocationStore
+}
+
+func NewAdminService(
+	moderation *repository.ModerationRepository,
+	users *repository.UserRepository,
+	spaces *repository.SpaceRepository,
+	revocations *auth.RevocationStore,
+) *AdminService {
+	return &AdminService{
+		moderation:  moderation,
+		users:       users,
+		spaces:      spaces,
+		revocations: revocations,
+	}
+}
+
+func (s *AdminService) ListUsers(f repository.UserFilter) ([]domain.User, error) {
+	f.Limit, f.Offset = pageBounds(f.Limit, f.Offset)
+	return s.users.List(f)
+}
+
+func (s *AdminService) SuspendUser(adminID, userID int, reason string) (*domain.AuditEntry, error) {
+	user, err := s.moderatedUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.SuspendedAt != nil {
+		return nil, ErrAlreadySuspended
+	}
+
+	entry := auditEntry(adminID, domain.AuditUserSuspended, domain.AuditTargetUser, int64(userID), reason)
+	if err := s.moderation.SuspendUser(entry); err != nil {
+		return nil, err
+	}
+	s.revocations.ForgetUser(userID)
+	return entry, nil
+}
+
+func (s *AdminService) ReinstateUser(adminID, userID int, reason string) (*domain.AuditEntry, error) {
+	user, err := s.moderatedUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.SuspendedAt == nil {
+		return nil, ErrNotSuspended
+	}
+
+	entry := auditEntry(adminID, domain.AuditUserReinstated, domain.AuditTargetUser, int64(userID), reason)
+	if err := s.moderation.ReinstateUser(entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// ForceCancelBooking отменяет ещё не завершённую бронь, в том числе
+// уже начавшуюся, — то, чего не могут сделать ни арендатор, ни владелец.
+func (s *AdminService) ForceCancelBooking(adminID, bookingID int, reason string) (*domain.AuditEntry, error) {
+	entry := auditEntry(adminID, domain.AuditBookingCancelled, domain.AuditTargetBooking, int64(bookingID), reason)
+	_, err := s.moderation.ForceCancelBooking(entry)
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+func (s *AdminService) UnpublishSpace(adminID, spaceID int, reason string) (*domain.AuditEntry, error) {
+	sp, err := s.spaces.GetByID(spaceID)
+	if err != nil {
+		return nil, err
+	}
+	if sp.UnpublishedAt != nil {
+		return nil, ErrAlreadyUnpublished
+	}
+
+	entry := auditEntry(adminID, domain.AuditSpaceUnpublished, domain.AuditTargetSpace, int64(spaceID), reason)
+	if err := s.moderation.UnpublishSpace(entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+func (s *AdminService) RepublishSpace(adminID, spaceID int, reason string) (*domain.AuditEntry, error) {
+	sp, err := s.spaces.GetByID(spaceID)
+	if err != nil {
+		return nil, err
+	}
+	if sp.UnpublishedAt == nil {
+		return nil, ErrNotUnpublished
+	}
+
+	entry := auditEntry(adminID, domain.AuditSpaceRepublished, domain.AuditTargetSpace, int64(spaceID), reason)
+	if err := s.moderation.RepublishSpace(entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+func (s *AdminService) ListAudit(f repository.AuditFilter) ([]domain.AuditEntry, error) {
+	f.Limit, f.Offset = pageBounds(f.Limit, f.Offset)
+	return s.moderation.ListAudit(f)
+}
+
+func (s *AdminService) moderatedUser(userID int) (*domain.User, error) {
+	user, err := s.users.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.HasRole(domain.RoleAdmin) {
+		return nil, ErrCannotModerateAdmin
+	}
+	return user, nil
+}
+
+func auditEntry(
+	adminID int,
+	action domain.AuditAction,
+	targetType domain.AuditTargetType,
+	targetID int64,
+	reason string,
+) *domain.AuditEntry {
+	return &domain.AuditEntry{
+		AdminID:    adminID,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Reason:     reason,
+	}
+}
+
+func pageBounds(limit, offset int) (int, int) {
+	if limit <= 0 {
+		limit = defaultAdminPageSize
+	}
+	if limit > maxAdminPageSize {
+		limit = maxAdminPageSize
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return limit, offset
+}
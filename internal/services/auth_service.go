@@ -1,7 +1,9 @@
 package services
 
 import (
+	"context"
 	"errors"
+	"log"
 	"time"
 
 	"SpaceBookProject/internal/auth"
@@ -12,23 +14,56 @@ import (
 )
 
 var (
-	ErrInvalidCredentials = errors.New("invalid email or password")
-	ErrTokenExpired       = errors.New("token has expired")
+	ErrInvalidCredentials   = errors.New("invalid email or password")
+	ErrTokenExpired         = errors.New("token has expired")
+	ErrEmailAlreadyVerified = errors.New("email is already verified")
+	ErrUserSuspended        = errors.New("account is suspended")
+	ErrRoleRequired         = errors.New("at least one role is required")
 )
 
+// AccountNotifier доставляет пользователю одноразовые токены. Реализация
+// по умолчанию шлёт письма, в тестах её можно заменить заглушкой.
+type AccountNotifier interface {
+	SendPasswordReset(ctx context.Context, user *domain.User, token string, validFor time.Duration) error
+	SendEmailVerification(ctx context.Context, user *domain.User, token string, validFor time.Duration) error
+}
+
+type AccountTokenTTL struct {
+	PasswordReset     time.Duration
+	EmailVerification time.Duration
+}
+
 type AuthService struct {
-	userRepo   *repository.UserRepository
-	jwtManager *auth.JWTManager
+	userRepo    *repository.UserRepository
+	sessionRepo *repository.SessionRepository
+	tokenRepo   *repository.UserTokenRepository
+	jwtManager  *auth.JWTManager
+	revocations *auth.RevocationStore
+	notifier    AccountNotifier
+	tokenTTL    AccountTokenTTL
 }
 
-func NewAuthService(userRepo *repository.UserRepository, jwtManager *auth.JWTManager) *AuthService {
+func NewAuthService(
+	userRepo *repository.UserRepository,
+	sessionRepo *repository.SessionRepository,
+	tokenRepo *repository.UserTokenRepository,
+	jwtManager *auth.JWTManager,
+	revocations *auth.RevocationStore,
+	notifier AccountNotifier,
+	tokenTTL AccountTokenTTL,
+) *AuthService {
 	return &AuthService{
-		userRepo:   userRepo,
-		jwtManager: jwtManager,
+		userRepo:    userRepo,
+		sessionRepo: sessionRepo,
+		tokenRepo:   tokenRepo,
+		jwtManager:  jwtManager,
+		revocations: revocations,
+		notifier:    notifier,
+		tokenTTL:    tokenTTL,
 	}
 }
 
-func (s *AuthService) Register(req *domain.RegisterRequest) (*domain.AuthResponse, error) {
+func (s *AuthService) Register(req *domain.RegisterRequest, meta domain.SessionMeta) (*domain.AuthResponse, error) {
 	existingUser, _ := s.userRepo.GetByEmail(req.Email)
 	if existingUser != nil {
 		return nil, repository.ErrUserAlreadyExists
@@ -37,61 +72,97 @@ func (s *AuthService) Register(req *domain.RegisterRequest) (*domain.AuthRespons
 	if err != nil {
 		return nil, err
 	}
+	locale := req.Locale
+	if locale == "" {
+		locale = domain.DefaultLocale
+	}
+	roles := registrationRoles(req)
+	if len(roles) == 0 {
+		return nil, ErrRoleRequired
+	}
 	user := &domain.User{
 		Email:        req.Email,
 		PasswordHash: string(hashedPassword),
-		Role:         req.Role,
+		Roles:        roles,
 		FirstName:    req.FirstName,
 		LastName:     req.LastName,
 		Phone:        req.Phone,
+		Locale:       locale,
 	}
 	if err := s.userRepo.Create(user); err != nil {
 		return nil, err
 	}
-	accessToken, err := s.jwtManager.GenerateAccessToken(user.ID, user.Email, string(user.Role))
+	// регистрация не должна падать из-за почты: письмо можно запросить повторно
+	if err := s.sendEmailVerification(context.Background(), user); err != nil {
+		log.Printf("[auth] failed to send verification email to user %d: %v", user.ID, err)
+	}
+	return s.startSession(user, meta)
+}
+
+func (s *AuthService) Login(req *domain.LoginRequest, meta domain.SessionMeta) (*domain.AuthResponse, error) {
+	user, err := s.userRepo.GetByEmail(req.Email)
 	if err != nil {
+		if err == repository.ErrUserNotFound {
+			return nil, ErrInvalidCredentials
+		}
 		return nil, err
 	}
-	refreshToken, err := s.jwtManager.GenerateRefreshToken(user.ID)
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	if user.SuspendedAt != nil {
+		return nil, ErrUserSuspended
+	}
+	return s.startSession(user, meta)
+}
+
+// RefreshToken обменивает refresh-токен на новую пару токенов той же сессии.
+// Повторное предъявление уже обменянного токена отзывает всю сессию.
+func (s *AuthService) RefreshToken(refreshToken string, meta domain.SessionMeta) (*domain.AuthResponse, error) {
+	newRefreshToken, newHash, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	expiresAt := time.Now().Add(s.jwtManager.RefreshTokenTTL())
+
+	session, err := s.sessionRepo.Rotate(auth.HashToken(refreshToken), newHash, meta, expiresAt)
 	if err != nil {
 		return nil, err
 	}
-	expiresAt := time.Now().Add(7 * 24 * time.Hour)
-	if err := s.userRepo.SaveRefreshToken(user.ID, refreshToken, expiresAt); err != nil {
+	user, err := s.userRepo.GetByID(session.UserID)
+	if err != nil {
+		return nil, err
+	}
+	accessToken, err := s.accessToken(user, session.ID)
+	if err != nil {
 		return nil, err
 	}
 	user.PasswordHash = ""
-
 	return &domain.AuthResponse{
 		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
+		RefreshToken: newRefreshToken,
 		User:         *user,
 	}, nil
 }
-func (s *AuthService) Login(req *domain.LoginRequest) (*domain.AuthResponse, error) {
-	user, err := s.userRepo.GetByEmail(req.Email)
+
+func (s *AuthService) startSession(user *domain.User, meta domain.SessionMeta) (*domain.AuthResponse, error) {
+	refreshToken, hash, err := auth.GenerateOpaqueToken()
 	if err != nil {
-		if err == repository.ErrUserNotFound {
-			return nil, ErrInvalidCredentials
-		}
 		return nil, err
 	}
-	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
-		return nil, ErrInvalidCredentials
+	session := &domain.Session{
+		UserID:    user.ID,
+		UserAgent: meta.UserAgent,
+		IP:        meta.IP,
+		ExpiresAt: time.Now().Add(s.jwtManager.RefreshTokenTTL()),
 	}
-	accessToken, err := s.jwtManager.GenerateAccessToken(user.ID, user.Email, string(user.Role))
-	if err != nil {
+	if err := s.sessionRepo.Create(session, hash); err != nil {
 		return nil, err
 	}
-
-	refreshToken, err := s.jwtManager.GenerateRefreshToken(user.ID)
+	accessToken, err := s.accessToken(user, session.ID)
 	if err != nil {
 		return nil, err
 	}
-	expiresAt := time.Now().Add(7 * 24 * time.Hour)
-	if err := s.userRepo.SaveRefreshToken(user.ID, refreshToken, expiresAt); err != nil {
-		return nil, err
-	}
 	user.PasswordHash = ""
 	return &domain.AuthResponse{
 		AccessToken:  accessToken,
@@ -99,37 +170,195 @@ func (s *AuthService) Login(req *domain.LoginRequest) (*domain.AuthResponse, err
 		User:         *user,
 	}, nil
 }
-func (s *AuthService) RefreshToken(refreshToken string) (*domain.AuthResponse, error) {
-	userID, err := s.userRepo.GetRefreshToken(refreshToken)
-	if err != nil {
+
+// BecomeOwner добавляет пользователю роль владельца и выдаёт access-токен
+// текущей сессии с обновлённым набором ролей.
+func (s *AuthService) BecomeOwner(claims *auth.TokenClaims) (*domain.AccessTokenResponse, error) {
+	if _, err := s.userRepo.AddRole(claims.UserID, domain.RoleOwner); err != nil {
 		return nil, err
 	}
-	user, err := s.userRepo.GetByID(userID)
+	user, err := s.userRepo.GetByID(claims.UserID)
 	if err != nil {
 		return nil, err
 	}
-	accessToken, err := s.jwtManager.GenerateAccessToken(user.ID, user.Email, string(user.Role))
+	accessToken, err := s.accessToken(user, claims.SessionID)
 	if err != nil {
 		return nil, err
 	}
-	newRefreshToken, err := s.jwtManager.GenerateRefreshToken(user.ID)
+	return &domain.AccessTokenResponse{
+		AccessToken: accessToken,
+		User:        *user,
+	}, nil
+}
+
+func (s *AuthService) accessToken(user *domain.User, sessionID int64) (string, error) {
+	roles := make([]string, len(user.Roles))
+	for i, r := range user.Roles {
+		roles[i] = string(r)
+	}
+	return s.jwtManager.GenerateAccessToken(user.ID, user.Email, roles, sessionID, user.TokenVersion)
+}
+
+// registrationRoles объединяет role и roles из запроса без повторов.
+func registrationRoles(req *domain.RegisterRequest) []domain.UserRole {
+	var roles []domain.UserRole
+	for _, r := range append([]domain.UserRole{req.Role}, req.Roles...) {
+		if r != "" && !domain.ContainsRole(roles, r) {
+			roles = append(roles, r)
+		}
+	}
+	return roles
+}
+
+// Logout завершает текущую сессию: отзывает её refresh-токены и сам
+// access-токен, которым выполнен запрос.
+func (s *AuthService) Logout(claims *auth.TokenClaims) error {
+	err := s.sessionRepo.Revoke(claims.SessionID, claims.UserID, repository.RevokeReasonLogout)
+	if err != nil && !errors.Is(err, repository.ErrSessionNotFound) {
+		return err
+	}
+	s.revocations.ForgetSession(claims.SessionID)
+	return s.revocations.RevokeToken(claims)
+}
+
+// LogoutAll завершает все сессии пользователя на всех устройствах.
+func (s *AuthService) LogoutAll(userID int) error {
+	return s.RevokeAllTokens(userID, repository.RevokeReasonLogout)
+}
+
+// RevokeAllTokens отзывает все сессии и access-токены пользователя:
+// увеличенная token_version делает недействительными уже выданные токены.
+func (s *AuthService) RevokeAllTokens(userID int, reason string) error {
+	if err := s.userRepo.BumpTokenVersion(userID); err != nil {
+		return err
+	}
+	if err := s.sessionRepo.RevokeAllForUser(userID, reason); err != nil {
+		return err
+	}
+	s.revocations.ForgetUser(userID)
+	return nil
+}
+
+func (s *AuthService) ChangePassword(userID int, req *domain.ChangePasswordRequest) error {
+	user, err := s.userRepo.GetByID(userID)
 	if err != nil {
-		return nil, err
+		return err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.CurrentPassword)); err != nil {
+		return ErrInvalidCredentials
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	if err := s.userRepo.UpdatePassword(userID, string(hashedPassword)); err != nil {
+		return err
 	}
-	expiresAt := time.Now().Add(7 * 24 * time.Hour)
-	if err := s.userRepo.SaveRefreshToken(user.ID, newRefreshToken, expiresAt); err != nil {
+	if err := s.sessionRepo.RevokeAllForUser(userID, repository.RevokeReasonPasswordChange); err != nil {
+		return err
+	}
+	s.revocations.ForgetUser(userID)
+	return nil
+}
+
+func (s *AuthService) ListSessions(userID int, currentSessionID int64) ([]domain.Session, error) {
+	sessions, err := s.sessionRepo.ListActiveByUser(userID)
+	if err != nil {
 		return nil, err
 	}
-	user.PasswordHash = ""
-	return &domain.AuthResponse{
-		AccessToken:  accessToken,
-		RefreshToken: newRefreshToken,
-		User:         *user,
-	}, nil
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == currentSessionID
+	}
+	return sessions, nil
 }
 
-func (s *AuthService) Logout(userID int) error {
-	return s.userRepo.DeleteRefreshToken(userID)
+func (s *AuthService) RevokeSession(userID int, sessionID int64) error {
+	if err := s.sessionRepo.Revoke(sessionID, userID, repository.RevokeReasonUser); err != nil {
+		return err
+	}
+	s.revocations.ForgetSession(sessionID)
+	return nil
+}
+
+// ForgotPassword отправляет ссылку для сброса пароля. Для неизвестного
+// email ничего не происходит и ошибка не возвращается, чтобы по ответу
+// нельзя было проверить, зарегистрирован ли адрес.
+func (s *AuthService) ForgotPassword(ctx context.Context, req *domain.ForgotPasswordRequest) error {
+	user, err := s.userRepo.GetByEmail(req.Email)
+	if err != nil {
+		if err == repository.ErrUserNotFound {
+			return nil
+		}
+		return err
+	}
+
+	token, hash, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+	if err := s.tokenRepo.Create(user.ID, domain.UserTokenPasswordReset, hash, time.Now().Add(s.tokenTTL.PasswordReset)); err != nil {
+		return err
+	}
+	// ошибку отправки не отдаём клиенту по той же причине
+	if err := s.notifier.SendPasswordReset(ctx, user, token, s.tokenTTL.PasswordReset); err != nil {
+		log.Printf("[auth] failed to send password reset email to user %d: %v", user.ID, err)
+	}
+	return nil
+}
+
+// ResetPassword задаёт новый пароль по токену из письма и завершает все
+// сессии пользователя.
+func (s *AuthService) ResetPassword(req *domain.ResetPasswordRequest) error {
+	userID, err := s.tokenRepo.Consume(domain.UserTokenPasswordReset, auth.HashToken(req.Token))
+	if err != nil {
+		return err
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	if err := s.userRepo.UpdatePassword(userID, string(hashedPassword)); err != nil {
+		return err
+	}
+	// письмо пришло на почту владельца — адрес заодно считаем подтверждённым
+	if err := s.userRepo.MarkEmailVerified(userID); err != nil {
+		return err
+	}
+	if err := s.sessionRepo.RevokeAllForUser(userID, repository.RevokeReasonPasswordChange); err != nil {
+		return err
+	}
+	s.revocations.ForgetUser(userID)
+	return nil
+}
+
+func (s *AuthService) VerifyEmail(req *domain.VerifyEmailRequest) error {
+	userID, err := s.tokenRepo.Consume(domain.UserTokenEmailVerification, auth.HashToken(req.Token))
+	if err != nil {
+		return err
+	}
+	return s.userRepo.MarkEmailVerified(userID)
+}
+
+func (s *AuthService) ResendEmailVerification(ctx context.Context, userID int) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerifiedAt != nil {
+		return ErrEmailAlreadyVerified
+	}
+	return s.sendEmailVerification(ctx, user)
+}
+
+func (s *AuthService) sendEmailVerification(ctx context.Context, user *domain.User) error {
+	token, hash, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+	if err := s.tokenRepo.Create(user.ID, domain.UserTokenEmailVerification, hash, time.Now().Add(s.tokenTTL.EmailVerification)); err != nil {
+		return err
+	}
+	return s.notifier.SendEmailVerification(ctx, user, token, s.tokenTTL.EmailVerification)
 }
 
 func (s *AuthService) GetUserByID(userID int) (*domain.User, error) {
@@ -142,5 +371,12 @@ func (s *AuthService) GetUserByID(userID int) (*domain.User, error) {
 }
 
 func (s *AuthService) ValidateToken(token string) (*auth.TokenClaims, error) {
-	return s.jwtManager.ValidateToken(token)
+	claims, err := s.jwtManager.ValidateToken(token)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.revocations.Check(claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
 }
@@ -0,0 +1,205 @@
+package services
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"SpaceBookProject/internal/domain"
+	"SpaceBookProject/internal/repository"
+)
+
+const (
+	defaultAvailabilityWindow = 30 * 24 * time.Hour
+	maxAvailabilityWindow     = 366 * 24 * time.Hour
+)
+
+var (
+	ErrInvalidRange = errors.New("from must be before to")
+	ErrRangeTooLong = errors.New("requested range is too long")
+	ErrBlackoutPast = errors.New("blackout must end in the future")
+)
+
+type AvailabilityService struct {
+	bookings  *repository.BookingRepository
+	spaces    *repository.SpaceRepository
+	blackouts *repository.BlackoutRepository
+	policy    *SpacePolicy
+}
+
+func NewAvailabilityService(
+	bookings *repository.BookingRepository,
+	spaces *repository.SpaceRepository,
+	blackouts *repository.BlackoutRepository,
+	policy *SpacePolicy,
+) *AvailabilityService {
+	return &AvailabilityService{
+		bookings:  bookings,
+		spaces:    spaces,
+		blackouts: blackouts,
+		policy:    policy,
+	}
+}
+
+// GetAvailability строит календарь помещения на интервале [from, to):
+// занятые периоды — одобренные брони (и, по запросу, ожидающие) плюс
+// закрытия владельца, свободные — всё остальное.
+func (s *AvailabilityService) GetAvailability(spaceID int, fromStr, toStr string, includePending bool) (*domain.Availability, error) {
+	sp, err := s.spaces.GetByID(spaceID)
+	if err != nil {
+		return nil, err
+	}
+	if !sp.Bookable() {
+		return nil, repository.ErrSpaceNotFound
+	}
+	loc, err := sp.Location()
+	if err != nil {
+		return nil, err
+	}
+
+	from, to, err := parseRange(fromStr, toStr, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := append([]domain.BookingStatus(nil), domain.OccupyingStatuses...)
+	if includePending {
+		statuses = append(statuses, domain.BookingStatusPending)
+	}
+	bookings, err := s.bookings.ListInRange(spaceID, from, to, statuses)
+	if err != nil {
+		return nil, err
+	}
+	blackouts, err := s.blackouts.ListInRange(spaceID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	busy := make([]domain.Interval, 0, len(bookings)+len(blackouts))
+	for _, b := range bookings {
+		source := domain.IntervalSourceBooking
+		if b.Status == domain.BookingStatusPending {
+			source = domain.IntervalSourcePending
+		}
+		busy = append(busy, clipInterval(b.DateFrom, b.DateTo, from, to, source))
+	}
+	for _, b := range blackouts {
+		busy = append(busy, clipInterval(b.StartsAt, b.EndsAt, from, to, domain.IntervalSourceBlackout))
+	}
+	sort.Slice(busy, func(i, j int) bool {
+		return busy[i].From.Before(busy[j].From)
+	})
+
+	return &domain.Availability{
+		SpaceID:  spaceID,
+		Timezone: loc.String(),
+		From:     from,
+		To:       to,
+		Free:     freeIntervals(busy, from, to),
+		Busy:     busy,
+	}, nil
+}
+
+func (s *AvailabilityService) ListBlackouts(userID, spaceID int) ([]domain.Blackout, error) {
+	if _, err := s.policy.managedSpace(s.spaces, userID, spaceID, domain.SpaceActionView); err != nil {
+		return nil, err
+	}
+	return s.blackouts.ListBySpace(spaceID)
+}
+
+func (s *AvailabilityService) CreateBlackout(userID, spaceID int, req *domain.CreateBlackoutRequest) (*domain.Blackout, error) {
+	sp, err := s.policy.managedSpace(s.spaces, userID, spaceID, domain.SpaceActionSchedule)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := sp.Location()
+	if err != nil {
+		return nil, err
+	}
+
+	from, err := parseBookingTime(req.StartsAt, loc)
+	if err != nil {
+		return nil, err
+	}
+	to, err := parseBookingTime(req.EndsAt, loc)
+	if err != nil {
+		return nil, err
+	}
+	if !from.Before(to) {
+		return nil, ErrInvalidRange
+	}
+	if !to.After(time.Now()) {
+		return nil, ErrBlackoutPast
+	}
+
+	b := &domain.Blackout{
+		SpaceID:  spaceID,
+		StartsAt: from,
+		EndsAt:   to,
+		Reason:   req.Reason,
+	}
+	if err := s.blackouts.Create(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (s *AvailabilityService) DeleteBlackout(userID, spaceID, blackoutID int) error {
+	if _, err := s.policy.managedSpace(s.spaces, userID, spaceID, domain.SpaceActionSchedule); err != nil {
+		return err
+	}
+	return s.blackouts.Delete(blackoutID, spaceID)
+}
+
+func parseRange(fromStr, toStr string, loc *time.Location) (time.Time, time.Time, error) {
+	var from, to time.Time
+	var err error
+
+	if fromStr == "" {
+		from = time.Now().In(loc).Truncate(time.Minute)
+	} else if from, err = parseBookingTime(fromStr, loc); err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	if toStr == "" {
+		to = from.Add(defaultAvailabilityWindow)
+	} else if to, err = parseBookingTime(toStr, loc); err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	if !from.Before(to) {
+		return time.Time{}, time.Time{}, ErrInvalidRange
+	}
+	if to.Sub(from) > maxAvailabilityWindow {
+		return time.Time{}, time.Time{}, ErrRangeTooLong
+	}
+	return from, to, nil
+}
+
+func clipInterval(start, end, from, to time.Time, source domain.IntervalSource) domain.Interval {
+	if start.Before(from) {
+		start = from
+	}
+	if end.After(to) {
+		end = to
+	}
+	return domain.Interval{From: start, To: end, Source: source}
+}
+
+// freeIntervals вычисляет дополнение отсортированных занятых периодов до [from, to).
+func freeIntervals(busy []domain.Interval, from, to time.Time) []domain.Interval {
+	free := []domain.Interval{}
+	cursor := from
+	for _, b := range busy {
+		if b.From.After(cursor) {
+			free = append(free, domain.Interval{From: cursor, To: b.From})
+		}
+		if b.To.After(cursor) {
+			cursor = b.To
+		}
+	}
+	if cursor.Before(to) {
+		free = append(free, domain.Interval{From: cursor, To: to})
+	}
+	return free
+}
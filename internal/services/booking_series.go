@@ -0,0 +1,253 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"SpaceBookProject/internal/domain"
+)
+
+var (
+	ErrSeriesConflicts   = errors.New("some occurrences of the series cannot be booked")
+	ErrSeriesSelfOverlap = errors.New("occurrences of the series overlap each other")
+	ErrEmptySeries       = errors.New("recurrence rule produces no bookable occurrences")
+	ErrNotInSeries       = errors.New("booking is not part of a series")
+	ErrNothingToChange   = errors.New("no occurrences of the series can be changed")
+)
+
+// SeriesConflictError перечисляет вхождения, из-за которых серия не создана.
+type SeriesConflictError struct {
+	Conflicts []domain.SeriesConflict
+}
+
+func (e *SeriesConflictError) Error() string {
+	return fmt.Sprintf("%d occurrences of the series cannot be booked", len(e.Conflicts))
+}
+
+func (e *SeriesConflictError) Unwrap() error {
+	return ErrSeriesConflicts
+}
+
+// CreateSeries создаёт серию броней по правилу повторения. Все вхождения
+// проверяются заранее; если какие-то заняты, серия не создаётся и ошибка
+// перечисляет их, а с SkipConflicts создаётся без них, и пропущенные
+// возвращаются вторым значением.
+func (s *BookingService) CreateSeries(tenantID int, req *domain.CreateSeriesRequest) (*domain.BookingSeries, []domain.SeriesConflict, error) {
+	tenant, err := s.users.GetByID(tenantID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if s.requireVerifiedEmail && tenant.EmailVerifiedAt == nil {
+		return nil, nil, ErrEmailNotVerified
+	}
+
+	sp, err := s.spaces.GetByID(req.SpaceID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !sp.Bookable() {
+		return nil, nil, ErrSpaceInactive
+	}
+	if sp.OwnerID == tenantID {
+		return nil, nil, ErrOwnSpace
+	}
+
+	rule, err := domain.ParseRRule(req.RRule)
+	if err != nil {
+		return nil, nil, err
+	}
+	from, to, err := parseBookingRange(sp, req.DateFrom, req.DateTo)
+	if err != nil {
+		return nil, nil, err
+	}
+	loc, err := sp.Location()
+	if err != nil {
+		return nil, nil, err
+	}
+	except := make(map[string]bool, len(req.Exceptions))
+	for _, d := range req.Exceptions {
+		except[d] = true
+	}
+	starts, err := rule.Occurrences(from, loc, except)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	status := domain.BookingStatusPending
+	if req.OrganizationID != nil {
+		if status, err = s.companyBookingStatus(*req.OrganizationID, tenantID); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	priceOf, err := s.pricer(sp)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	duration := to.Sub(from)
+	now := time.Now()
+	var (
+		bookings  []domain.Booking
+		conflicts []domain.SeriesConflict
+	)
+	for i, start := range starts {
+		end := start.Add(duration)
+		if i > 0 && start.Before(starts[i-1].Add(duration)) {
+			return nil, nil, ErrSeriesSelfOverlap
+		}
+
+		reason := ""
+		if start.Before(now) {
+			reason = "occurrence is in the past"
+		} else if err := s.checkFree(sp.ID, start, end, nil); err != nil {
+			if !errors.Is(err, ErrSpaceUnavailable) && !errors.Is(err, ErrAlreadyBooked) {
+				return nil, nil, err
+			}
+			reason = err.Error()
+		}
+		if reason != "" {
+			conflicts = append(conflicts, domain.SeriesConflict{DateFrom: start, DateTo: end, Reason: reason})
+			continue
+		}
+
+		bookings = append(bookings, domain.Booking{
+			SpaceID:        sp.ID,
+			TenantID:       tenantID,
+			OrganizationID: req.OrganizationID,
+			Status:         status,
+			DateFrom:       start,
+			DateTo:         end,
+			Price:          priceOf(start, end),
+		})
+	}
+
+	if len(conflicts) > 0 && !req.SkipConflicts {
+		return nil, nil, &SeriesConflictError{Conflicts: conflicts}
+	}
+	if len(bookings) == 0 {
+		return nil, conflicts, ErrEmptySeries
+	}
+
+	series := &domain.BookingSeries{
+		SpaceID:        sp.ID,
+		TenantID:       tenantID,
+		OrganizationID: req.OrganizationID,
+		RRule:          req.RRule,
+		Exceptions:     req.Exceptions,
+	}
+	if series.Exceptions == nil {
+		series.Exceptions = []string{}
+	}
+	if err := s.series.Create(series, bookings); err != nil {
+		return nil, nil, err
+	}
+	return series, conflicts, nil
+}
+
+// GetSeries возвращает серию со всеми вхождениями тем, кто видит её брони.
+func (s *BookingService) GetSeries(userID, seriesID int) (*domain.BookingSeries, error) {
+	series, err := s.series.GetByID(seriesID)
+	if err != nil {
+		return nil, err
+	}
+	bookings, err := s.series.ListBookings(seriesID)
+	if err != nil {
+		return nil, err
+	}
+
+	companyOnly := true
+	for _, b := range bookings {
+		if b.Status != domain.BookingStatusPendingCompany {
+			companyOnly = false
+			break
+		}
+	}
+	if err := s.authorizeParties(userID, series.TenantID, series.SpaceID, series.OrganizationID, companyOnly); err != nil {
+		return nil, err
+	}
+
+	series.Bookings = bookings
+	return series, nil
+}
+
+// ApproveSeries одобряет все ожидающие вхождения серии разом: если хоть
+// одно пересекается с одобренной бронью, не одобряется ни одно.
+// Отдельные вхождения решаются обычными ApproveBooking и RejectBooking.
+func (s *BookingService) ApproveSeries(userID, seriesID int, reason string) ([]domain.Booking, error) {
+	return s.decideSeries(userID, seriesID, domain.BookingStatusApproved, reason, func(b *domain.Booking) error {
+		if b.RespondBy != nil && time.Now().After(*b.RespondBy) {
+			return ErrResponseOverdue
+		}
+		return nil
+	})
+}
+
+func (s *BookingService) RejectSeries(userID, seriesID int, reason string) ([]domain.Booking, error) {
+	return s.decideSeries(userID, seriesID, domain.BookingStatusRejected, reason, nil)
+}
+
+func (s *BookingService) decideSeries(userID, seriesID int, to domain.BookingStatus, reason string, guard func(b *domain.Booking) error) ([]domain.Booking, error) {
+	series, err := s.series.GetByID(seriesID)
+	if err != nil {
+		return nil, err
+	}
+	sp, err := s.spaces.GetByID(series.SpaceID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.policy.Authorize(userID, sp, domain.SpaceActionDecide); err != nil {
+		return nil, err
+	}
+
+	t := domain.BookingTransition{To: to, ActorID: &userID, Reason: reason}
+	changed, err := s.series.Transition(seriesID, []domain.BookingStatus{domain.BookingStatusPending}, nil, t, guard)
+	if err != nil {
+		return nil, err
+	}
+	if len(changed) == 0 {
+		return nil, ErrNothingToChange
+	}
+	return changed, nil
+}
+
+// CancelFollowing отменяет вхождение серии и все последующие — "это и
+// следующие" в терминах календарей. Прошедшие и уже решённые вхождения
+// не меняются.
+func (s *BookingService) CancelFollowing(id, tenantID int, reason string) ([]domain.Booking, error) {
+	b, err := s.bookings.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if b.TenantID != tenantID {
+		return nil, ErrForbidden
+	}
+	if b.SeriesID == nil {
+		return nil, ErrNotInSeries
+	}
+	if time.Now().After(b.DateFrom) {
+		return nil, ErrAlreadyStarted
+	}
+
+	cancellable := []domain.BookingStatus{
+		domain.BookingStatusPendingCompany,
+		domain.BookingStatusPending,
+		domain.BookingStatusApproved,
+		domain.BookingStatusConfirmed,
+	}
+	t := domain.BookingTransition{
+		To:          domain.BookingStatusCancelled,
+		ActorID:     &tenantID,
+		Reason:      reason,
+		CancelledBy: domain.CancelledByTenant,
+	}
+	changed, err := s.series.Transition(*b.SeriesID, cancellable, &b.DateFrom, t, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(changed) == 0 {
+		return nil, ErrNothingToChange
+	}
+	return changed, nil
+}
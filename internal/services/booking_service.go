@@ -11,69 +11,230 @@ import (
 var (
 	ErrForbidden          = errors.New("forbidden")
 	ErrAlreadyStarted     = errors.New("booking already started")
-	ErrWrongStatus        = errors.New("invalid booking status")
-	ErrOverlappingBooking = errors.New("overlapping approved booking")
+	ErrWrongStatus        = domain.ErrInvalidTransition
+	ErrNotStarted         = errors.New("booking has not started yet")
+	ErrAlreadyEnded       = errors.New("booking already ended")
+	ErrResponseOverdue    = errors.New("the deadline to respond to this booking has passed")
+	ErrAlreadyBooked      = errors.New("space is already booked for these dates")
+	ErrNotModifiable      = errors.New("only pending or approved bookings can be modified")
+	ErrOverlappingBooking = repository.ErrOverlappingBooking
+	ErrInvalidBookingTime = errors.New("date_from and date_to must be RFC3339 timestamps or dates in YYYY-MM-DD format")
+	ErrSlotMisaligned     = errors.New("booking must start and end on the space's slot boundaries")
+	ErrSpaceUnavailable   = errors.New("space is closed by the owner for these dates")
+	ErrEmailNotVerified   = errors.New("confirm your email before booking")
+	ErrOwnSpace           = errors.New("you cannot book your own space")
+	ErrNotCompanyMember   = errors.New("you can book only on behalf of your own company")
 )
 
 type BookingService struct {
-	bookings *repository.BookingRepository
-	spaces   *repository.SpaceRepository
-	events   chan<- domain.BookingEvent
+	bookings  *repository.BookingRepository
+	changes   *repository.BookingChangeRepository
+	series    *repository.BookingSeriesRepository
+	spaces    *repository.SpaceRepository
+	blackouts *repository.BlackoutRepository
+	pricing   *repository.PricingRepository
+	users     *repository.UserRepository
+	orgs      *repository.OrganizationRepository
+	policy    *SpacePolicy
+
+	// requireVerifiedEmail запрещает создавать брони до подтверждения email.
+	requireVerifiedEmail bool
 }
 
-func NewBookingService(bookings *repository.BookingRepository, spaces *repository.SpaceRepository, events chan<- domain.BookingEvent) *BookingService {
+func NewBookingService(
+	bookings *repository.BookingRepository,
+	changes *repository.BookingChangeRepository,
+	series *repository.BookingSeriesRepository,
+	spaces *repository.SpaceRepository,
+	blackouts *repository.BlackoutRepository,
+	pricing *repository.PricingRepository,
+	users *repository.UserRepository,
+	orgs *repository.OrganizationRepository,
+	policy *SpacePolicy,
+	requireVerifiedEmail bool,
+) *BookingService {
 	return &BookingService{
-		bookings: bookings,
-		spaces:   spaces,
-		events:   events,
+		bookings:             bookings,
+		changes:              changes,
+		series:               series,
+		spaces:               spaces,
+		blackouts:            blackouts,
+		pricing:              pricing,
+		users:                users,
+		orgs:                 orgs,
+		policy:               policy,
+		requireVerifiedEmail: requireVerifiedEmail,
 	}
 }
 
-const dateLayout = "2006-01-02"
+const (
+	dateLayout       = "2006-01-02"
+	localTimeLayout  = "2006-01-02T15:04:05"
+	localShortLayout = "2006-01-02T15:04"
+)
+
+// parseBookingTime разбирает границу брони. Голая дата означает полночь
+// в часовом поясе помещения (посуточная бронь), время без смещения тоже
+// трактуется в этом поясе, RFC3339 принимается как есть.
+func parseBookingTime(value string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{dateLayout, localTimeLayout, localShortLayout} {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, ErrInvalidBookingTime
+}
 
+func alignedToSlot(t time.Time, loc *time.Location, slotMinutes int) bool {
+	local := t.In(loc)
+	if local.Second() != 0 || local.Nanosecond() != 0 {
+		return false
+	}
+	return (local.Hour()*60+local.Minute())%slotMinutes == 0
+}
+
+// CreateBooking создаёт заявку. Если в помещении включено мгновенное
+// бронирование и бронь подходит под его правила, заявка одобряется сразу,
+// иначе ждёт решения владельца.
 func (s *BookingService) CreateBooking(tenantID int, req *domain.CreateBookingRequest) (*domain.Booking, error) {
-	from, err := time.Parse(dateLayout, req.DateFrom)
+	tenant, err := s.users.GetByID(tenantID)
 	if err != nil {
 		return nil, err
 	}
-	to, err := time.Parse(dateLayout, req.DateTo)
+	if s.requireVerifiedEmail && tenant.EmailVerifiedAt == nil {
+		return nil, ErrEmailNotVerified
+	}
+
+	sp, err := s.spaces.GetByID(req.SpaceID)
 	if err != nil {
 		return nil, err
 	}
-	if !from.Before(to) {
-		return nil, errors.New("date_from must be before date_to")
+	if !sp.Bookable() {
+		return nil, ErrSpaceInactive
 	}
-
-	hasOverlap, err := s.bookings.HasApprovedOverlap(req.SpaceID, from, to, nil)
+	// пользователь с ролями owner и tenant не бронирует сам у себя
+	if sp.OwnerID == tenantID {
+		return nil, ErrOwnSpace
+	}
+	from, to, err := s.freeRange(sp, req.DateFrom, req.DateTo, nil)
 	if err != nil {
 		return nil, err
 	}
-	if hasOverlap {
-		return nil, errors.New("space is already booked for these dates")
+
+	status := domain.BookingStatusPending
+	if req.OrganizationID != nil {
+		if status, err = s.companyBookingStatus(*req.OrganizationID, tenantID); err != nil {
+			return nil, err
+		}
+	}
+
+	priceOf, err := s.pricer(sp)
+	if err != nil {
+		return nil, err
 	}
 
 	b := &domain.Booking{
-		SpaceID:  req.SpaceID,
-		TenantID: tenantID,
-		Status:   domain.BookingStatusPending,
-		DateFrom: from,
-		DateTo:   to,
+		SpaceID:        req.SpaceID,
+		TenantID:       tenantID,
+		OrganizationID: req.OrganizationID,
+		Status:         status,
+		DateFrom:       from,
+		DateTo:         to,
+		Price:          priceOf(from, to),
 	}
 
-	if err := s.bookings.Create(b); err != nil {
+	create := s.bookings.Create
+	if status == domain.BookingStatusPending &&
+		sp.InstantBooking.Allows(from, to, time.Now(), tenant.EmailVerifiedAt != nil) {
+		create = s.bookings.CreateInstant
+	}
+	if err := create(b); err != nil {
 		return nil, err
 	}
-	if s.events != nil {
-		s.events <- domain.BookingEvent{
-			Type:      domain.BookingEventCreated,
-			BookingID: b.ID,
-			SpaceID:   b.SpaceID,
-			TenantID:  b.TenantID,
-			At:        time.Now(),
-		}
+	return b, nil
+}
+
+// pricer загружает тариф помещения и возвращает функцию расчёта стоимости
+// брони по нему; без тарифа стоимость не рассчитывается.
+func (s *BookingService) pricer(sp *domain.Space) (func(from, to time.Time) *domain.Money, error) {
+	p, err := s.pricing.Get(sp.ID)
+	if errors.Is(err, repository.ErrPricingNotFound) {
+		return func(time.Time, time.Time) *domain.Money { return nil }, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	loc, err := sp.Location()
+	if err != nil {
+		return nil, err
 	}
+	return func(from, to time.Time) *domain.Money {
+		m := p.Quote(from, to, loc).TotalMoney()
+		return &m
+	}, nil
+}
 
-	return b, nil
+// freeRange разбирает и проверяет новые границы брони: порядок, выравнивание
+// по слотам, закрытые владельцем даты и пересечение с одобренными бронями,
+// кроме excludeID.
+func (s *BookingService) freeRange(sp *domain.Space, fromStr, toStr string, excludeID *int) (time.Time, time.Time, error) {
+	from, to, err := parseBookingRange(sp, fromStr, toStr)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if err := s.checkFree(sp.ID, from, to, excludeID); err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return from, to, nil
+}
+
+// parseBookingRange разбирает границы брони в поясе помещения и проверяет их
+// порядок и выравнивание по слотам.
+func parseBookingRange(sp *domain.Space, fromStr, toStr string) (time.Time, time.Time, error) {
+	loc, err := sp.Location()
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	from, err := parseBookingTime(fromStr, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	to, err := parseBookingTime(toStr, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if !from.Before(to) {
+		return time.Time{}, time.Time{}, errors.New("date_from must be before date_to")
+	}
+	if sp.SlotMinutes > 0 && (!alignedToSlot(from, loc, sp.SlotMinutes) || !alignedToSlot(to, loc, sp.SlotMinutes)) {
+		return time.Time{}, time.Time{}, ErrSlotMisaligned
+	}
+	return from, to, nil
+}
+
+// checkFree сообщает ErrSpaceUnavailable или ErrAlreadyBooked, если
+// интервал закрыт владельцем или пересекается с одобренной бронью.
+func (s *BookingService) checkFree(spaceID int, from, to time.Time, excludeID *int) error {
+	closed, err := s.blackouts.HasOverlap(spaceID, from, to)
+	if err != nil {
+		return err
+	}
+	if closed {
+		return ErrSpaceUnavailable
+	}
+
+	hasOverlap, err := s.bookings.HasApprovedOverlap(spaceID, from, to, excludeID)
+	if err != nil {
+		return err
+	}
+	if hasOverlap {
+		return ErrAlreadyBooked
+	}
+	return nil
 }
 
 func (s *BookingService) ListMyBookings(tenantID int) ([]domain.Booking, error) {
@@ -84,112 +245,404 @@ func (s *BookingService) ListOwnerBookings(ownerID int) ([]domain.Booking, error
 	return s.bookings.ListByOwner(ownerID)
 }
 
-func (s *BookingService) CancelBooking(id, tenantID int) error {
-	b, err := s.bookings.GetByID(id)
+// CancelBooking отменяет бронь арендатора и возвращает расчёт возврата
+// по политике помещения. Заявка, ещё не дошедшая до владельца, отменяется
+// без события — так решает таблица переходов.
+func (s *BookingService) CancelBooking(id, tenantID int, reason string) (*domain.Cancellation, error) {
+	t := domain.BookingTransition{
+		To:          domain.BookingStatusCancelled,
+		ActorID:     &tenantID,
+		Reason:      reason,
+		CancelledBy: domain.CancelledByTenant,
+	}
+	_, err := s.bookings.Transition(id, t, func(b *domain.Booking) error {
+		if b.TenantID != tenantID {
+			return ErrForbidden
+		}
+		if time.Now().After(b.DateFrom) {
+			return ErrAlreadyStarted
+		}
+		return nil
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
+	return s.bookings.GetCancellation(id)
+}
 
+// CancellationPreview показывает арендатору, сколько он получит обратно,
+// если отменит бронь сейчас. Ничего не меняет.
+func (s *BookingService) CancellationPreview(tenantID, id int) (*domain.Cancellation, error) {
+	b, err := s.bookings.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
 	if b.TenantID != tenantID {
-		return ErrForbidden
+		return nil, ErrForbidden
 	}
-	if time.Now().After(b.DateFrom) {
-		return ErrAlreadyStarted
+	now := time.Now()
+	if now.After(b.DateFrom) {
+		return nil, ErrAlreadyStarted
 	}
-	if b.Status != domain.BookingStatusPending && b.Status != domain.BookingStatusApproved {
-		return ErrWrongStatus
+	if _, err := domain.TransitionEvent(b.Status, domain.BookingStatusCancelled); err != nil {
+		return nil, err
 	}
 
-	if err := s.bookings.UpdateStatus(id, domain.BookingStatusCancelled); err != nil {
-		return err
+	sp, err := s.spaces.GetByID(b.SpaceID)
+	if err != nil {
+		return nil, err
 	}
-	if s.events != nil {
-		s.events <- domain.BookingEvent{
-			Type:      domain.BookingEventCancelled,
-			BookingID: b.ID,
-			SpaceID:   b.SpaceID,
-			TenantID:  b.TenantID,
-			At:        time.Now(),
-		}
+	return sp.CancellationPolicy.Cancel(b, domain.CancelledByTenant, now), nil
+}
+
+// GetCancellation возвращает сохранённый расчёт возврата отменённой брони.
+func (s *BookingService) GetCancellation(userID, id int) (*domain.Cancellation, error) {
+	b, err := s.bookings.GetByID(id)
+	if err != nil {
+		return nil, err
 	}
+	if err := s.authorizeView(userID, b); err != nil {
+		return nil, err
+	}
+	return s.bookings.GetCancellation(id)
+}
 
-	return nil
+// ApproveBooking одобряет заявку. После срока ответа она вот-вот истечёт,
+// и одобрить её уже нельзя, даже если планировщик ещё не успел.
+func (s *BookingService) ApproveBooking(id, userID int, reason string) error {
+	return s.ownerTransition(id, userID, domain.BookingStatusApproved, reason, func(b *domain.Booking) error {
+		if b.RespondBy != nil && time.Now().After(*b.RespondBy) {
+			return ErrResponseOverdue
+		}
+		return nil
+	})
+}
+
+func (s *BookingService) RejectBooking(id, userID int, reason string) error {
+	return s.ownerTransition(id, userID, domain.BookingStatusRejected, reason, nil)
+}
+
+// OwnerCancelBooking отменяет бронь по инициативе владельца; арендатор
+// получает полный возврат независимо от политики.
+func (s *BookingService) OwnerCancelBooking(id, userID int, reason string) error {
+	return s.ownerTransition(id, userID, domain.BookingStatusCancelled, reason, func(b *domain.Booking) error {
+		if time.Now().After(b.DateFrom) {
+			return ErrAlreadyStarted
+		}
+		return nil
+	})
+}
+
+// ConfirmBooking подтверждает одобренную бронь после списания оплаты.
+// Переход выполняет система, а не пользователь.
+func (s *BookingService) ConfirmBooking(id int, reason string) (*domain.Booking, error) {
+	t := domain.BookingTransition{To: domain.BookingStatusConfirmed, Reason: reason}
+	return s.bookings.Transition(id, t, nil)
+}
+
+// CheckInBooking отмечает заезд арендатора; после окончания брони
+// отмечать заезд поздно.
+func (s *BookingService) CheckInBooking(id, userID int, reason string) error {
+	return s.ownerTransition(id, userID, domain.BookingStatusCheckedIn, reason, func(b *domain.Booking) error {
+		if time.Now().After(b.DateTo) {
+			return ErrAlreadyEnded
+		}
+		return nil
+	})
 }
 
-func (s *BookingService) ApproveBooking(id int, ownerID int) error {
+func (s *BookingService) CompleteBooking(id, userID int, reason string) error {
+	return s.ownerTransition(id, userID, domain.BookingStatusCompleted, reason, nil)
+}
+
+// MarkNoShow фиксирует неявку; до начала брони о ней говорить рано.
+func (s *BookingService) MarkNoShow(id, userID int, reason string) error {
+	return s.ownerTransition(id, userID, domain.BookingStatusNoShow, reason, func(b *domain.Booking) error {
+		if time.Now().Before(b.DateFrom) {
+			return ErrNotStarted
+		}
+		return nil
+	})
+}
+
+// ownerTransition выполняет переход от имени владельца помещения или
+// участника организации с правом SpaceActionDecide. Заявки, ещё не
+// согласованные компанией арендатора, владельцу недоступны.
+func (s *BookingService) ownerTransition(id, userID int, to domain.BookingStatus, reason string, guard func(b *domain.Booking) error) error {
+	t := domain.BookingTransition{To: to, ActorID: &userID, Reason: reason}
+	if to == domain.BookingStatusCancelled {
+		t.CancelledBy = domain.CancelledByOwner
+	}
+	_, err := s.bookings.Transition(id, t, func(b *domain.Booking) error {
+		sp, err := s.spaces.GetByID(b.SpaceID)
+		if err != nil {
+			return err
+		}
+		if err := s.policy.Authorize(userID, sp, domain.SpaceActionDecide); err != nil {
+			return err
+		}
+		if b.Status == domain.BookingStatusPendingCompany {
+			return &domain.TransitionError{From: b.Status, To: to}
+		}
+		if guard != nil {
+			return guard(b)
+		}
+		return nil
+	})
+	return err
+}
+
+// ModifyBooking переносит бронь арендатора на новые даты. Заявку, которую
+// ещё не одобрили, достаточно просто изменить; перенос одобренной брони
+// оформляется запросом, и до решения владельца бронь остаётся на старых
+// датах. Во втором случае возвращается созданный запрос.
+func (s *BookingService) ModifyBooking(tenantID, id int, req *domain.ModifyBookingRequest) (*domain.Booking, *domain.BookingChangeRequest, error) {
 	b, err := s.bookings.GetByID(id)
 	if err != nil {
-		return err
+		return nil, nil, err
+	}
+	if b.TenantID != tenantID {
+		return nil, nil, ErrForbidden
+	}
+	if time.Now().After(b.DateFrom) {
+		return nil, nil, ErrAlreadyStarted
+	}
+	if !modifiable(b.Status) {
+		return nil, nil, ErrNotModifiable
 	}
 
 	sp, err := s.spaces.GetByID(b.SpaceID)
 	if err != nil {
-		return err
+		return nil, nil, err
+	}
+	if !sp.Bookable() {
+		return nil, nil, ErrSpaceInactive
+	}
+	from, to, err := s.freeRange(sp, req.DateFrom, req.DateTo, &b.ID)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	if sp.OwnerID != ownerID {
-		return ErrForbidden
+	if b.Status == domain.BookingStatusApproved {
+		cr := &domain.BookingChangeRequest{
+			BookingID:   b.ID,
+			DateFrom:    from,
+			DateTo:      to,
+			RequestedBy: tenantID,
+		}
+		if req.Reason != "" {
+			cr.Reason = &req.Reason
+		}
+		if err := s.changes.Create(cr); err != nil {
+			return nil, nil, err
+		}
+		return b, cr, nil
 	}
 
-	if b.Status != domain.BookingStatusPending {
-		return ErrWrongStatus
+	priceOf, err := s.pricer(sp)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	overlap, err := s.bookings.HasApprovedOverlap(b.SpaceID, b.DateFrom, b.DateTo, &b.ID)
+	// статус мог измениться между чтением и блокировкой строки
+	updated, err := s.bookings.Reschedule(id, from, to, priceOf(from, to), func(locked *domain.Booking) error {
+		if locked.Status != b.Status {
+			return ErrNotModifiable
+		}
+		return nil
+	})
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
-	if overlap {
-		return ErrOverlappingBooking
+	return updated, nil, nil
+}
+
+func modifiable(st domain.BookingStatus) bool {
+	return st == domain.BookingStatusPendingCompany ||
+		st == domain.BookingStatusPending ||
+		st == domain.BookingStatusApproved
+}
+
+// ListBookingChanges возвращает запросы на перенос брони тем же, кто
+// видит её историю.
+func (s *BookingService) ListBookingChanges(userID, bookingID int) ([]domain.BookingChangeRequest, error) {
+	b, err := s.bookings.GetByID(bookingID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorizeView(userID, b); err != nil {
+		return nil, err
 	}
+	return s.changes.ListByBooking(bookingID)
+}
 
-	if err := s.bookings.UpdateStatus(id, domain.BookingStatusApproved); err != nil {
-		return err
+// ApproveBookingChange переносит одобренную бронь на даты из запроса.
+// Стоимость пересчитывается по тарифу на момент решения: перенос — новая
+// договорённость.
+func (s *BookingService) ApproveBookingChange(userID, bookingID int, changeID int64) (*domain.Booking, error) {
+	cr, err := s.changes.GetByID(bookingID, changeID)
+	if err != nil {
+		return nil, err
 	}
-	if s.events != nil {
-		s.events <- domain.BookingEvent{
-			Type:      domain.BookingEventApproved,
-			BookingID: b.ID,
-			SpaceID:   b.SpaceID,
-			TenantID:  b.TenantID,
-			At:        time.Now(),
-		}
+	if time.Now().After(cr.DateFrom) {
+		return nil, ErrAlreadyStarted
+	}
+	b, err := s.bookings.GetByID(bookingID)
+	if err != nil {
+		return nil, err
+	}
+	closed, err := s.blackouts.HasOverlap(b.SpaceID, cr.DateFrom, cr.DateTo)
+	if err != nil {
+		return nil, err
+	}
+	if closed {
+		return nil, ErrSpaceUnavailable
+	}
+	sp, err := s.spaces.GetByID(b.SpaceID)
+	if err != nil {
+		return nil, err
+	}
+	priceOf, err := s.pricer(sp)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	b, _, err = s.changes.Approve(bookingID, changeID, userID, priceOf(cr.DateFrom, cr.DateTo), func(b *domain.Booking) error {
+		if err := s.authorizeDecision(userID, b); err != nil {
+			return err
+		}
+		if b.Status != domain.BookingStatusApproved {
+			return ErrNotModifiable
+		}
+		return nil
+	})
+	return b, err
 }
 
-func (s *BookingService) RejectBooking(id int, ownerID int) error {
-	b, err := s.bookings.GetByID(id)
+// RejectBookingChange отклоняет перенос; бронь остаётся на прежних датах.
+func (s *BookingService) RejectBookingChange(userID, bookingID int, changeID int64) (*domain.BookingChangeRequest, error) {
+	return s.changes.Reject(bookingID, changeID, userID, func(b *domain.Booking) error {
+		return s.authorizeDecision(userID, b)
+	})
+}
+
+func (s *BookingService) authorizeDecision(userID int, b *domain.Booking) error {
+	sp, err := s.spaces.GetByID(b.SpaceID)
 	if err != nil {
 		return err
 	}
+	return s.policy.Authorize(userID, sp, domain.SpaceActionDecide)
+}
 
-	sp, err := s.spaces.GetByID(b.SpaceID)
+// BookingHistory возвращает историю статусов брони арендатору, тем, кто
+// вправе видеть помещение, и согласующим компании, от имени которой она
+// сделана.
+func (s *BookingService) BookingHistory(userID, bookingID int) ([]domain.BookingStatusChange, error) {
+	b, err := s.bookings.GetByID(bookingID)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	if err := s.authorizeView(userID, b); err != nil {
+		return nil, err
+	}
+	return s.bookings.History(bookingID)
+}
 
-	if sp.OwnerID != ownerID {
+func (s *BookingService) authorizeView(userID int, b *domain.Booking) error {
+	return s.authorizeParties(userID, b.TenantID, b.SpaceID, b.OrganizationID, b.Status == domain.BookingStatusPendingCompany)
+}
+
+// authorizeParties пускает арендатора, согласующих его компании и — если
+// заявка уже дошла до владельца — тех, кто вправе видеть помещение.
+func (s *BookingService) authorizeParties(userID, tenantID, spaceID int, orgID *int, companyOnly bool) error {
+	if tenantID == userID {
+		return nil
+	}
+	if orgID != nil {
+		err := s.requireCompanyApprover(*orgID, userID)
+		if err == nil || !errors.Is(err, ErrForbidden) {
+			return err
+		}
+	}
+	if companyOnly {
 		return ErrForbidden
 	}
+	sp, err := s.spaces.GetByID(spaceID)
+	if err != nil {
+		return err
+	}
+	return s.policy.Authorize(userID, sp, domain.SpaceActionView)
+}
 
-	if b.Status != domain.BookingStatusPending {
-		return ErrWrongStatus
+// companyBookingStatus проверяет, что арендатор состоит в компании, и
+// решает, нужна ли заявке согласование внутри неё. Тот, кто сам вправе
+// согласовывать, отправляет заявку владельцу сразу.
+func (s *BookingService) companyBookingStatus(orgID, tenantID int) (domain.BookingStatus, error) {
+	role, err := s.orgs.MemberRole(orgID, tenantID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotOrgMember) {
+			return "", ErrNotCompanyMember
+		}
+		return "", err
+	}
+	org, err := s.orgs.GetByID(orgID)
+	if err != nil {
+		return "", err
+	}
+	if org.BookingApprovalRequired && !role.CanApproveBookings() {
+		return domain.BookingStatusPendingCompany, nil
 	}
+	return domain.BookingStatusPending, nil
+}
+
+// ListCompanyBookings возвращает брони сотрудников компании; доступно
+// тем, кто вправе их согласовывать.
+func (s *BookingService) ListCompanyBookings(userID, orgID int, status *domain.BookingStatus) ([]domain.Booking, error) {
+	if err := s.requireCompanyApprover(orgID, userID); err != nil {
+		return nil, err
+	}
+	return s.bookings.ListByOrganization(orgID, status)
+}
 
-	if err := s.bookings.UpdateStatus(id, domain.BookingStatusRejected); err != nil {
+// CompanyApproveBooking передаёт согласованную заявку владельцу помещения.
+// Для владельца заявка появляется только теперь, поэтому событие — created.
+func (s *BookingService) CompanyApproveBooking(userID, orgID, bookingID int, reason string) error {
+	return s.companyTransition(userID, orgID, bookingID, domain.BookingStatusPending, reason)
+}
+
+func (s *BookingService) CompanyRejectBooking(userID, orgID, bookingID int, reason string) error {
+	return s.companyTransition(userID, orgID, bookingID, domain.BookingStatusRejected, reason)
+}
+
+// companyTransition решает судьбу заявки, ждущей согласования в компании:
+// другие брони компании этим путём не меняются.
+func (s *BookingService) companyTransition(userID, orgID, bookingID int, to domain.BookingStatus, reason string) error {
+	if err := s.requireCompanyApprover(orgID, userID); err != nil {
 		return err
 	}
-	if s.events != nil {
-		s.events <- domain.BookingEvent{
-			Type:      domain.BookingEventRejected,
-			BookingID: b.ID,
-			SpaceID:   b.SpaceID,
-			TenantID:  b.TenantID,
-			At:        time.Now(),
+	t := domain.BookingTransition{To: to, ActorID: &userID, Reason: reason}
+	_, err := s.bookings.Transition(bookingID, t, func(b *domain.Booking) error {
+		if b.OrganizationID == nil || *b.OrganizationID != orgID {
+			return repository.ErrBookingNotFound
 		}
-	}
+		if b.Status != domain.BookingStatusPendingCompany {
+			return &domain.TransitionError{From: b.Status, To: to}
+		}
+		return nil
+	})
+	return err
+}
 
+func (s *BookingService) requireCompanyApprover(orgID, userID int) error {
+	role, err := s.orgs.MemberRole(orgID, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotOrgMember) {
+			return ErrForbidden
+		}
+		return err
+	}
+	if !role.CanApproveBookings() {
+		return ErrForbidden
+	}
 	return nil
 }
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"errors"
+
+	"SpaceBookProject/internal/domain"
+	"SpaceBookProject/internal/repository"
+)
+
+var ErrNotInvoiceable = errors.New("invoice is available only for approved bookings with a price")
+
+type InvoiceService struct {
+	invoices *repository.InvoiceRepository
+	bookings *repository.BookingRepository
+	booking  *BookingService
+}
+
+func NewInvoiceService(
+	invoices *repository.InvoiceRepository,
+	bookings *repository.BookingRepository,
+	booking *BookingService,
+) *InvoiceService {
+	return &InvoiceService{
+		invoices: invoices,
+		bookings: bookings,
+		booking:  booking,
+	}
+}
+
+// Invoice возвращает счёт брони. Оплаченная бронь получает счёт при
+// списании, а подтверждённая без оплаты через платформу — при первом
+// запросе. Одобренной брони, у которой ещё могут поменяться даты и цена,
+// отдаётся pro-forma без номера.
+func (s *InvoiceService) Invoice(userID, bookingID int) (*domain.Invoice, error) {
+	b, err := s.viewableBooking(userID, bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	inv, err := s.invoices.GetInvoice(b.ID)
+	if !errors.Is(err, repository.ErrInvoiceNotFound) {
+		return inv, err
+	}
+	if b.Price == nil {
+		return nil, ErrNotInvoiceable
+	}
+	switch b.Status {
+	case domain.BookingStatusApproved:
+		return s.invoices.Preview(b.ID)
+	case domain.BookingStatusConfirmed, domain.BookingStatusCheckedIn,
+		domain.BookingStatusCompleted, domain.BookingStatusNoShow:
+		return s.invoices.Issue(b.ID)
+	}
+	return nil, ErrNotInvoiceable
+}
+
+// CreditNotes возвращает credit notes, выставленные при возвратах по брони.
+func (s *InvoiceService) CreditNotes(userID, bookingID int) ([]domain.Invoice, error) {
+	if _, err := s.viewableBooking(userID, bookingID); err != nil {
+		return nil, err
+	}
+	return s.invoices.ListCreditNotes(bookingID)
+}
+
+func (s *InvoiceService) CreditNote(userID, bookingID, noteID int) (*domain.Invoice, error) {
+	if _, err := s.viewableBooking(userID, bookingID); err != nil {
+		return nil, err
+	}
+	note, err := s.invoices.GetByID(noteID)
+	if err != nil {
+		return nil, err
+	}
+	if note.BookingID != bookingID || note.Kind != domain.InvoiceKindCreditNote {
+		return nil, repository.ErrInvoiceNotFound
+	}
+	return note, nil
+}
+
+func (s *InvoiceService) viewableBooking(userID, bookingID int) (*domain.Booking, error) {
+	b, err := s.bookings.GetByID(bookingID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.booking.authorizeView(userID, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
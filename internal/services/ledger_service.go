@@ -0,0 +1,54 @@
+package services
+
+import (
+	"SpaceBookProject/internal/domain"
+	"SpaceBookProject/internal/repository"
+)
+
+type LedgerService struct {
+	ledger *repository.LedgerRepository
+}
+
+func NewLedgerService(ledger *repository.LedgerRepository) *LedgerService {
+	return &LedgerService{ledger: ledger}
+}
+
+// OwnerPayouts возвращает текущий долг платформы перед владельцем и его
+// выплаты, начиная с последней.
+func (s *LedgerService) OwnerPayouts(ownerID int) ([]domain.OwnerBalance, []domain.Payout, error) {
+	balances, err := s.ledger.OwnerBalances(ownerID)
+	if err != nil {
+		return nil, nil, err
+	}
+	payouts, err := s.ledger.ListPayouts(ownerID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return balances, payouts, nil
+}
+
+// PayoutStatement возвращает выписку по выплате её владельцу.
+func (s *LedgerService) PayoutStatement(ownerID, payoutID int) (*domain.PayoutStatement, error) {
+	p, err := s.ledger.GetPayout(payoutID)
+	if err != nil {
+		return nil, err
+	}
+	if p.OwnerID != ownerID {
+		return nil, ErrForbidden
+	}
+
+	entries, err := s.ledger.StatementEntries(p)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.PayoutStatement{Payout: *p, Entries: entries}, nil
+}
+
+// RunPayouts создаёт пакет выплат вне расписания.
+func (s *LedgerService) RunPayouts() ([]domain.Payout, error) {
+	return s.ledger.RunPayouts(0)
+}
+
+func (s *LedgerService) Reconcile() ([]domain.LedgerReconciliation, error) {
+	return s.ledger.Reconcile()
+}
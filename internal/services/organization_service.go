@@ -0,0 +1,190 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"SpaceBookProject/internal/auth"
+	"SpaceBookProject/internal/domain"
+	"SpaceBookProject/internal/repository"
+)
+
+// InvitationNotifier доставляет приглашение в организацию на email.
+type InvitationNotifier interface {
+	SendOrganizationInvitation(
+		ctx context.Context,
+		inv *domain.OrgInvitation,
+		org *domain.Organization,
+		inviter *domain.User,
+		token string,
+		validFor time.Duration,
+	) error
+}
+
+type OrganizationService struct {
+	orgs          *repository.OrganizationRepository
+	users         *repository.UserRepository
+	notifier      InvitationNotifier
+	invitationTTL time.Duration
+}
+
+func NewOrganizationService(
+	orgs *repository.OrganizationRepository,
+	users *repository.UserRepository,
+	notifier InvitationNotifier,
+	invitationTTL time.Duration,
+) *OrganizationService {
+	return &OrganizationService{
+		orgs:          orgs,
+		users:         users,
+		notifier:      notifier,
+		invitationTTL: invitationTTL,
+	}
+}
+
+func (s *OrganizationService) CreateOrganization(userID int, req *domain.CreateOrganizationRequest) (*domain.Organization, error) {
+	org := &domain.Organization{
+		Name:      strings.TrimSpace(req.Name),
+		CreatedBy: userID,
+	}
+	if err := s.orgs.Create(org); err != nil {
+		return nil, err
+	}
+	return org, nil
+}
+
+func (s *OrganizationService) ListMyOrganizations(userID int) ([]domain.Organization, error) {
+	return s.orgs.ListByMember(userID)
+}
+
+func (s *OrganizationService) UpdateOrganization(userID, orgID int, req *domain.UpdateOrganizationRequest) (*domain.Organization, error) {
+	if err := s.requireAdmin(orgID, userID); err != nil {
+		return nil, err
+	}
+	org, err := s.orgs.GetByID(orgID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Name != nil {
+		org.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.BookingApprovalRequired != nil {
+		org.BookingApprovalRequired = *req.BookingApprovalRequired
+	}
+	if err := s.orgs.Update(org); err != nil {
+		return nil, err
+	}
+	org.MyRole = domain.OrgRoleAdmin
+	return org, nil
+}
+
+func (s *OrganizationService) ListMembers(userID, orgID int) ([]domain.OrgMember, error) {
+	if _, err := s.memberRole(orgID, userID); err != nil {
+		return nil, err
+	}
+	return s.orgs.ListMembers(orgID)
+}
+
+// Invite отправляет приглашение на email. Пользователь с этим адресом
+// может ещё не быть зарегистрирован: принять приглашение он сможет после
+// регистрации.
+func (s *OrganizationService) Invite(ctx context.Context, userID, orgID int, req *domain.InviteMemberRequest) (*domain.OrgInvitation, error) {
+	if err := s.requireAdmin(orgID, userID); err != nil {
+		return nil, err
+	}
+	org, err := s.orgs.GetByID(orgID)
+	if err != nil {
+		return nil, err
+	}
+	inviter, err := s.users.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	token, hash, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	inv := &domain.OrgInvitation{
+		OrganizationID: orgID,
+		Email:          strings.TrimSpace(req.Email),
+		Role:           req.Role,
+		InvitedBy:      userID,
+		ExpiresAt:      time.Now().Add(s.invitationTTL),
+	}
+	if err := s.orgs.CreateInvitation(inv, hash); err != nil {
+		return nil, err
+	}
+	if err := s.notifier.SendOrganizationInvitation(ctx, inv, org, inviter, token, s.invitationTTL); err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+func (s *OrganizationService) ListInvitations(userID, orgID int) ([]domain.OrgInvitation, error) {
+	if err := s.requireAdmin(orgID, userID); err != nil {
+		return nil, err
+	}
+	return s.orgs.ListPendingInvitations(orgID)
+}
+
+func (s *OrganizationService) RevokeInvitation(userID, orgID int, invitationID int64) error {
+	if err := s.requireAdmin(orgID, userID); err != nil {
+		return err
+	}
+	return s.orgs.RevokeInvitation(orgID, invitationID)
+}
+
+func (s *OrganizationService) AcceptInvitation(userID int, req *domain.AcceptInvitationRequest) (*domain.OrgInvitation, error) {
+	user, err := s.users.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.orgs.AcceptInvitation(auth.HashToken(req.Token), user.ID, user.Email)
+}
+
+func (s *OrganizationService) UpdateMemberRole(userID, orgID, memberID int, req *domain.UpdateMemberRequest) error {
+	if err := s.requireAdmin(orgID, userID); err != nil {
+		return err
+	}
+	return s.orgs.UpdateMemberRole(orgID, memberID, req.Role)
+}
+
+// RemoveMember исключает участника. Администратор может исключить
+// любого, остальные — только выйти сами.
+func (s *OrganizationService) RemoveMember(userID, orgID, memberID int) error {
+	if memberID != userID {
+		if err := s.requireAdmin(orgID, userID); err != nil {
+			return err
+		}
+	} else if _, err := s.memberRole(orgID, userID); err != nil {
+		return err
+	}
+	return s.orgs.RemoveMember(orgID, memberID)
+}
+
+// memberRole возвращает роль пользователя; посторонним организация
+// не видна вовсе.
+func (s *OrganizationService) memberRole(orgID, userID int) (domain.OrgRole, error) {
+	role, err := s.orgs.MemberRole(orgID, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotOrgMember) {
+			return "", repository.ErrOrganizationNotFound
+		}
+		return "", err
+	}
+	return role, nil
+}
+
+func (s *OrganizationService) requireAdmin(orgID, userID int) error {
+	role, err := s.memberRole(orgID, userID)
+	if err != nil {
+		return err
+	}
+	if role != domain.OrgRoleAdmin {
+		return ErrForbidden
+	}
+	return nil
+}
@@ -0,0 +1,261 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"SpaceBookProject/internal/domain"
+	"SpaceBookProject/internal/payments"
+	"SpaceBookProject/internal/repository"
+)
+
+var ErrNotPayable = errors.New("only approved bookings with a price can be paid")
+
+type PaymentService struct {
+	payments *repository.PaymentRepository
+	bookings *repository.BookingRepository
+	booking  *BookingService
+	provider payments.Provider
+}
+
+func NewPaymentService(
+	paymentRepo *repository.PaymentRepository,
+	bookings *repository.BookingRepository,
+	booking *BookingService,
+	provider payments.Provider,
+) *PaymentService {
+	return &PaymentService{
+		payments: paymentRepo,
+		bookings: bookings,
+		booking:  booking,
+		provider: provider,
+	}
+}
+
+// Pay оплачивает одобренную бронь арендатора: сумма блокируется у шлюза
+// и сразу списывается, после чего бронь подтверждается. Отказ шлюза
+// возвращается платежом в статусе failed, а если плательщику нужно
+// подтвердить оплату — платежом requires_action; дальше дело за вебхуком.
+func (s *PaymentService) Pay(ctx context.Context, tenantID, bookingID int) (*domain.Payment, error) {
+	b, err := s.bookings.GetByID(bookingID)
+	if err != nil {
+		return nil, err
+	}
+	if b.TenantID != tenantID {
+		return nil, ErrForbidden
+	}
+	if b.Status != domain.BookingStatusApproved || b.Price == nil {
+		return nil, ErrNotPayable
+	}
+
+	p := &domain.Payment{
+		BookingID: b.ID,
+		Provider:  s.provider.Name(),
+		Amount:    b.Price.Amount,
+		Currency:  b.Price.Currency,
+	}
+	if err := s.payments.Create(p); err != nil {
+		return nil, err
+	}
+
+	auth, err := s.provider.Authorize(ctx, payments.AuthorizeRequest{
+		Amount:         p.Amount,
+		Currency:       p.Currency,
+		Description:    fmt.Sprintf("Booking #%d", b.ID),
+		IdempotencyKey: fmt.Sprintf("payment-%d", p.ID),
+	})
+	if err != nil {
+		// незавершённый платёж не должен навсегда заблокировать оплату брони
+		reason := err.Error()
+		if _, ferr := s.payments.Advance(p.ID, []domain.PaymentStatus{domain.PaymentStatusPending}, domain.PaymentStatusFailed, &reason); ferr != nil {
+			log.Printf("[payments] failed to mark payment %d as failed: %v", p.ID, ferr)
+		}
+		return nil, err
+	}
+
+	switch auth.Status {
+	case payments.AuthorizationDeclined:
+		return s.payments.SetAuthorization(p.ID, auth.Ref, domain.PaymentStatusFailed, nil, &auth.DeclineReason)
+	case payments.AuthorizationRequiresAction:
+		return s.payments.SetAuthorization(p.ID, auth.Ref, domain.PaymentStatusRequiresAction, &auth.ActionURL, nil)
+	}
+
+	p, err = s.payments.SetAuthorization(p.ID, auth.Ref, domain.PaymentStatusAuthorized, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return s.capture(ctx, p)
+}
+
+// capture списывает заблокированную сумму. При ошибке шлюза платёж
+// остаётся authorized: результат придёт вебхуком.
+func (s *PaymentService) capture(ctx context.Context, p *domain.Payment) (*domain.Payment, error) {
+	err := s.provider.Capture(ctx, payments.CaptureRequest{
+		Ref:            *p.ProviderRef,
+		Amount:         p.Amount,
+		IdempotencyKey: fmt.Sprintf("capture-%d", p.ID),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.payments.Advance(p.ID, []domain.PaymentStatus{domain.PaymentStatusAuthorized}, domain.PaymentStatusCaptured, nil); err != nil {
+		return nil, err
+	}
+	// платёж мог параллельно обработать вебхук — берём актуальное состояние
+	if p, err = s.payments.GetByRef(p.Provider, *p.ProviderRef); err != nil {
+		return nil, err
+	}
+	if p.Status == domain.PaymentStatusCaptured {
+		if err := s.confirm(ctx, p); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// confirm подтверждает бронь списанного платежа обычным переходом
+// BookingService. Уже подтверждённая бронь — не ошибка; если же бронь
+// успели отменить до списания, деньги возвращаются целиком.
+func (s *PaymentService) confirm(ctx context.Context, p *domain.Payment) error {
+	_, err := s.booking.ConfirmBooking(p.BookingID, "payment captured")
+	if err == nil || !errors.Is(err, ErrWrongStatus) {
+		return err
+	}
+
+	b, err := s.bookings.GetByID(p.BookingID)
+	if err != nil {
+		return err
+	}
+	switch b.Status {
+	case domain.BookingStatusConfirmed, domain.BookingStatusCheckedIn,
+		domain.BookingStatusCompleted, domain.BookingStatusNoShow:
+		return nil
+	}
+	log.Printf("[payments] booking %d is %s, refunding payment %d", b.ID, b.Status, p.ID)
+	return s.refund(ctx, p, p.Amount-p.RefundedAmount, fmt.Sprintf("refund-%d-unconfirmed", p.ID))
+}
+
+func (s *PaymentService) refund(ctx context.Context, p *domain.Payment, amount int64, key string) error {
+	if amount <= 0 {
+		return nil
+	}
+	err := s.provider.Refund(ctx, payments.RefundRequest{
+		Ref:            *p.ProviderRef,
+		Amount:         amount,
+		IdempotencyKey: key,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = s.payments.AddRefund(p.ID, key, amount)
+	return err
+}
+
+// HandleWebhook обрабатывает уведомление шлюза. Повторная доставка
+// уже обработанного события пропускается, а сами переходы платежа
+// условны, поэтому параллельная обработка одного события безопасна.
+// Ошибка означает, что шлюз должен прислать событие снова.
+func (s *PaymentService) HandleWebhook(ctx context.Context, header http.Header, body []byte) error {
+	evt, err := s.provider.VerifyWebhook(header, body)
+	if err != nil {
+		return err
+	}
+	provider := s.provider.Name()
+
+	seen, err := s.payments.WebhookProcessed(provider, evt.ID)
+	if err != nil || seen {
+		return err
+	}
+	p, err := s.payments.GetByRef(provider, evt.Ref)
+	if err != nil {
+		return err
+	}
+
+	switch evt.Type {
+	case payments.WebhookPaymentAuthorized:
+		err = s.advanceAndThen(ctx, p, domain.PaymentStatusAuthorized, nil, func(p *domain.Payment) error {
+			_, err := s.capture(ctx, p)
+			return err
+		})
+	case payments.WebhookPaymentCaptured:
+		err = s.advanceAndThen(ctx, p, domain.PaymentStatusCaptured, nil, func(p *domain.Payment) error {
+			return s.confirm(ctx, p)
+		})
+	case payments.WebhookPaymentFailed:
+		reason := evt.Reason
+		err = s.advanceAndThen(ctx, p, domain.PaymentStatusFailed, &reason, nil)
+	case payments.WebhookRefundSucceeded:
+		_, err = s.payments.SetRefunded(p.ID, evt.Amount)
+	default:
+		log.Printf("[payments] ignoring %s webhook %s of type %s", provider, evt.ID, evt.Type)
+	}
+	if err != nil {
+		return err
+	}
+	return s.payments.MarkWebhookProcessed(provider, evt.ID)
+}
+
+// advanceAndThen переводит незавершённый платёж в статус to и, если платёж
+// в нём оказался, выполняет then — в том числе при повторе после сбоя.
+func (s *PaymentService) advanceAndThen(
+	ctx context.Context,
+	p *domain.Payment,
+	to domain.PaymentStatus,
+	reason *string,
+	then func(p *domain.Payment) error,
+) error {
+	open := []domain.PaymentStatus{
+		domain.PaymentStatusPending,
+		domain.PaymentStatusRequiresAction,
+		domain.PaymentStatusAuthorized,
+	}
+	if _, err := s.payments.Advance(p.ID, open, to, reason); err != nil {
+		return err
+	}
+	p, err := s.payments.GetByRef(p.Provider, *p.ProviderRef)
+	if err != nil {
+		return err
+	}
+	if then == nil || p.Status != to {
+		return nil
+	}
+	return then(p)
+}
+
+// HandleEvent — dispatcher для BookingEventWorker: при отмене оплаченной
+// брони возвращается сумма, рассчитанная при отмене, а без расчёта — вся.
+// Ключ идемпотентности привязан к событию, поэтому повтор события не вернёт
+// деньги дважды.
+func (s *PaymentService) HandleEvent(ctx context.Context, evt domain.BookingEvent) error {
+	if evt.Type != domain.BookingEventCancelled {
+		return nil
+	}
+	p, err := s.payments.GetCaptured(evt.BookingID)
+	if errors.Is(err, repository.ErrPaymentNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	amount := p.Amount - p.RefundedAmount
+	if c := evt.Cancellation; c != nil && c.Refund != nil && c.Refund.Currency == p.Currency {
+		amount = min(amount, c.Refund.Amount)
+	}
+	return s.refund(ctx, p, amount, fmt.Sprintf("refund-%d-event-%d", p.ID, evt.ID))
+}
+
+// ListPayments возвращает платежи брони тем, кто видит её историю.
+func (s *PaymentService) ListPayments(userID, bookingID int) ([]domain.Payment, error) {
+	b, err := s.bookings.GetByID(bookingID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.booking.authorizeView(userID, b); err != nil {
+		return nil, err
+	}
+	return s.payments.ListByBooking(bookingID)
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"strings"
+
+	"SpaceBookProject/internal/domain"
+	"SpaceBookProject/internal/repository"
+)
+
+type PricingService struct {
+	pricing *repository.PricingRepository
+	spaces  *repository.SpaceRepository
+	policy  *SpacePolicy
+}
+
+func NewPricingService(
+	pricing *repository.PricingRepository,
+	spaces *repository.SpaceRepository,
+	policy *SpacePolicy,
+) *PricingService {
+	return &PricingService{
+		pricing: pricing,
+		spaces:  spaces,
+		policy:  policy,
+	}
+}
+
+func (s *PricingService) GetPricing(spaceID int) (*domain.SpacePricing, error) {
+	sp, err := s.spaces.GetByID(spaceID)
+	if err != nil {
+		return nil, err
+	}
+	if sp.DeletedAt != nil {
+		return nil, repository.ErrSpaceNotFound
+	}
+	return s.pricing.Get(sp.ID)
+}
+
+// SetPricing заменяет тарифы помещения. Уже созданные брони сохраняют
+// рассчитанную при создании стоимость.
+func (s *PricingService) SetPricing(userID, spaceID int, req *domain.SetPricingRequest) (*domain.SpacePricing, error) {
+	sp, err := s.policy.managedSpace(s.spaces, userID, spaceID, domain.SpaceActionManage)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &domain.SpacePricing{
+		SpaceID:        sp.ID,
+		Currency:       strings.ToUpper(req.Currency),
+		Rates:          req.Rates,
+		WeekendPercent: req.WeekendPercent,
+		Seasons:        req.Seasons,
+		Discounts:      req.Discounts,
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.pricing.Upsert(p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Quote рассчитывает стоимость брони на даты по текущему тарифу, с теми же
+// правилами разбора дат, что и при создании брони. Занятость дат не
+// проверяется: расчёт ни к чему не обязывает.
+func (s *PricingService) Quote(spaceID int, req *domain.QuoteRequest) (*domain.Quote, error) {
+	sp, err := s.spaces.GetByID(spaceID)
+	if err != nil {
+		return nil, err
+	}
+	if !sp.Bookable() {
+		return nil, ErrSpaceInactive
+	}
+
+	from, to, err := parseBookingRange(sp, req.DateFrom, req.DateTo)
+	if err != nil {
+		return nil, err
+	}
+	p, err := s.pricing.Get(sp.ID)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := sp.Location()
+	if err != nil {
+		return nil, err
+	}
+	return p.Quote(from, to, loc), nil
+}
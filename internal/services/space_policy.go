@@ -0,0 +1,65 @@
+package services
+
+import (
+	"errors"
+
+	"SpaceBookProject/internal/domain"
+	"SpaceBookProject/internal/repository"
+)
+
+// SpacePolicy решает, может ли пользователь выполнить действие над
+// помещением. Владелец (owner_id) может всё; участники организации,
+// которой принадлежит помещение, — то, что разрешает их роль.
+type SpacePolicy struct {
+	orgs *repository.OrganizationRepository
+}
+
+func NewSpacePolicy(orgs *repository.OrganizationRepository) *SpacePolicy {
+	return &SpacePolicy{orgs: orgs}
+}
+
+// Authorize возвращает nil, если действие разрешено, и ErrForbidden, если нет.
+func (p *SpacePolicy) Authorize(userID int, sp *domain.Space, action domain.SpaceAction) error {
+	if sp.OwnerID == userID {
+		return nil
+	}
+	if sp.OrganizationID == nil {
+		return ErrForbidden
+	}
+
+	role, err := p.orgs.MemberRole(*sp.OrganizationID, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotOrgMember) {
+			return ErrForbidden
+		}
+		return err
+	}
+	if !role.Allows(action) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// Allowed — то же, что Authorize, но для мест, где отказ не ошибка.
+func (p *SpacePolicy) Allowed(userID int, sp *domain.Space, action domain.SpaceAction) (bool, error) {
+	err := p.Authorize(userID, sp, action)
+	if errors.Is(err, ErrForbidden) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// managedSpace загружает неудалённое помещение и проверяет право на action.
+func (p *SpacePolicy) managedSpace(spaces *repository.SpaceRepository, userID, spaceID int, action domain.SpaceAction) (*domain.Space, error) {
+	sp, err := spaces.GetByID(spaceID)
+	if err != nil {
+		return nil, err
+	}
+	if sp.DeletedAt != nil {
+		return nil, repository.ErrSpaceNotFound
+	}
+	if err := p.Authorize(userID, sp, action); err != nil {
+		return nil, err
+	}
+	return sp, nil
+}
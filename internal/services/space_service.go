@@ -1,16 +1,36 @@
 package services
 
 import (
+	"errors"
+	"time"
+
 	"SpaceBookProject/internal/domain"
 	"SpaceBookProject/internal/repository"
 )
 
+var (
+	ErrInvalidTimezone    = errors.New("unknown timezone")
+	ErrInvalidSlotMinutes = errors.New("slot_minutes must divide 24 hours evenly")
+	ErrSpaceInactive      = errors.New("space is not accepting bookings")
+	ErrSpaceUnpublished   = errors.New("space was unpublished by moderation")
+)
+
 type SpaceService struct {
-	repo *repository.SpaceRepository
+	repo   *repository.SpaceRepository
+	orgs   *repository.OrganizationRepository
+	policy *SpacePolicy
 }
 
-func NewSpaceService(repo *repository.SpaceRepository) *SpaceService {
-	return &SpaceService{repo: repo}
+func NewSpaceService(
+	repo *repository.SpaceRepository,
+	orgs *repository.OrganizationRepository,
+	policy *SpacePolicy,
+) *SpaceService {
+	return &SpaceService{
+		repo:   repo,
+		orgs:   orgs,
+		policy: policy,
+	}
 }
 
 func (s *SpaceService) ListSpaces(f repository.SpaceFilter) ([]domain.Space, error) {
@@ -18,13 +38,40 @@ func (s *SpaceService) ListSpaces(f repository.SpaceFilter) ([]domain.Space, err
 }
 
 func (s *SpaceService) CreateSpace(ownerID int, req *domain.CreateSpaceRequest) (*domain.Space, error) {
+	timezone := req.Timezone
+	if timezone == "" {
+		timezone = domain.DefaultSpaceTimezone
+	}
+	slotMinutes := req.SlotMinutes
+	if slotMinutes == 0 {
+		slotMinutes = domain.DefaultSlotMinutes
+	}
+	if err := validateSchedule(timezone, slotMinutes); err != nil {
+		return nil, err
+	}
+	responseHours := req.ResponseHours
+	if responseHours == 0 {
+		responseHours = domain.DefaultResponseHours
+	}
+	if req.OrganizationID != nil {
+		if err := s.requireOrgAdmin(*req.OrganizationID, ownerID); err != nil {
+			return nil, err
+		}
+	}
+
 	space := &domain.Space{
-		OwnerID:     ownerID,
-		Title:       req.Title,
-		Description: req.Description,
-		AreaM2:      req.AreaM2,
-		Price:       req.Price,
-		Phone:       req.Phone,
+		OwnerID:        ownerID,
+		OrganizationID: req.OrganizationID,
+		Title:          req.Title,
+		Description:    req.Description,
+		AreaM2:         req.AreaM2,
+		Price:          req.Price,
+		Phone:          req.Phone,
+		Timezone:       timezone,
+		SlotMinutes:    slotMinutes,
+		ResponseHours:  responseHours,
+		// совпадает со значением колонки по умолчанию
+		CancellationPolicy: domain.DefaultCancellationPolicy(),
 	}
 
 	if err := s.repo.Create(space); err != nil {
@@ -32,3 +79,183 @@ func (s *SpaceService) CreateSpace(ownerID int, req *domain.CreateSpaceRequest)
 	}
 	return space, nil
 }
+
+// GetSpace отдаёт помещение по id. Выключенное или снятое модерацией
+// помещение видят только владелец и участники его организации,
+// удалённое — никто.
+func (s *SpaceService) GetSpace(id, viewerID int) (*domain.Space, error) {
+	sp, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if sp.DeletedAt != nil {
+		return nil, repository.ErrSpaceNotFound
+	}
+	if !sp.IsActive || sp.UnpublishedAt != nil {
+		ok, err := s.policy.Allowed(viewerID, sp, domain.SpaceActionView)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, repository.ErrSpaceNotFound
+		}
+	}
+	return sp, nil
+}
+
+// ListOwnerSpaces возвращает помещения пользователя и организаций,
+// в которых он состоит.
+func (s *SpaceService) ListOwnerSpaces(ownerID int) ([]domain.Space, error) {
+	return s.repo.ListFiltered(repository.SpaceFilter{
+		ManagedBy:       &ownerID,
+		IncludeInactive: true,
+	})
+}
+
+func (s *SpaceService) UpdateSpace(userID, id int, req *domain.UpdateSpaceRequest) (*domain.Space, error) {
+	sp, err := s.policy.managedSpace(s.repo, userID, id, domain.SpaceActionManage)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Title != nil {
+		sp.Title = *req.Title
+	}
+	if req.Description != nil {
+		sp.Description = *req.Description
+	}
+	if req.AreaM2 != nil {
+		sp.AreaM2 = *req.AreaM2
+	}
+	if req.Price != nil {
+		sp.Price = *req.Price
+	}
+	if req.Phone != nil {
+		sp.Phone = *req.Phone
+	}
+	if req.Timezone != nil {
+		sp.Timezone = *req.Timezone
+	}
+	if req.SlotMinutes != nil {
+		sp.SlotMinutes = *req.SlotMinutes
+	}
+	if req.ResponseHours != nil {
+		sp.ResponseHours = *req.ResponseHours
+	}
+	if err := validateSchedule(sp.Timezone, sp.SlotMinutes); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(sp); err != nil {
+		return nil, err
+	}
+	return sp, nil
+}
+
+func (s *SpaceService) SetActive(userID, id int, active bool) (*domain.Space, error) {
+	sp, err := s.policy.managedSpace(s.repo, userID, id, domain.SpaceActionManage)
+	if err != nil {
+		return nil, err
+	}
+	if active && sp.UnpublishedAt != nil {
+		return nil, ErrSpaceUnpublished
+	}
+	if err := s.repo.SetActive(id, active); err != nil {
+		return nil, err
+	}
+	sp.IsActive = active
+	return sp, nil
+}
+
+func (s *SpaceService) DeleteSpace(userID, id int) error {
+	if _, err := s.policy.managedSpace(s.repo, userID, id, domain.SpaceActionManage); err != nil {
+		return err
+	}
+	return s.repo.SoftDelete(id)
+}
+
+// SetOrganization передаёт помещение организации или отвязывает его.
+// Привязать может только владелец помещения, будучи администратором
+// целевой организации; отвязать — владелец или администратор текущей.
+func (s *SpaceService) SetOrganization(userID, id int, orgID *int) (*domain.Space, error) {
+	sp, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if sp.DeletedAt != nil {
+		return nil, repository.ErrSpaceNotFound
+	}
+
+	if orgID != nil {
+		if sp.OwnerID != userID {
+			return nil, ErrForbidden
+		}
+		if err := s.requireOrgAdmin(*orgID, userID); err != nil {
+			return nil, err
+		}
+	} else if err := s.policy.Authorize(userID, sp, domain.SpaceActionManage); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.SetOrganization(id, orgID); err != nil {
+		return nil, err
+	}
+	sp.OrganizationID = orgID
+	return sp, nil
+}
+
+// SetInstantBooking включает, выключает или меняет правила мгновенного
+// бронирования помещения.
+func (s *SpaceService) SetInstantBooking(userID, id int, rules domain.InstantBookingRules) (*domain.Space, error) {
+	sp, err := s.policy.managedSpace(s.repo, userID, id, domain.SpaceActionManage)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.SetInstantBooking(id, rules); err != nil {
+		return nil, err
+	}
+	sp.InstantBooking = rules
+	return sp, nil
+}
+
+// SetCancellationPolicy меняет политику отмены. Уже отменённые брони
+// сохраняют рассчитанный при отмене возврат.
+func (s *SpaceService) SetCancellationPolicy(userID, id int, policy domain.CancellationPolicy) (*domain.Space, error) {
+	sp, err := s.policy.managedSpace(s.repo, userID, id, domain.SpaceActionManage)
+	if err != nil {
+		return nil, err
+	}
+	policy, err = policy.Normalize()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.SetCancellationPolicy(id, policy); err != nil {
+		return nil, err
+	}
+	sp.CancellationPolicy = policy
+	return sp, nil
+}
+
+func (s *SpaceService) requireOrgAdmin(orgID, userID int) error {
+	role, err := s.orgs.MemberRole(orgID, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotOrgMember) {
+			return ErrForbidden
+		}
+		return err
+	}
+	if role != domain.OrgRoleAdmin {
+		return ErrForbidden
+	}
+	return nil
+}
+
+func validateSchedule(timezone string, slotMinutes int) error {
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return ErrInvalidTimezone
+	}
+	if !domain.ValidSlotMinutes(slotMinutes) {
+		return ErrInvalidSlotMinutes
+	}
+	return nil
+}
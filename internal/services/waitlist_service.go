@@ -0,0 +1,206 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"SpaceBookProject/internal/domain"
+	"SpaceBookProject/internal/repository"
+)
+
+var (
+	ErrSpaceAvailable = errors.New("space is free for these dates, book it directly")
+	ErrDatesInPast    = errors.New("dates must be in the future")
+)
+
+// WaitlistNotifier сообщает арендатору, что даты из листа ожидания
+// освободились; booking не nil, если заявка создана автоматически.
+type WaitlistNotifier interface {
+	SendWaitlistOpening(
+		ctx context.Context,
+		tenant *domain.User,
+		sp *domain.Space,
+		entry *domain.WaitlistEntry,
+		booking *domain.Booking,
+	) error
+}
+
+type WaitlistService struct {
+	waitlist *repository.WaitlistRepository
+	bookings *repository.BookingRepository
+	spaces   *repository.SpaceRepository
+	users    *repository.UserRepository
+	booking  *BookingService
+	policy   *SpacePolicy
+	notifier WaitlistNotifier
+}
+
+func NewWaitlistService(
+	waitlist *repository.WaitlistRepository,
+	bookings *repository.BookingRepository,
+	spaces *repository.SpaceRepository,
+	users *repository.UserRepository,
+	booking *BookingService,
+	policy *SpacePolicy,
+	notifier WaitlistNotifier,
+) *WaitlistService {
+	return &WaitlistService{
+		waitlist: waitlist,
+		bookings: bookings,
+		spaces:   spaces,
+		users:    users,
+		booking:  booking,
+		policy:   policy,
+		notifier: notifier,
+	}
+}
+
+// Join ставит арендатора в очередь на даты, которые сейчас заняты.
+func (s *WaitlistService) Join(tenantID, spaceID int, req *domain.JoinWaitlistRequest) (*domain.WaitlistEntry, error) {
+	sp, err := s.spaces.GetByID(spaceID)
+	if err != nil {
+		return nil, err
+	}
+	if !sp.Bookable() {
+		return nil, ErrSpaceInactive
+	}
+	if sp.OwnerID == tenantID {
+		return nil, ErrOwnSpace
+	}
+
+	from, to, err := parseBookingRange(sp, req.DateFrom, req.DateTo)
+	if err != nil {
+		return nil, err
+	}
+	if from.Before(time.Now()) {
+		return nil, ErrDatesInPast
+	}
+	if err := s.booking.checkFree(sp.ID, from, to, nil); err == nil {
+		return nil, ErrSpaceAvailable
+	} else if !errors.Is(err, ErrAlreadyBooked) {
+		return nil, err
+	}
+
+	entry := &domain.WaitlistEntry{
+		SpaceID:  sp.ID,
+		TenantID: tenantID,
+		DateFrom: from,
+		DateTo:   to,
+		AutoBook: req.AutoBook,
+	}
+	if err := s.waitlist.Create(entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+func (s *WaitlistService) Leave(tenantID, entryID int) error {
+	return s.waitlist.Cancel(entryID, tenantID)
+}
+
+func (s *WaitlistService) ListMine(tenantID int) ([]domain.WaitlistEntry, error) {
+	return s.waitlist.ListByTenant(tenantID)
+}
+
+// ListForSpace показывает очередь помещения тем, кто вправе его видеть.
+func (s *WaitlistService) ListForSpace(userID, spaceID int) ([]domain.WaitlistEntry, error) {
+	if _, err := s.policy.managedSpace(s.spaces, userID, spaceID, domain.SpaceActionView); err != nil {
+		return nil, err
+	}
+	return s.waitlist.ListWaiting(spaceID)
+}
+
+func (s *WaitlistService) Demand(userID int) ([]domain.WaitlistDemand, error) {
+	return s.waitlist.Demand(userID)
+}
+
+// HandleEvent — dispatcher для BookingEventWorker. Когда бронь отменена
+// или отклонена, ждущие пересекающихся дат получают письмо в порядке
+// очереди, а первому из тех, чьи даты теперь свободны, при auto_book
+// сразу создаётся заявка. Запись выводится из очереди до отправки письма,
+// поэтому повтор события никого не уведомит дважды.
+func (s *WaitlistService) HandleEvent(ctx context.Context, evt domain.BookingEvent) error {
+	if evt.Type != domain.BookingEventCancelled && evt.Type != domain.BookingEventRejected {
+		return nil
+	}
+
+	b, err := s.bookings.GetByID(evt.BookingID)
+	if err != nil {
+		return err
+	}
+	entries, err := s.waitlist.ListWaitingOverlapping(b.SpaceID, b.DateFrom, b.DateTo)
+	if err != nil || len(entries) == 0 {
+		return err
+	}
+	sp, err := s.spaces.GetByID(b.SpaceID)
+	if err != nil {
+		return err
+	}
+	if !sp.Bookable() {
+		return nil
+	}
+
+	first := true
+	for i := range entries {
+		entry := &entries[i]
+		if entry.DateFrom.Before(time.Now()) {
+			continue
+		}
+		// даты могли остаться занятыми другой бронью
+		if err := s.booking.checkFree(sp.ID, entry.DateFrom, entry.DateTo, nil); err != nil {
+			if errors.Is(err, ErrAlreadyBooked) || errors.Is(err, ErrSpaceUnavailable) {
+				continue
+			}
+			return err
+		}
+
+		book := first && entry.AutoBook
+		first = false
+
+		// запись забирается из очереди до бронирования: если дальше что-то
+		// упадёт, повтор события не создаст вторую заявку
+		claimed, err := s.waitlist.Claim(entry.ID)
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			continue
+		}
+
+		var created *domain.Booking
+		if book {
+			created = s.autoBook(entry)
+		}
+		if created != nil {
+			if err := s.waitlist.LinkBooking(entry.ID, created.ID); err != nil {
+				log.Printf("[waitlist] failed to link booking %d to entry %d: %v", created.ID, entry.ID, err)
+			}
+		}
+
+		tenant, err := s.users.GetByID(entry.TenantID)
+		if err != nil {
+			return err
+		}
+		if err := s.notifier.SendWaitlistOpening(ctx, tenant, sp, entry, created); err != nil {
+			log.Printf("[waitlist] failed to notify tenant %d about space %d: %v", entry.TenantID, sp.ID, err)
+		}
+	}
+	return nil
+}
+
+// autoBook создаёт заявку от имени арендатора по обычным правилам
+// бронирования; при отказе арендатор просто получит письмо.
+func (s *WaitlistService) autoBook(entry *domain.WaitlistEntry) *domain.Booking {
+	b, err := s.booking.CreateBooking(entry.TenantID, &domain.CreateBookingRequest{
+		SpaceID:  entry.SpaceID,
+		DateFrom: entry.DateFrom.Format(time.RFC3339),
+		DateTo:   entry.DateTo.Format(time.RFC3339),
+	})
+	if err != nil {
+		log.Printf("[waitlist] auto-booking for entry %d failed: %v", entry.ID, err)
+		return nil
+	}
+	return b
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"SpaceBookProject/internal/domain"
+	"SpaceBookProject/internal/repository"
+)
+
+const webhookDeliveriesLimit = 100
+
+type WebhookService struct {
+	repo *repository.WebhookRepository
+}
+
+func NewWebhookService(repo *repository.WebhookRepository) *WebhookService {
+	return &WebhookService{repo: repo}
+}
+
+// CreateEndpoint регистрирует endpoint. Секрет возвращается только в ответе
+// на создание; если клиент его не передал, он генерируется.
+func (s *WebhookService) CreateEndpoint(userID int, req *domain.CreateWebhookRequest) (*domain.WebhookEndpoint, error) {
+	secret := req.Secret
+	if secret == "" {
+		var err error
+		if secret, err = generateWebhookSecret(); err != nil {
+			return nil, err
+		}
+	}
+
+	e := &domain.WebhookEndpoint{
+		UserID:     userID,
+		URL:        req.URL,
+		Secret:     secret,
+		EventTypes: req.EventTypes,
+	}
+	if err := s.repo.CreateEndpoint(e); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (s *WebhookService) ListEndpoints(userID int) ([]domain.WebhookEndpoint, error) {
+	items, err := s.repo.ListEndpointsByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range items {
+		items[i].Secret = ""
+	}
+	return items, nil
+}
+
+func (s *WebhookService) DeleteEndpoint(userID, id int) error {
+	if _, err := s.ownedEndpoint(userID, id); err != nil {
+		return err
+	}
+	return s.repo.DeleteEndpoint(id)
+}
+
+func (s *WebhookService) ListDeliveries(userID, endpointID int) ([]domain.WebhookDelivery, error) {
+	if _, err := s.ownedEndpoint(userID, endpointID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListDeliveries(endpointID, webhookDeliveriesLimit)
+}
+
+func (s *WebhookService) ListAttempts(userID, endpointID int, deliveryID int64) ([]domain.WebhookDeliveryAttempt, error) {
+	if _, err := s.ownedDelivery(userID, endpointID, deliveryID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListAttempts(deliveryID)
+}
+
+func (s *WebhookService) Redeliver(userID, endpointID int, deliveryID int64) error {
+	if _, err := s.ownedDelivery(userID, endpointID, deliveryID); err != nil {
+		return err
+	}
+	return s.repo.Redeliver(deliveryID)
+}
+
+func (s *WebhookService) ownedEndpoint(userID, id int) (*domain.WebhookEndpoint, error) {
+	e, err := s.repo.GetEndpoint(id)
+	if err != nil {
+		return nil, err
+	}
+	if e.UserID != userID {
+		return nil, ErrForbidden
+	}
+	return e, nil
+}
+
+func (s *WebhookService) ownedDelivery(userID, endpointID int, deliveryID int64) (*domain.WebhookDelivery, error) {
+	if _, err := s.ownedEndpoint(userID, endpointID); err != nil {
+		return nil, err
+	}
+	d, err := s.repo.GetDelivery(deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if d.EndpointID != endpointID {
+		return nil, repository.ErrWebhookDeliveryNotFound
+	}
+	return d, nil
+}
+
+func generateWebhookSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(buf), nil
+}
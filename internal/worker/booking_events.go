@@ -6,31 +6,143 @@ import (
 	"time"
 
 	"SpaceBookProject/internal/domain"
+	"SpaceBookProject/internal/repository"
 )
 
+// Dispatcher доставляет событие потребителю. Ошибка означает, что событие
+// нужно повторить позже.
+type Dispatcher interface {
+	Dispatch(ctx context.Context, evt domain.BookingEvent) error
+}
+
+type DispatcherFunc func(ctx context.Context, evt domain.BookingEvent) error
+
+func (f DispatcherFunc) Dispatch(ctx context.Context, evt domain.BookingEvent) error {
+	return f(ctx, evt)
+}
+
+// MultiDispatcher передаёт событие всем dispatcher'ам по очереди и
+// останавливается на первой ошибке — событие будет повторено целиком,
+// поэтому каждый dispatcher должен быть идемпотентным.
+func MultiDispatcher(ds ...Dispatcher) Dispatcher {
+	return DispatcherFunc(func(ctx context.Context, evt domain.BookingEvent) error {
+		for _, d := range ds {
+			if err := d.Dispatch(ctx, evt); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// LogDispatcher просто пишет событие в лог.
+var LogDispatcher = DispatcherFunc(func(_ context.Context, evt domain.BookingEvent) error {
+	log.Printf(
+		"[worker] event=%s booking_id=%d space_id=%d tenant_id=%d at=%s\n",
+		evt.Type, evt.BookingID, evt.SpaceID, evt.TenantID,
+		evt.At.Format(time.RFC3339),
+	)
+	return nil
+})
+
+type BookingEventWorkerConfig struct {
+	PollInterval time.Duration
+	BatchSize    int
+	Lease        time.Duration
+	MaxAttempts  int
+	BaseBackoff  time.Duration
+	MaxBackoff   time.Duration
+}
+
 type BookingEventWorker struct {
-	Events <-chan domain.BookingEvent
+	outbox     *repository.OutboxRepository
+	dispatcher Dispatcher
+	cfg        BookingEventWorkerConfig
 }
 
-func NewBookingEventWorker(events <-chan domain.BookingEvent) *BookingEventWorker {
-	return &BookingEventWorker{Events: events}
+func NewBookingEventWorker(outbox *repository.OutboxRepository, dispatcher Dispatcher, cfg BookingEventWorkerConfig) *BookingEventWorker {
+	return &BookingEventWorker{
+		outbox:     outbox,
+		dispatcher: dispatcher,
+		cfg:        cfg,
+	}
 }
 
 func (w *BookingEventWorker) Run(ctx context.Context) {
 	log.Println("[worker] booking event worker started")
 	defer log.Println("[worker] booking event worker stopped")
 
+	ticker := time.NewTicker(w.cfg.PollInterval)
+	defer ticker.Stop()
+
 	for {
+		if ctx.Err() != nil {
+			return
+		}
+		// полная пачка — в очереди, скорее всего, есть ещё: не ждём тика
+		if w.processBatch(ctx) == w.cfg.BatchSize {
+			continue
+		}
+
 		select {
 		case <-ctx.Done():
 			return
-		case evt := <-w.Events:
-			log.Printf(
-				"[worker] event=%s booking_id=%d space_id=%d tenant_id=%d at=%s\n",
-				evt.Type, evt.BookingID, evt.SpaceID, evt.TenantID,
-				evt.At.Format(time.RFC3339),
-			)
-			// TODO: here insert into DB, send email, etc.
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *BookingEventWorker) processBatch(ctx context.Context) int {
+	msgs, err := w.outbox.Claim(w.cfg.BatchSize, w.cfg.Lease)
+	if err != nil {
+		log.Printf("[worker] failed to claim outbox events: %v", err)
+		return 0
+	}
+
+	for _, msg := range msgs {
+		if ctx.Err() != nil {
+			// незавершённые события вернутся в очередь по истечении аренды
+			return 0
+		}
+		w.handle(ctx, msg)
+	}
+	return len(msgs)
+}
+
+func (w *BookingEventWorker) handle(ctx context.Context, msg domain.OutboxMessage) {
+	err := w.dispatcher.Dispatch(ctx, msg.Event)
+	if err == nil {
+		if err := w.outbox.MarkDelivered(msg.ID); err != nil {
+			log.Printf("[worker] failed to mark event %d delivered: %v", msg.ID, err)
+		}
+		return
+	}
+
+	if msg.Attempts >= w.cfg.MaxAttempts {
+		log.Printf("[worker] event %d moved to dead letter after %d attempts: %v", msg.ID, msg.Attempts, err)
+		if err := w.outbox.MarkDead(msg.ID, err.Error()); err != nil {
+			log.Printf("[worker] failed to mark event %d dead: %v", msg.ID, err)
+		}
+		return
+	}
+
+	retryAt := time.Now().Add(Backoff(msg.Attempts, w.cfg.BaseBackoff, w.cfg.MaxBackoff))
+	log.Printf("[worker] event %d failed (attempt %d), retry at %s: %v",
+		msg.ID, msg.Attempts, retryAt.Format(time.RFC3339), err)
+	if err := w.outbox.MarkFailed(msg.ID, err.Error(), retryAt); err != nil {
+		log.Printf("[worker] failed to reschedule event %d: %v", msg.ID, err)
+	}
+}
+
+// Backoff возвращает экспоненциальную задержку перед попыткой attempt+1:
+// base, 2*base, 4*base, ... но не больше max.
+func Backoff(attempt int, base, max time.Duration) time.Duration {
+	d := base
+	for i := 1; i < attempt; i++ {
+		d *= 2
+		if d >= max {
+			return max
 		}
 	}
+	return d
 }
@@ -0,0 +1,64 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"SpaceBookProject/internal/repository"
+)
+
+type BookingExpirySchedulerConfig struct {
+	Interval  time.Duration
+	BatchSize int
+}
+
+// BookingExpiryScheduler периодически переводит в expired заявки, которые
+// владелец не рассмотрел вовремя. Запускать можно на всех репликах:
+// одновременно проход выполняет только одна, см. BookingRepository.ExpireDue.
+type BookingExpiryScheduler struct {
+	bookings *repository.BookingRepository
+	cfg      BookingExpirySchedulerConfig
+}
+
+func NewBookingExpiryScheduler(bookings *repository.BookingRepository, cfg BookingExpirySchedulerConfig) *BookingExpiryScheduler {
+	return &BookingExpiryScheduler{
+		bookings: bookings,
+		cfg:      cfg,
+	}
+}
+
+func (s *BookingExpiryScheduler) Run(ctx context.Context) {
+	log.Println("[worker] booking expiry scheduler started")
+	defer log.Println("[worker] booking expiry scheduler stopped")
+
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+		if s.expireBatch() == s.cfg.BatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *BookingExpiryScheduler) expireBatch() int {
+	expired, err := s.bookings.ExpireDue(s.cfg.BatchSize)
+	if err != nil {
+		log.Printf("[worker] failed to expire bookings: %v", err)
+		return 0
+	}
+	for _, b := range expired {
+		log.Printf("[worker] booking %d expired", b.ID)
+	}
+	return len(expired)
+}
@@ -0,0 +1,57 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"SpaceBookProject/internal/repository"
+)
+
+type PayoutSchedulerConfig struct {
+	Period        time.Duration
+	CheckInterval time.Duration
+}
+
+// PayoutScheduler раз в Period создаёт пакет выплат владельцам. Запускать
+// можно на всех репликах: см. LedgerRepository.RunPayouts.
+type PayoutScheduler struct {
+	ledger *repository.LedgerRepository
+	cfg    PayoutSchedulerConfig
+}
+
+func NewPayoutScheduler(ledger *repository.LedgerRepository, cfg PayoutSchedulerConfig) *PayoutScheduler {
+	return &PayoutScheduler{
+		ledger: ledger,
+		cfg:    cfg,
+	}
+}
+
+func (s *PayoutScheduler) Run(ctx context.Context) {
+	log.Println("[worker] payout scheduler started")
+	defer log.Println("[worker] payout scheduler stopped")
+
+	ticker := time.NewTicker(s.cfg.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		s.runBatch()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *PayoutScheduler) runBatch() {
+	payouts, err := s.ledger.RunPayouts(s.cfg.Period)
+	if err != nil {
+		log.Printf("[worker] failed to run payouts: %v", err)
+		return
+	}
+	for _, p := range payouts {
+		log.Printf("[worker] payout %d: %d %s to owner %d", p.ID, p.Amount, p.Currency, p.OwnerID)
+	}
+}
@@ -0,0 +1,210 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"SpaceBookProject/internal/domain"
+	"SpaceBookProject/internal/repository"
+)
+
+const (
+	WebhookEventHeader     = "X-SpaceBook-Event"
+	WebhookDeliveryHeader  = "X-SpaceBook-Delivery"
+	WebhookTimestampHeader = "X-SpaceBook-Timestamp"
+	WebhookSignatureHeader = "X-SpaceBook-Signature"
+)
+
+// SignWebhook считает подпись "sha256=<hex>" от HMAC-SHA256(secret, "<timestamp>.<body>").
+// Метка времени входит в подпись, чтобы получатель мог отбрасывать повторы.
+func SignWebhook(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// NewWebhookDispatcher раскладывает outbox-событие по доставкам для всех
+// подписанных endpoint'ов. Сама отправка выполняется WebhookWorker.
+func NewWebhookDispatcher(repo *repository.WebhookRepository) Dispatcher {
+	return DispatcherFunc(func(_ context.Context, evt domain.BookingEvent) error {
+		if !evt.Type.OwnerVisible() {
+			return nil
+		}
+		endpoints, err := repo.ListEndpointsForEvent(evt)
+		if err != nil {
+			return err
+		}
+		if len(endpoints) == 0 {
+			return nil
+		}
+
+		payload, err := json.Marshal(domain.WebhookPayload{
+			ID:        evt.ID,
+			Type:      evt.Type,
+			CreatedAt: evt.At,
+			Data:      evt,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, e := range endpoints {
+			d := &domain.WebhookDelivery{
+				EndpointID: e.ID,
+				EventID:    evt.ID,
+				EventType:  evt.Type,
+				Payload:    payload,
+			}
+			if err := repo.EnqueueDelivery(d); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+type WebhookWorkerConfig struct {
+	PollInterval time.Duration
+	BatchSize    int
+	Timeout      time.Duration
+	MaxAttempts  int
+	BaseBackoff  time.Duration
+	MaxBackoff   time.Duration
+}
+
+type WebhookWorker struct {
+	repo   *repository.WebhookRepository
+	client *http.Client
+	cfg    WebhookWorkerConfig
+}
+
+func NewWebhookWorker(repo *repository.WebhookRepository, cfg WebhookWorkerConfig) *WebhookWorker {
+	return &WebhookWorker{
+		repo:   repo,
+		client: &http.Client{Timeout: cfg.Timeout},
+		cfg:    cfg,
+	}
+}
+
+func (w *WebhookWorker) Run(ctx context.Context) {
+	log.Println("[webhooks] delivery worker started")
+	defer log.Println("[webhooks] delivery worker stopped")
+
+	ticker := time.NewTicker(w.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+		if w.processBatch(ctx) == w.cfg.BatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *WebhookWorker) processBatch(ctx context.Context) int {
+	// аренда с запасом на таймаут каждого запроса пачки
+	lease := w.cfg.Timeout*time.Duration(w.cfg.BatchSize) + time.Minute
+	items, err := w.repo.ClaimDeliveries(w.cfg.BatchSize, lease)
+	if err != nil {
+		log.Printf("[webhooks] failed to claim deliveries: %v", err)
+		return 0
+	}
+
+	for _, item := range items {
+		if ctx.Err() != nil {
+			return 0
+		}
+		w.deliver(ctx, item)
+	}
+	return len(items)
+}
+
+func (w *WebhookWorker) deliver(ctx context.Context, item repository.ClaimedWebhookDelivery) {
+	d := item.Delivery
+	started := time.Now()
+	status, err := w.send(ctx, item)
+
+	attempt := &domain.WebhookDeliveryAttempt{
+		DeliveryID: d.ID,
+		DurationMs: int(time.Since(started).Milliseconds()),
+	}
+	if status != 0 {
+		attempt.ResponseStatus = &status
+	}
+	if err != nil {
+		msg := err.Error()
+		attempt.Error = &msg
+	}
+	if recErr := w.repo.RecordAttempt(attempt); recErr != nil {
+		log.Printf("[webhooks] failed to record attempt for delivery %d: %v", d.ID, recErr)
+	}
+
+	if err == nil {
+		if err := w.repo.MarkDelivered(d.ID, status); err != nil {
+			log.Printf("[webhooks] failed to mark delivery %d delivered: %v", d.ID, err)
+		}
+		return
+	}
+
+	if d.Attempts >= w.cfg.MaxAttempts {
+		log.Printf("[webhooks] delivery %d failed permanently after %d attempts: %v", d.ID, d.Attempts, err)
+		if err := w.repo.MarkFailed(d.ID, attempt.ResponseStatus, err.Error()); err != nil {
+			log.Printf("[webhooks] failed to mark delivery %d failed: %v", d.ID, err)
+		}
+		return
+	}
+
+	retryAt := time.Now().Add(Backoff(d.Attempts, w.cfg.BaseBackoff, w.cfg.MaxBackoff))
+	if err := w.repo.MarkRetry(d.ID, attempt.ResponseStatus, err.Error(), retryAt); err != nil {
+		log.Printf("[webhooks] failed to reschedule delivery %d: %v", d.ID, err)
+	}
+}
+
+// send отправляет подписанный запрос. Успехом считается любой ответ 2xx.
+func (w *WebhookWorker) send(ctx context.Context, item repository.ClaimedWebhookDelivery) (int, error) {
+	d := item.Delivery
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, item.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "SpaceBook-Webhooks/1.0")
+	req.Header.Set(WebhookEventHeader, string(d.EventType))
+	req.Header.Set(WebhookDeliveryHeader, strconv.FormatInt(d.ID, 10))
+	req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(WebhookSignatureHeader, SignWebhook(item.Secret, timestamp, d.Payload))
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected response status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
@@ -10,7 +10,9 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func AuthMiddleware(jwtManager *auth.JWTManager) gin.HandlerFunc {
+// AuthMiddleware проверяет подпись и срок access-токена, а затем сверяет
+// его с хранилищем отзывов (logout, отзыв сессии, смена пароля).
+func AuthMiddleware(jwtManager *auth.JWTManager, revocations *auth.RevocationStore) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -36,26 +38,35 @@ func AuthMiddleware(jwtManager *auth.JWTManager) gin.HandlerFunc {
 			c.Abort()
 			return
 		}
-		c.Set("userID", claims.UserID)
-		c.Set("email", claims.Email)
-		c.Set("role", claims.Role)
+		if err := revocations.Check(claims); err != nil {
+			if err == auth.ErrRevokedToken {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Token has been revoked"})
+			} else {
+				c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Failed to verify token"})
+			}
+			c.Abort()
+			return
+		}
+		setClaims(c, claims)
 		c.Next()
 	}
 }
 
+// RoleMiddleware пропускает пользователя, у которого есть хотя бы одна
+// из перечисленных ролей.
 func RoleMiddleware(allowedRoles ...domain.UserRole) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		role, exists := c.Get("role")
+		raw, exists := c.Get("roles")
 		if !exists {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "User role not found"})
 			c.Abort()
 			return
 		}
 
-		userRole := domain.UserRole(role.(string))
+		userRoles := raw.([]domain.UserRole)
 		allowed := false
 		for _, allowedRole := range allowedRoles {
-			if userRole == allowedRole {
+			if domain.ContainsRole(userRoles, allowedRole) {
 				allowed = true
 				break
 			}
@@ -74,7 +85,7 @@ func OwnerOnlyMiddleware() gin.HandlerFunc {
 	return RoleMiddleware(domain.RoleOwner)
 }
 
-func OptionalAuthMiddleware(jwtManager *auth.JWTManager) gin.HandlerFunc {
+func OptionalAuthMiddleware(jwtManager *auth.JWTManager, revocations *auth.RevocationStore) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -88,16 +99,26 @@ func OptionalAuthMiddleware(jwtManager *auth.JWTManager) gin.HandlerFunc {
 		}
 		tokenString := parts[1]
 		claims, err := jwtManager.ValidateToken(tokenString)
-		if err == nil {
-			c.Set("userID", claims.UserID)
-			c.Set("email", claims.Email)
-			c.Set("role", claims.Role)
+		if err == nil && revocations.Check(claims) == nil {
+			setClaims(c, claims)
 			c.Set("authenticated", true)
 		}
 
 		c.Next()
 	}
 }
+func setClaims(c *gin.Context, claims *auth.TokenClaims) {
+	c.Set("userID", claims.UserID)
+	c.Set("email", claims.Email)
+	roles := make([]domain.UserRole, len(claims.Roles))
+	for i, r := range claims.Roles {
+		roles[i] = domain.UserRole(r)
+	}
+	c.Set("roles", roles)
+	c.Set("sessionID", claims.SessionID)
+	c.Set("claims", claims)
+}
+
 func CORSMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
@@ -0,0 +1,4 @@
+-- Брони, которые up-миграция вернула из approved в pending из-за
+-- пересечения, остаются в pending: какие из них были одобрены, видно
+-- только в NOTICE при применении up-миграции.
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS bookings_no_approved_overlap;
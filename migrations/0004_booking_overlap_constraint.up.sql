@@ -0,0 +1,42 @@
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+-- До появления ограничения одно помещение успевали одобрить дважды на
+-- одни даты. Такие пересечения разбираются до ALTER TABLE: одобренной
+-- остаётся бронь, созданная раньше, а пересекающиеся с ней возвращаются
+-- владельцу в pending. Список возвращённых броней пишется в NOTICE.
+DO $$
+DECLARE
+    b       RECORD;
+    demoted INTEGER[] := '{}';
+BEGIN
+    FOR b IN
+        SELECT id, space_id, date_from, date_to, created_at
+        FROM bookings
+        WHERE status = 'approved'
+        ORDER BY created_at, id
+    LOOP
+        IF EXISTS (
+            SELECT 1
+            FROM bookings k
+            WHERE k.status = 'approved'
+              AND k.space_id = b.space_id
+              AND (k.created_at, k.id) < (b.created_at, b.id)
+              AND daterange(k.date_from, k.date_to, '[)') && daterange(b.date_from, b.date_to, '[)')
+        ) THEN
+            UPDATE bookings SET status = 'pending', updated_at = CURRENT_TIMESTAMP WHERE id = b.id;
+            demoted := demoted || b.id;
+        END IF;
+    END LOOP;
+
+    IF cardinality(demoted) > 0 THEN
+        RAISE NOTICE 'overlapping approved bookings moved back to pending: %', demoted;
+    END IF;
+END;
+$$;
+
+ALTER TABLE bookings
+    ADD CONSTRAINT bookings_no_approved_overlap
+        EXCLUDE USING gist (
+            space_id WITH =,
+            daterange(date_from, date_to, '[)') WITH &&
+        ) WHERE (status = 'approved');
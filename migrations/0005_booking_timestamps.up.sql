@@ -0,0 +1,19 @@
+ALTER TABLE spaces
+    ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    ADD COLUMN IF NOT EXISTS slot_minutes INTEGER NOT NULL DEFAULT 60
+        CHECK (slot_minutes > 0 AND 1440 % slot_minutes = 0);
+
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS bookings_no_approved_overlap;
+
+-- существующие брони были посуточными: дата превращается в полночь по UTC,
+-- что совпадает с часовым поясом по умолчанию для всех помещений
+ALTER TABLE bookings
+    ALTER COLUMN date_from TYPE TIMESTAMPTZ USING (date_from::timestamp AT TIME ZONE 'UTC'),
+    ALTER COLUMN date_to TYPE TIMESTAMPTZ USING (date_to::timestamp AT TIME ZONE 'UTC');
+
+ALTER TABLE bookings
+    ADD CONSTRAINT bookings_no_approved_overlap
+        EXCLUDE USING gist (
+            space_id WITH =,
+            tstzrange(date_from, date_to, '[)') WITH &&
+        ) WHERE (status = 'approved');
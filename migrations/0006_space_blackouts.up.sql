@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS space_blackouts (
+                                               id SERIAL PRIMARY KEY,
+                                               space_id INTEGER NOT NULL REFERENCES spaces(id) ON DELETE CASCADE,
+                                               starts_at TIMESTAMPTZ NOT NULL,
+                                               ends_at TIMESTAMPTZ NOT NULL,
+                                               reason TEXT NOT NULL DEFAULT '',
+                                               created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                                               CHECK (starts_at < ends_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_space_blackouts_space_id ON space_blackouts(space_id);
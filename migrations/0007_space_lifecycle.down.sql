@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_spaces_deleted_at;
+ALTER TABLE spaces DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,3 @@
+ALTER TABLE spaces ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_spaces_deleted_at ON spaces(deleted_at);
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS outbox (
+                                      id BIGSERIAL PRIMARY KEY,
+                                      event_type VARCHAR(50) NOT NULL,
+                                      payload JSONB NOT NULL,
+                                      status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'delivered', 'dead')),
+                                      attempts INTEGER NOT NULL DEFAULT 0,
+                                      next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                                      locked_until TIMESTAMPTZ,
+                                      last_error TEXT,
+                                      created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                                      delivered_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_pending ON outbox(next_attempt_at) WHERE status = 'pending';
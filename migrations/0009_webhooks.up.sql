@@ -0,0 +1,42 @@
+CREATE TABLE IF NOT EXISTS webhook_endpoints (
+                                                 id SERIAL PRIMARY KEY,
+                                                 user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                                 url TEXT NOT NULL,
+                                                 secret VARCHAR(128) NOT NULL,
+                                                 event_types TEXT[] NOT NULL DEFAULT '{}',
+                                                 is_active BOOLEAN NOT NULL DEFAULT TRUE,
+                                                 created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                                                 updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_endpoints_user_id ON webhook_endpoints(user_id);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+                                                  id BIGSERIAL PRIMARY KEY,
+                                                  endpoint_id INTEGER NOT NULL REFERENCES webhook_endpoints(id) ON DELETE CASCADE,
+                                                  event_id BIGINT NOT NULL,
+                                                  event_type VARCHAR(50) NOT NULL,
+                                                  payload JSONB NOT NULL,
+                                                  status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'delivered', 'failed')),
+                                                  attempts INTEGER NOT NULL DEFAULT 0,
+                                                  next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                                                  locked_until TIMESTAMPTZ,
+                                                  response_status INTEGER,
+                                                  last_error TEXT,
+                                                  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                                                  delivered_at TIMESTAMPTZ,
+                                                  UNIQUE (endpoint_id, event_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_pending ON webhook_deliveries(next_attempt_at) WHERE status = 'pending';
+
+CREATE TABLE IF NOT EXISTS webhook_delivery_attempts (
+                                                         id BIGSERIAL PRIMARY KEY,
+                                                         delivery_id BIGINT NOT NULL REFERENCES webhook_deliveries(id) ON DELETE CASCADE,
+                                                         response_status INTEGER,
+                                                         error TEXT,
+                                                         duration_ms INTEGER NOT NULL,
+                                                         attempted_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_delivery_attempts_delivery_id ON webhook_delivery_attempts(delivery_id);
@@ -0,0 +1,13 @@
+DROP TABLE IF EXISTS refresh_tokens;
+DROP TABLE IF EXISTS sessions;
+
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+                                              id SERIAL PRIMARY KEY,
+                                              user_id INTEGER UNIQUE NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                              token VARCHAR(500) NOT NULL UNIQUE,
+                                              expires_at TIMESTAMP NOT NULL,
+                                              created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_token ON refresh_tokens(token);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user_id ON refresh_tokens(user_id);
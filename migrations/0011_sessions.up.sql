@@ -0,0 +1,29 @@
+-- старые refresh-токены хранились в открытом виде по одному на пользователя;
+-- перенести их в хешированные сессии нельзя, поэтому пользователям придётся войти заново
+DROP TABLE IF EXISTS refresh_tokens;
+
+CREATE TABLE IF NOT EXISTS sessions (
+                                        id BIGSERIAL PRIMARY KEY,
+                                        user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                        user_agent TEXT NOT NULL DEFAULT '',
+                                        ip VARCHAR(64) NOT NULL DEFAULT '',
+                                        created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                                        last_used_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                                        expires_at TIMESTAMPTZ NOT NULL,
+                                        revoked_at TIMESTAMPTZ,
+                                        revoked_reason VARCHAR(50)
+);
+
+CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions(user_id);
+
+-- каждая сессия — семейство refresh-токенов: при обмене старый токен
+-- помечается used_at, повторное предъявление такого токена отзывает сессию
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+                                              id BIGSERIAL PRIMARY KEY,
+                                              session_id BIGINT NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+                                              token_hash CHAR(64) NOT NULL UNIQUE,
+                                              created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                                              used_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_session_id ON refresh_tokens(session_id);
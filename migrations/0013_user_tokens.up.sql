@@ -0,0 +1,13 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMP;
+
+CREATE TABLE IF NOT EXISTS user_tokens (
+                                           id BIGSERIAL PRIMARY KEY,
+                                           user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                           purpose VARCHAR(30) NOT NULL CHECK (purpose IN ('password_reset', 'email_verification')),
+                                           token_hash CHAR(64) NOT NULL UNIQUE,
+                                           expires_at TIMESTAMPTZ NOT NULL,
+                                           used_at TIMESTAMPTZ,
+                                           created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_tokens_user_purpose ON user_tokens(user_id, purpose);
@@ -0,0 +1,11 @@
+DROP TABLE IF EXISTS admin_audit_log;
+
+ALTER TABLE spaces DROP COLUMN IF EXISTS unpublish_reason;
+ALTER TABLE spaces DROP COLUMN IF EXISTS unpublished_at;
+
+ALTER TABLE users DROP COLUMN IF EXISTS suspension_reason;
+ALTER TABLE users DROP COLUMN IF EXISTS suspended_at;
+
+UPDATE users SET role = 'owner' WHERE role = 'admin';
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE users ADD CONSTRAINT users_role_check CHECK (role IN ('owner', 'tenant'));
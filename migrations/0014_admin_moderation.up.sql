@@ -0,0 +1,21 @@
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE users ADD CONSTRAINT users_role_check CHECK (role IN ('owner', 'tenant', 'admin'));
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS suspended_at TIMESTAMPTZ;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS suspension_reason TEXT;
+
+ALTER TABLE spaces ADD COLUMN IF NOT EXISTS unpublished_at TIMESTAMPTZ;
+ALTER TABLE spaces ADD COLUMN IF NOT EXISTS unpublish_reason TEXT;
+
+CREATE TABLE IF NOT EXISTS admin_audit_log (
+                                               id BIGSERIAL PRIMARY KEY,
+                                               admin_id INTEGER NOT NULL REFERENCES users(id),
+                                               action VARCHAR(50) NOT NULL,
+                                               target_type VARCHAR(20) NOT NULL,
+                                               target_id BIGINT NOT NULL,
+                                               reason TEXT NOT NULL,
+                                               created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_admin_audit_log_target ON admin_audit_log(target_type, target_id);
+CREATE INDEX IF NOT EXISTS idx_admin_audit_log_admin_id ON admin_audit_log(admin_id);
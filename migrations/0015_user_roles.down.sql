@@ -0,0 +1,15 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role VARCHAR(20);
+
+-- из набора ролей остаётся самая широкая
+UPDATE users SET role = CASE
+    WHEN 'admin' = ANY(roles) THEN 'admin'
+    WHEN 'owner' = ANY(roles) THEN 'owner'
+    ELSE 'tenant'
+END;
+
+ALTER TABLE users ALTER COLUMN role SET NOT NULL;
+ALTER TABLE users ADD CONSTRAINT users_role_check CHECK (role IN ('owner', 'tenant', 'admin'));
+
+DROP INDEX IF EXISTS idx_users_roles;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_roles_check;
+ALTER TABLE users DROP COLUMN IF EXISTS roles;
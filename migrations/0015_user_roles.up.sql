@@ -0,0 +1,12 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS roles VARCHAR(20)[];
+
+UPDATE users SET roles = ARRAY[role] WHERE roles IS NULL;
+
+ALTER TABLE users ALTER COLUMN roles SET NOT NULL;
+ALTER TABLE users ADD CONSTRAINT users_roles_check
+    CHECK (cardinality(roles) > 0 AND roles <@ ARRAY['owner', 'tenant', 'admin']::VARCHAR(20)[]);
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE users DROP COLUMN IF EXISTS role;
+
+CREATE INDEX IF NOT EXISTS idx_users_roles ON users USING GIN (roles);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_spaces_organization_id;
+ALTER TABLE spaces DROP COLUMN IF EXISTS organization_id;
+
+DROP TABLE IF EXISTS organization_invitations;
+DROP TABLE IF EXISTS organization_members;
+DROP TABLE IF EXISTS organizations;
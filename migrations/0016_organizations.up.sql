@@ -0,0 +1,38 @@
+CREATE TABLE IF NOT EXISTS organizations (
+                                             id SERIAL PRIMARY KEY,
+                                             name VARCHAR(200) NOT NULL,
+                                             created_by INTEGER NOT NULL REFERENCES users(id),
+                                             created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                                             updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS organization_members (
+                                                    organization_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+                                                    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                                    role VARCHAR(20) NOT NULL CHECK (role IN ('admin', 'manager', 'viewer')),
+                                                    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                                                    PRIMARY KEY (organization_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_organization_members_user_id ON organization_members(user_id);
+
+CREATE TABLE IF NOT EXISTS organization_invitations (
+                                                        id BIGSERIAL PRIMARY KEY,
+                                                        organization_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+                                                        email VARCHAR(255) NOT NULL,
+                                                        role VARCHAR(20) NOT NULL CHECK (role IN ('admin', 'manager', 'viewer')),
+                                                        token_hash CHAR(64) NOT NULL UNIQUE,
+                                                        invited_by INTEGER NOT NULL REFERENCES users(id),
+                                                        expires_at TIMESTAMPTZ NOT NULL,
+                                                        accepted_at TIMESTAMPTZ,
+                                                        revoked_at TIMESTAMPTZ,
+                                                        created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_organization_invitations_org ON organization_invitations(organization_id);
+
+-- помещение по-прежнему принадлежит owner_id, организация лишь даёт
+-- своим участникам права на управление им
+ALTER TABLE spaces ADD COLUMN IF NOT EXISTS organization_id INTEGER REFERENCES organizations(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_spaces_organization_id ON spaces(organization_id);
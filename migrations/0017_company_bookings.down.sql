@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_bookings_organization_id;
+
+UPDATE bookings SET status = 'cancelled' WHERE status = 'pending_company';
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS bookings_status_check;
+ALTER TABLE bookings ADD CONSTRAINT bookings_status_check
+    CHECK (status IN ('pending', 'approved', 'rejected', 'cancelled'));
+
+ALTER TABLE bookings DROP COLUMN IF EXISTS organization_id;
+ALTER TABLE organizations DROP COLUMN IF EXISTS booking_approval_required;
@@ -0,0 +1,10 @@
+ALTER TABLE organizations ADD COLUMN IF NOT EXISTS booking_approval_required BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- бронь от имени компании; заявка сначала проходит согласование внутри неё
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS organization_id INTEGER REFERENCES organizations(id) ON DELETE SET NULL;
+
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS bookings_status_check;
+ALTER TABLE bookings ADD CONSTRAINT bookings_status_check
+    CHECK (status IN ('pending_company', 'pending', 'approved', 'rejected', 'cancelled'));
+
+CREATE INDEX IF NOT EXISTS idx_bookings_organization_id ON bookings(organization_id);
@@ -0,0 +1,17 @@
+DROP TABLE IF EXISTS booking_status_history;
+
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS bookings_no_approved_overlap;
+
+UPDATE bookings SET status = 'cancelled' WHERE status = 'expired';
+UPDATE bookings SET status = 'approved' WHERE status IN ('checked_in', 'completed');
+UPDATE bookings SET status = 'cancelled' WHERE status = 'no_show';
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS bookings_status_check;
+ALTER TABLE bookings ADD CONSTRAINT bookings_status_check
+    CHECK (status IN ('pending_company', 'pending', 'approved', 'rejected', 'cancelled'));
+
+ALTER TABLE bookings
+    ADD CONSTRAINT bookings_no_approved_overlap
+        EXCLUDE USING gist (
+            space_id WITH =,
+            tstzrange(date_from, date_to, '[)') WITH &&
+        ) WHERE (status = 'approved');
@@ -0,0 +1,33 @@
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS bookings_status_check;
+ALTER TABLE bookings ADD CONSTRAINT bookings_status_check
+    CHECK (status IN ('pending_company', 'pending', 'approved', 'rejected', 'cancelled',
+                      'expired', 'checked_in', 'completed', 'no_show'));
+
+-- заезд и завершение не освобождают помещение: пересечение запрещено
+-- для всех статусов, в которых бронь его занимает
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS bookings_no_approved_overlap;
+ALTER TABLE bookings
+    ADD CONSTRAINT bookings_no_approved_overlap
+        EXCLUDE USING gist (
+            space_id WITH =,
+            tstzrange(date_from, date_to, '[)') WITH &&
+        ) WHERE (status IN ('approved', 'checked_in', 'completed'));
+
+-- каждая смена статуса брони; actor_id пуст у системных переходов
+CREATE TABLE IF NOT EXISTS booking_status_history (
+    id          BIGSERIAL PRIMARY KEY,
+    booking_id  INTEGER NOT NULL REFERENCES bookings(id) ON DELETE CASCADE,
+    from_status VARCHAR(20),
+    to_status   VARCHAR(20) NOT NULL,
+    actor_id    INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    reason      TEXT,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_booking_status_history_booking_id
+    ON booking_status_history(booking_id, created_at);
+
+-- у уже существующих броней известно только текущее состояние
+INSERT INTO booking_status_history (booking_id, from_status, to_status, created_at)
+SELECT id, NULL, status, updated_at
+FROM bookings;
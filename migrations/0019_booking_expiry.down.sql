@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_bookings_awaiting_response;
+
+ALTER TABLE bookings DROP COLUMN IF EXISTS respond_by;
+ALTER TABLE spaces DROP COLUMN IF EXISTS response_hours;
@@ -0,0 +1,14 @@
+-- сколько часов владелец может думать над заявкой
+ALTER TABLE spaces ADD COLUMN IF NOT EXISTS response_hours INTEGER NOT NULL DEFAULT 48
+    CHECK (response_hours > 0);
+
+-- срок ответа владельца: выставляется, когда заявка попадает к нему
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS respond_by TIMESTAMPTZ;
+
+UPDATE bookings b
+SET respond_by = LEAST(b.created_at + INTERVAL '48 hours', b.date_from)
+WHERE b.status = 'pending';
+
+CREATE INDEX IF NOT EXISTS idx_bookings_awaiting_response
+    ON bookings(respond_by, date_from)
+    WHERE status IN ('pending_company', 'pending');
@@ -0,0 +1,5 @@
+ALTER TABLE spaces
+    DROP COLUMN IF EXISTS instant_verified_only,
+    DROP COLUMN IF EXISTS instant_min_lead_minutes,
+    DROP COLUMN IF EXISTS instant_max_duration_minutes,
+    DROP COLUMN IF EXISTS instant_booking;
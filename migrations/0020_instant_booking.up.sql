@@ -0,0 +1,8 @@
+-- мгновенное бронирование: заявка одобряется сразу, если выполнены правила
+ALTER TABLE spaces
+    ADD COLUMN IF NOT EXISTS instant_booking BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS instant_max_duration_minutes INTEGER
+        CHECK (instant_max_duration_minutes > 0),
+    ADD COLUMN IF NOT EXISTS instant_min_lead_minutes INTEGER
+        CHECK (instant_min_lead_minutes >= 0),
+    ADD COLUMN IF NOT EXISTS instant_verified_only BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS booking_change_requests (
+    id           BIGSERIAL PRIMARY KEY,
+    booking_id   INTEGER NOT NULL REFERENCES bookings(id) ON DELETE CASCADE,
+    date_from    TIMESTAMPTZ NOT NULL,
+    date_to      TIMESTAMPTZ NOT NULL,
+    status       VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'approved', 'rejected', 'superseded')),
+    reason       TEXT,
+    requested_by INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    decided_by   INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    decided_at   TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (date_from < date_to)
+);
+
+-- у брони не больше одного неотвеченного запроса на перенос
+CREATE UNIQUE INDEX IF NOT EXISTS idx_booking_change_requests_one_pending
+    ON booking_change_requests(booking_id)
+    WHERE status = 'pending';
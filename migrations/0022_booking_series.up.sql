@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS booking_series (
+    id              SERIAL PRIMARY KEY,
+    space_id        INTEGER NOT NULL REFERENCES spaces(id) ON DELETE CASCADE,
+    tenant_id       INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    organization_id INTEGER REFERENCES organizations(id) ON DELETE SET NULL,
+    rrule           TEXT NOT NULL,
+    -- даты-исключения (EXDATE) в часовом поясе помещения
+    exceptions      DATE[] NOT NULL DEFAULT '{}',
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS series_id INTEGER REFERENCES booking_series(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_bookings_series_id ON bookings(series_id, date_from);
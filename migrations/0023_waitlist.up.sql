@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS waitlist_entries (
+    id          SERIAL PRIMARY KEY,
+    space_id    INTEGER NOT NULL REFERENCES spaces(id) ON DELETE CASCADE,
+    tenant_id   INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    date_from   TIMESTAMPTZ NOT NULL,
+    date_to     TIMESTAMPTZ NOT NULL,
+    -- первому в очереди сразу создаётся заявка, когда даты освобождаются
+    auto_book   BOOLEAN NOT NULL DEFAULT FALSE,
+    status      VARCHAR(20) NOT NULL DEFAULT 'waiting'
+        CHECK (status IN ('waiting', 'notified', 'booked', 'cancelled')),
+    booking_id  INTEGER REFERENCES bookings(id) ON DELETE SET NULL,
+    notified_at TIMESTAMPTZ,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (date_from < date_to)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_waitlist_entries_unique_waiting
+    ON waitlist_entries(space_id, tenant_id, date_from, date_to)
+    WHERE status = 'waiting';
+
+CREATE INDEX IF NOT EXISTS idx_waitlist_entries_space_waiting
+    ON waitlist_entries(space_id, created_at)
+    WHERE status = 'waiting';
@@ -0,0 +1,6 @@
+ALTER TABLE bookings
+    DROP CONSTRAINT IF EXISTS bookings_price_complete,
+    DROP COLUMN IF EXISTS price_currency,
+    DROP COLUMN IF EXISTS price_amount;
+
+DROP TABLE IF EXISTS space_pricing;
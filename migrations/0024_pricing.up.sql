@@ -0,0 +1,21 @@
+-- тарифы помещения; суммы в минимальных единицах валюты
+CREATE TABLE IF NOT EXISTS space_pricing (
+    space_id            INTEGER PRIMARY KEY REFERENCES spaces(id) ON DELETE CASCADE,
+    currency            CHAR(3) NOT NULL,
+    hour_rate           BIGINT CHECK (hour_rate > 0),
+    day_rate            BIGINT CHECK (day_rate > 0),
+    week_rate           BIGINT CHECK (week_rate > 0),
+    month_rate          BIGINT CHECK (month_rate > 0),
+    weekend_percent     INTEGER CHECK (weekend_percent > 0),
+    seasons             JSONB NOT NULL DEFAULT '[]',
+    long_stay_discounts JSONB NOT NULL DEFAULT '[]',
+    updated_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (COALESCE(hour_rate, day_rate, week_rate, month_rate) IS NOT NULL)
+);
+
+-- стоимость фиксируется при создании брони и не меняется при последующих правках тарифа
+ALTER TABLE bookings
+    ADD COLUMN IF NOT EXISTS price_amount BIGINT,
+    ADD COLUMN IF NOT EXISTS price_currency CHAR(3),
+    ADD CONSTRAINT bookings_price_complete
+        CHECK ((price_amount IS NULL) = (price_currency IS NULL));
@@ -0,0 +1,17 @@
+DROP TABLE IF EXISTS payment_webhook_events;
+DROP TABLE IF EXISTS payments;
+
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS bookings_no_approved_overlap;
+
+UPDATE bookings SET status = 'approved' WHERE status = 'confirmed';
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS bookings_status_check;
+ALTER TABLE bookings ADD CONSTRAINT bookings_status_check
+    CHECK (status IN ('pending_company', 'pending', 'approved', 'rejected', 'cancelled',
+                      'expired', 'checked_in', 'completed', 'no_show'));
+
+ALTER TABLE bookings
+    ADD CONSTRAINT bookings_no_approved_overlap
+        EXCLUDE USING gist (
+            space_id WITH =,
+            tstzrange(date_from, date_to, '[)') WITH &&
+        ) WHERE (status IN ('approved', 'checked_in', 'completed'));
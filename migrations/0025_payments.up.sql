@@ -0,0 +1,45 @@
+-- оплаченная бронь подтверждена и, как одобренная, занимает помещение
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS bookings_status_check;
+ALTER TABLE bookings ADD CONSTRAINT bookings_status_check
+    CHECK (status IN ('pending_company', 'pending', 'approved', 'confirmed', 'rejected', 'cancelled',
+                      'expired', 'checked_in', 'completed', 'no_show'));
+
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS bookings_no_approved_overlap;
+ALTER TABLE bookings
+    ADD CONSTRAINT bookings_no_approved_overlap
+        EXCLUDE USING gist (
+            space_id WITH =,
+            tstzrange(date_from, date_to, '[)') WITH &&
+        ) WHERE (status IN ('approved', 'confirmed', 'checked_in', 'completed'));
+
+CREATE TABLE IF NOT EXISTS payments (
+    id              SERIAL PRIMARY KEY,
+    booking_id      INTEGER NOT NULL REFERENCES bookings(id) ON DELETE CASCADE,
+    provider        VARCHAR(50) NOT NULL,
+    provider_ref    VARCHAR(255),
+    amount          BIGINT NOT NULL CHECK (amount > 0),
+    currency        CHAR(3) NOT NULL,
+    status          VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'requires_action', 'authorized', 'captured', 'failed', 'refunded')),
+    refunded_amount BIGINT NOT NULL DEFAULT 0 CHECK (refunded_amount >= 0 AND refunded_amount <= amount),
+    action_url      TEXT,
+    failure_reason  TEXT,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_payments_provider_ref
+    ON payments(provider, provider_ref);
+
+-- у брони не больше одного живого платежа: повторная оплата невозможна
+CREATE UNIQUE INDEX IF NOT EXISTS idx_payments_booking_active
+    ON payments(booking_id)
+    WHERE status IN ('pending', 'requires_action', 'authorized', 'captured');
+
+-- обработанные вебхуки шлюза; повторная доставка пропускается
+CREATE TABLE IF NOT EXISTS payment_webhook_events (
+    provider    VARCHAR(50) NOT NULL,
+    event_id    VARCHAR(255) NOT NULL,
+    received_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (provider, event_id)
+);
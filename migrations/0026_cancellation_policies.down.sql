@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS booking_cancellations;
+
+ALTER TABLE spaces
+    DROP COLUMN IF EXISTS cancellation_tiers,
+    DROP COLUMN IF EXISTS cancellation_policy;
@@ -0,0 +1,23 @@
+-- политика отмены помещения; ступени хранятся только у custom
+ALTER TABLE spaces
+    ADD COLUMN IF NOT EXISTS cancellation_policy VARCHAR(20) NOT NULL DEFAULT 'flexible'
+        CHECK (cancellation_policy IN ('flexible', 'moderate', 'strict', 'custom')),
+    ADD COLUMN IF NOT EXISTS cancellation_tiers JSONB NOT NULL DEFAULT '[]';
+
+-- расчёт возврата, сделанный в момент отмены; отмены владельцем и
+-- администратором отличаются по cancelled_by и всегда возвращают всё
+CREATE TABLE IF NOT EXISTS booking_cancellations (
+    booking_id     INTEGER PRIMARY KEY REFERENCES bookings(id) ON DELETE CASCADE,
+    cancelled_by   VARCHAR(20) NOT NULL CHECK (cancelled_by IN ('tenant', 'owner', 'admin')),
+    actor_id       INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    policy         VARCHAR(20) NOT NULL,
+    refund_percent INTEGER NOT NULL CHECK (refund_percent BETWEEN 0 AND 100),
+    refund_amount  BIGINT,
+    fee_amount     BIGINT,
+    currency       CHAR(3),
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_booking_cancellations_by_owner
+    ON booking_cancellations(created_at)
+    WHERE cancelled_by = 'owner';
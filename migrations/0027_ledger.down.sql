@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS payouts;
+DROP TABLE IF EXISTS payout_batches;
+
+DROP TABLE IF EXISTS ledger_entries;
+DROP TABLE IF EXISTS ledger_transactions;
+DROP TABLE IF EXISTS ledger_accounts;
+
+DROP FUNCTION IF EXISTS ledger_check_balanced();
+DROP FUNCTION IF EXISTS ledger_immutable();
@@ -0,0 +1,106 @@
+-- счета главной книги; у platform и fees нет пользователя
+CREATE TABLE IF NOT EXISTS ledger_accounts (
+    id         SERIAL PRIMARY KEY,
+    kind       VARCHAR(20) NOT NULL CHECK (kind IN ('platform', 'owner', 'tenant', 'fees')),
+    user_id    INTEGER REFERENCES users(id) ON DELETE RESTRICT,
+    currency   CHAR(3) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK ((kind IN ('owner', 'tenant')) = (user_id IS NOT NULL))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ledger_accounts_unique
+    ON ledger_accounts(kind, COALESCE(user_id, 0), currency);
+
+-- reference делает запись транзакции идемпотентной
+CREATE TABLE IF NOT EXISTS ledger_transactions (
+    id          BIGSERIAL PRIMARY KEY,
+    kind        VARCHAR(20) NOT NULL CHECK (kind IN ('booking_charge', 'refund', 'commission', 'payout')),
+    reference   VARCHAR(100) NOT NULL UNIQUE,
+    booking_id  INTEGER REFERENCES bookings(id) ON DELETE RESTRICT,
+    payment_id  INTEGER REFERENCES payments(id) ON DELETE RESTRICT,
+    description TEXT NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- amount > 0 — дебет, < 0 — кредит
+CREATE TABLE IF NOT EXISTS ledger_entries (
+    id             BIGSERIAL PRIMARY KEY,
+    transaction_id BIGINT NOT NULL REFERENCES ledger_transactions(id) ON DELETE RESTRICT,
+    account_id     INTEGER NOT NULL REFERENCES ledger_accounts(id) ON DELETE RESTRICT,
+    amount         BIGINT NOT NULL CHECK (amount <> 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_account
+    ON ledger_entries(account_id, transaction_id);
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_transaction
+    ON ledger_entries(transaction_id);
+
+-- записанную транзакцию нельзя ни изменить, ни удалить: ошибки
+-- исправляются только новой, обратной транзакцией
+CREATE OR REPLACE FUNCTION ledger_immutable() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'ledger % rows are immutable', TG_TABLE_NAME;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS ledger_transactions_immutable ON ledger_transactions;
+CREATE TRIGGER ledger_transactions_immutable
+    BEFORE UPDATE OR DELETE ON ledger_transactions
+    FOR EACH ROW EXECUTE FUNCTION ledger_immutable();
+
+DROP TRIGGER IF EXISTS ledger_entries_immutable ON ledger_entries;
+CREATE TRIGGER ledger_entries_immutable
+    BEFORE UPDATE OR DELETE ON ledger_entries
+    FOR EACH ROW EXECUTE FUNCTION ledger_immutable();
+
+-- проводки транзакции в каждой валюте в сумме дают ноль; проверка
+-- отложена до коммита, чтобы проводки можно было вставлять по одной
+CREATE OR REPLACE FUNCTION ledger_check_balanced() RETURNS trigger AS $$
+BEGIN
+    IF EXISTS (
+        SELECT 1
+        FROM ledger_entries e
+        JOIN ledger_accounts a ON a.id = e.account_id
+        WHERE e.transaction_id = NEW.transaction_id
+        GROUP BY a.currency
+        HAVING SUM(e.amount) <> 0
+    ) THEN
+        RAISE EXCEPTION 'ledger transaction % is not balanced', NEW.transaction_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS ledger_entries_balanced ON ledger_entries;
+CREATE CONSTRAINT TRIGGER ledger_entries_balanced
+    AFTER INSERT ON ledger_entries
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW EXECUTE FUNCTION ledger_check_balanced();
+
+-- пакет выплат: все владельцы, кому платформа должна по транзакцию
+-- cutoff_transaction_id включительно
+CREATE TABLE IF NOT EXISTS payout_batches (
+    id                    SERIAL PRIMARY KEY,
+    cutoff_transaction_id BIGINT NOT NULL,
+    created_at            TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- выплата покрывает проводки счёта владельца с транзакциями
+-- из (from_transaction_id, to_transaction_id]
+CREATE TABLE IF NOT EXISTS payouts (
+    id                    SERIAL PRIMARY KEY,
+    batch_id              INTEGER NOT NULL REFERENCES payout_batches(id) ON DELETE RESTRICT,
+    owner_id              INTEGER NOT NULL REFERENCES users(id) ON DELETE RESTRICT,
+    currency              CHAR(3) NOT NULL,
+    amount                BIGINT NOT NULL CHECK (amount > 0),
+    opening_balance       BIGINT NOT NULL,
+    closing_balance       BIGINT NOT NULL,
+    from_transaction_id   BIGINT NOT NULL,
+    to_transaction_id     BIGINT NOT NULL,
+    ledger_transaction_id BIGINT NOT NULL UNIQUE REFERENCES ledger_transactions(id) ON DELETE RESTRICT,
+    created_at            TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (batch_id, owner_id, currency)
+);
+
+CREATE INDEX IF NOT EXISTS idx_payouts_owner
+    ON payouts(owner_id, currency, id DESC);
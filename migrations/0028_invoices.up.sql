@@ -0,0 +1,40 @@
+-- последний выданный номер в серии; строка блокируется до конца
+-- транзакции, поэтому номера идут без пропусков и повторов
+CREATE TABLE IF NOT EXISTS invoice_counters (
+    series      VARCHAR(30) NOT NULL,
+    kind        VARCHAR(20) NOT NULL,
+    last_number INTEGER NOT NULL,
+    PRIMARY KEY (series, kind)
+);
+
+CREATE TABLE IF NOT EXISTS invoices (
+    id          SERIAL PRIMARY KEY,
+    kind        VARCHAR(20) NOT NULL CHECK (kind IN ('invoice', 'credit_note')),
+    series      VARCHAR(30) NOT NULL,
+    sequence    INTEGER NOT NULL,
+    number      VARCHAR(50) NOT NULL,
+    booking_id  INTEGER NOT NULL REFERENCES bookings(id) ON DELETE RESTRICT,
+    payment_id  INTEGER REFERENCES payments(id) ON DELETE RESTRICT,
+    corrects_id INTEGER REFERENCES invoices(id) ON DELETE RESTRICT,
+    -- делает выставление credit note по возврату идемпотентным
+    reference   VARCHAR(100) UNIQUE,
+    issuer      JSONB NOT NULL,
+    customer    JSONB NOT NULL,
+    lines       JSONB NOT NULL,
+    taxes       JSONB NOT NULL,
+    subtotal    BIGINT NOT NULL,
+    tax_total   BIGINT NOT NULL,
+    total       BIGINT NOT NULL CHECK (total >= 0),
+    currency    CHAR(3) NOT NULL,
+    issued_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (series, kind, sequence),
+    CHECK ((kind = 'credit_note') = (corrects_id IS NOT NULL))
+);
+
+-- у брони один счёт; credit notes выставляются к нему при каждом возврате
+CREATE UNIQUE INDEX IF NOT EXISTS idx_invoices_booking_invoice
+    ON invoices(booking_id)
+    WHERE kind = 'invoice';
+
+CREATE INDEX IF NOT EXISTS idx_invoices_booking
+    ON invoices(booking_id, id);
@@ -0,0 +1,12 @@
+-- возвраты, сделанные у шлюза по ключу идемпотентности; повтор с тем же
+-- ключом не увеличивает refunded_amount платежа второй раз
+CREATE TABLE IF NOT EXISTS payment_refunds (
+    id              SERIAL PRIMARY KEY,
+    payment_id      INTEGER NOT NULL REFERENCES payments(id) ON DELETE RESTRICT,
+    idempotency_key VARCHAR(100) NOT NULL UNIQUE,
+    amount          BIGINT NOT NULL CHECK (amount > 0),
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_payment_refunds_payment
+    ON payment_refunds(payment_id);